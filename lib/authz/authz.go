@@ -0,0 +1,110 @@
+package authz
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+)
+
+const RoleClaim = "role"
+
+// Policy decides whether the caller identified by claims may perform the
+// request. An error means the decision could not be made.
+type Policy func(r *http.Request, claims jwtauth.Claims) (bool, error)
+
+func Authenticated() Policy {
+	return func(r *http.Request, claims jwtauth.Claims) (bool, error) {
+		return true, nil
+	}
+}
+
+func HasRole(roles ...string) Policy {
+	return func(r *http.Request, claims jwtauth.Claims) (bool, error) {
+		role := claims.String(RoleClaim)
+		for _, allowed := range roles {
+			if role == allowed {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+}
+
+func AllOf(policies ...Policy) Policy {
+	return func(r *http.Request, claims jwtauth.Claims) (bool, error) {
+		for _, policy := range policies {
+			if ok, err := policy(r, claims); err != nil || !ok {
+				return false, err
+			}
+		}
+
+		return true, nil
+	}
+}
+
+func AnyOf(policies ...Policy) Policy {
+	return func(r *http.Request, claims jwtauth.Claims) (bool, error) {
+		for _, policy := range policies {
+			if ok, err := policy(r, claims); err != nil || ok {
+				return ok, err
+			}
+		}
+
+		return false, nil
+	}
+}
+
+type Option func(*options)
+
+type options struct {
+	logger *slog.Logger
+}
+
+// WithLogger sets the logger for failing policies, default slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(options *options) {
+		options.logger = logger
+	}
+}
+
+// Require only passes requests to the next handler if the policy allows them.
+// The claims are taken from the request context, so the jwtauth middleware
+// has to run first. Errors of the policy are written with problem.WriteError,
+// so a policy may answer e.g. 404 Not Found by returning problem.ErrNotFound.
+func Require(policy Policy, opts ...Option) func(http.Handler) http.Handler {
+	options := options{logger: slog.Default()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := jwtauth.ClaimsFromContext(r.Context())
+			if !ok {
+				w.Header().Add("WWW-Authenticate", "Bearer")
+				problem.WriteStatus(w, r, http.StatusUnauthorized, "a bearer token is required")
+				return
+			}
+
+			allowed, err := policy(r, claims)
+			if err != nil {
+				p := problem.FromError(err)
+				if p.Status == http.StatusInternalServerError {
+					options.logger.ErrorContext(r.Context(), "could not evaluate authorization policy", slog.String("error", err.Error()))
+				}
+				problem.Write(w, r, p)
+				return
+			}
+
+			if !allowed {
+				problem.WriteStatus(w, r, http.StatusForbidden, "you are not allowed to do this")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
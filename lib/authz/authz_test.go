@@ -0,0 +1,170 @@
+package authz
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequire(t *testing.T) {
+	var called bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	withClaims := func(r *http.Request, claims jwtauth.Claims) *http.Request {
+		return r.WithContext(jwtauth.NewContext(r.Context(), claims))
+	}
+
+	t.Run("should return 401 UNAUTHORIZED if request is not authenticated", func(t *testing.T) {
+		// given
+		called = false
+		handler := Require(Authenticated())(next)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", "/api/v1/products/1", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+		assert.False(t, called)
+	})
+
+	t.Run("should return 403 FORBIDDEN if policy denies request", func(t *testing.T) {
+		// given
+		called = false
+		handler := Require(HasRole("admin"))(next)
+
+		w := httptest.NewRecorder()
+		r := withClaims(httptest.NewRequest("DELETE", "/api/v1/products/1", nil), jwtauth.Claims{"role": "customer"})
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.False(t, called)
+	})
+
+	t.Run("should return 500 INTERNAL SERVER ERROR if policy fails", func(t *testing.T) {
+		// given
+		called = false
+		handler := Require(func(r *http.Request, claims jwtauth.Claims) (bool, error) {
+			return false, errors.New("database error")
+		})(next)
+
+		w := httptest.NewRecorder()
+		r := withClaims(httptest.NewRequest("DELETE", "/api/v1/products/1", nil), jwtauth.Claims{})
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.False(t, called)
+	})
+
+	t.Run("should log policy failures with the request context", func(t *testing.T) {
+		// given
+		var logs bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&logs, nil))
+		handler := Require(func(r *http.Request, claims jwtauth.Claims) (bool, error) {
+			return false, errors.New("database error")
+		}, WithLogger(logger))(next)
+
+		w := httptest.NewRecorder()
+		r := withClaims(httptest.NewRequest("DELETE", "/api/v1/products/1", nil), jwtauth.Claims{})
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, logs.String(), `"msg":"could not evaluate authorization policy"`)
+		assert.Contains(t, logs.String(), `"error":"database error"`)
+	})
+
+	t.Run("should write problem of policy errors", func(t *testing.T) {
+		// given
+		called = false
+		handler := Require(func(r *http.Request, claims jwtauth.Claims) (bool, error) {
+			return false, fmt.Errorf("product %w", problem.ErrNotFound)
+		})(next)
+
+		w := httptest.NewRecorder()
+		r := withClaims(httptest.NewRequest("DELETE", "/api/v1/products/1", nil), jwtauth.Claims{})
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		assert.False(t, called)
+	})
+
+	t.Run("should call next handler if policy allows request", func(t *testing.T) {
+		// given
+		called = false
+		handler := Require(HasRole("retailer", "admin"))(next)
+
+		w := httptest.NewRecorder()
+		r := withClaims(httptest.NewRequest("DELETE", "/api/v1/products/1", nil), jwtauth.Claims{"role": "admin"})
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, called)
+	})
+}
+
+func TestPolicies(t *testing.T) {
+	allow := func(r *http.Request, claims jwtauth.Claims) (bool, error) { return true, nil }
+	deny := func(r *http.Request, claims jwtauth.Claims) (bool, error) { return false, nil }
+	fail := func(r *http.Request, claims jwtauth.Claims) (bool, error) { return false, errors.New("failed") }
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		allowed bool
+		err     bool
+	}{
+		{"HasRole matches role", HasRole("retailer", "admin"), true, false},
+		{"HasRole does not match role", HasRole("admin"), false, false},
+		{"AllOf with all allowing", AllOf(allow, allow), true, false},
+		{"AllOf with one denying", AllOf(allow, deny), false, false},
+		{"AllOf with one failing", AllOf(allow, fail), false, true},
+		{"AnyOf with one allowing", AnyOf(deny, allow), true, false},
+		{"AnyOf with none allowing", AnyOf(deny, deny), false, false},
+		{"AnyOf stops at first allowing", AnyOf(allow, fail), true, false},
+		{"AnyOf with one failing", AnyOf(fail, allow), false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			r := httptest.NewRequest("GET", "/", nil)
+			claims := jwtauth.Claims{"role": "retailer"}
+
+			// when
+			allowed, err := test.policy(r, claims)
+
+			// then
+			assert.Equal(t, test.allowed, allowed)
+			assert.Equal(t, test.err, err != nil)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package cors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	DefaultAllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	DefaultAllowedHeaders = []string{"Authorization", "Content-Type", "X-Request-ID"}
+)
+
+// Config configures the CORS middleware. Origins are compared exactly, "*"
+// allows every origin and a single "*" inside an origin matches a part of the
+// host, like https://*.example.com. Headers "*" allows every requested
+// header.
+type Config struct {
+	AllowedOrigins   []string      `yaml:"allowedOrigins"`
+	AllowedMethods   []string      `yaml:"allowedMethods"`
+	AllowedHeaders   []string      `yaml:"allowedHeaders"`
+	ExposedHeaders   []string      `yaml:"exposedHeaders"`
+	AllowCredentials bool          `yaml:"allowCredentials"`
+	MaxAge           time.Duration `yaml:"maxAge"`
+}
+
+func (config Config) Enabled() bool {
+	return len(config.AllowedOrigins) > 0
+}
+
+type originPattern struct {
+	prefix string
+	suffix string
+}
+
+func (pattern originPattern) matches(origin string) bool {
+	if len(origin) <= len(pattern.prefix)+len(pattern.suffix) {
+		return false
+	}
+
+	if !strings.HasPrefix(origin, pattern.prefix) || !strings.HasSuffix(origin, pattern.suffix) {
+		return false
+	}
+
+	wildcard := origin[len(pattern.prefix) : len(origin)-len(pattern.suffix)]
+	return !strings.ContainsAny(wildcard, "/:")
+}
+
+type Middleware struct {
+	allOrigins       bool
+	origins          map[string]bool
+	patterns         []originPattern
+	methods          string
+	allowedMethods   map[string]bool
+	allHeaders       bool
+	allowedHeaders   map[string]bool
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+func New(config Config) (*Middleware, error) {
+	middleware := &Middleware{
+		origins:          make(map[string]bool),
+		allowedMethods:   make(map[string]bool),
+		allowedHeaders:   make(map[string]bool),
+		exposedHeaders:   strings.Join(config.ExposedHeaders, ", "),
+		allowCredentials: config.AllowCredentials,
+	}
+
+	if len(config.AllowedOrigins) == 0 {
+		return nil, errors.New("cors needs at least one allowed origin")
+	}
+
+	for _, origin := range config.AllowedOrigins {
+		switch strings.Count(origin, "*") {
+		case 0:
+			middleware.origins[strings.ToLower(origin)] = true
+		case 1:
+			if origin == "*" {
+				middleware.allOrigins = true
+				continue
+			}
+
+			prefix, suffix, _ := strings.Cut(strings.ToLower(origin), "*")
+			middleware.patterns = append(middleware.patterns, originPattern{prefix, suffix})
+		default:
+			return nil, fmt.Errorf("invalid cors origin %q", origin)
+		}
+	}
+
+	if middleware.allOrigins && config.AllowCredentials {
+		return nil, errors.New("cors can't allow credentials for all origins")
+	}
+
+	methods := config.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultAllowedMethods
+	}
+
+	for _, method := range methods {
+		middleware.allowedMethods[strings.ToUpper(method)] = true
+	}
+	middleware.methods = strings.ToUpper(strings.Join(methods, ", "))
+
+	headers := config.AllowedHeaders
+	if len(headers) == 0 {
+		headers = DefaultAllowedHeaders
+	}
+
+	for _, header := range headers {
+		if header == "*" {
+			middleware.allHeaders = true
+			continue
+		}
+		middleware.allowedHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+
+	if config.MaxAge > 0 {
+		middleware.maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+
+	return middleware, nil
+}
+
+// Handler adds the CORS headers to responses for allowed origins and answers
+// preflight requests itself, so they also succeed for paths which have no
+// OPTIONS route.
+func (middleware *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+
+		if r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != "" {
+			middleware.preflight(w, r, origin)
+			return
+		}
+
+		if !middleware.allOrigins {
+			w.Header().Add("Vary", "Origin")
+		}
+
+		if origin != "" && middleware.allowOrigin(w, origin) {
+			if middleware.exposedHeaders != "" {
+				w.Header().Set("Access-Control-Expose-Headers", middleware.exposedHeaders)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// preflight leaves out all CORS headers if the request is not allowed, which
+// makes the browser reject it.
+func (middleware *Middleware) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	requestedHeaders, headersAllowed := middleware.requestedHeaders(r)
+
+	if !middleware.allowedMethods[method] || !headersAllowed || !middleware.allowOrigin(w, origin) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	header.Set("Access-Control-Allow-Methods", middleware.methods)
+	if len(requestedHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+	}
+	if middleware.maxAge != "" {
+		header.Set("Access-Control-Max-Age", middleware.maxAge)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (middleware *Middleware) allowOrigin(w http.ResponseWriter, origin string) bool {
+	if middleware.allOrigins {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return true
+	}
+
+	if !middleware.originAllowed(strings.ToLower(origin)) {
+		return false
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if middleware.allowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	return true
+}
+
+func (middleware *Middleware) originAllowed(origin string) bool {
+	if middleware.origins[origin] {
+		return true
+	}
+
+	for _, pattern := range middleware.patterns {
+		if pattern.matches(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (middleware *Middleware) requestedHeaders(r *http.Request) ([]string, bool) {
+	var headers []string
+	for _, value := range r.Header.Values("Access-Control-Request-Headers") {
+		for _, header := range strings.Split(value, ",") {
+			if header = strings.TrimSpace(header); header == "" {
+				continue
+			}
+
+			if !middleware.allHeaders && !middleware.allowedHeaders[http.CanonicalHeaderKey(header)] {
+				return nil, false
+			}
+			headers = append(headers, header)
+		}
+	}
+
+	return headers, true
+}
@@ -0,0 +1,201 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("should return error for invalid configs", func(t *testing.T) {
+		tests := []Config{
+			{},
+			{AllowedOrigins: []string{"https://*.*.example.com"}},
+			{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+		}
+
+		for _, test := range tests {
+			// when
+			_, err := New(test)
+
+			// then
+			assert.Error(t, err, test)
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	newHandler := func(config Config) http.Handler {
+		middleware, err := New(config)
+		if err != nil {
+			t.Fatalf("could not create cors middleware: %s", err.Error())
+		}
+		return middleware.Handler(next)
+	}
+
+	preflight := func(origin, method, headers string) *http.Request {
+		r := httptest.NewRequest("OPTIONS", "/api/v1/products", nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			r.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		return r
+	}
+
+	t.Run("should allow configured origins and patterns", func(t *testing.T) {
+		// given
+		handler := newHandler(Config{AllowedOrigins: []string{"https://shop.example.com", "https://*.shop.dev"}})
+
+		tests := []struct {
+			origin  string
+			allowed bool
+		}{
+			{"https://shop.example.com", true},
+			{"https://SHOP.example.com", true},
+			{"https://pr-42.shop.dev", true},
+			{"https://a.b.shop.dev", true},
+			{"https://shop.dev", false},
+			{"https://.shop.dev", false},
+			{"https://evil.com/.shop.dev", false},
+			{"http://shop.example.com", false},
+			{"https://shop.example.com.evil.com", false},
+		}
+
+		for _, test := range tests {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/v1/products", nil)
+			r.Header.Set("Origin", test.origin)
+
+			// when
+			handler.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusCreated, w.Code, test.origin)
+			if test.allowed {
+				assert.Equal(t, test.origin, w.Header().Get("Access-Control-Allow-Origin"), test.origin)
+			} else {
+				assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), test.origin)
+			}
+			assert.Equal(t, "Origin", w.Header().Get("Vary"), test.origin)
+		}
+	})
+
+	t.Run("should allow all origins without credentials", func(t *testing.T) {
+		// given
+		handler := newHandler(Config{AllowedOrigins: []string{"*"}})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products", nil)
+		r.Header.Set("Origin", "https://anywhere.com")
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Empty(t, w.Header().Get("Vary"))
+	})
+
+	t.Run("should add credentials and exposed headers", func(t *testing.T) {
+		// given
+		handler := newHandler(Config{
+			AllowedOrigins:   []string{"https://shop.example.com"},
+			ExposedHeaders:   []string{"X-Request-ID", "Retry-After"},
+			AllowCredentials: true,
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+		r.Header.Set("Origin", "https://shop.example.com")
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "X-Request-ID, Retry-After", w.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("should answer preflight requests", func(t *testing.T) {
+		// given
+		handler := newHandler(Config{
+			AllowedOrigins: []string{"https://shop.example.com"},
+			AllowedMethods: []string{"get", "post", "put", "delete"},
+			MaxAge:         10 * time.Minute,
+		})
+
+		w := httptest.NewRecorder()
+		r := preflight("https://shop.example.com", "PUT", "authorization, content-type")
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://shop.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, POST, PUT, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "authorization, content-type", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+		assert.Equal(t, []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}, w.Header().Values("Vary"))
+	})
+
+	t.Run("should reject preflight requests which are not allowed", func(t *testing.T) {
+		// given
+		handler := newHandler(Config{AllowedOrigins: []string{"https://shop.example.com"}})
+
+		tests := []*http.Request{
+			preflight("https://evil.com", "POST", ""),
+			preflight("https://shop.example.com", "DELETE", ""),
+			preflight("https://shop.example.com", "POST", "X-Custom"),
+		}
+
+		for _, test := range tests {
+			w := httptest.NewRecorder()
+
+			// when
+			handler.ServeHTTP(w, test)
+
+			// then
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+		}
+	})
+
+	t.Run("should allow every requested header with wildcard", func(t *testing.T) {
+		// given
+		handler := newHandler(Config{AllowedOrigins: []string{"https://shop.example.com"}, AllowedHeaders: []string{"*"}})
+
+		w := httptest.NewRecorder()
+		r := preflight("https://shop.example.com", "POST", "X-Custom")
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, "X-Custom", w.Header().Get("Access-Control-Allow-Headers"))
+	})
+
+	t.Run("should pass OPTIONS requests without preflight headers", func(t *testing.T) {
+		// given
+		handler := newHandler(Config{AllowedOrigins: []string{"https://shop.example.com"}})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("OPTIONS", "/api/v1/products", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+}
@@ -0,0 +1,42 @@
+package gomockhelpers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+)
+
+// Request matches requests derived from r, e.g. by a router which adds the
+// matched route to the context. The given path parameters must be set.
+func Request(r *http.Request, params ...router.Param) *requestMatcher {
+	return &requestMatcher{r, params}
+}
+
+type requestMatcher struct {
+	r      *http.Request
+	params []router.Param
+}
+
+func (matcher *requestMatcher) Matches(x any) bool {
+	r, ok := x.(*http.Request)
+	if !ok {
+		return false
+	}
+
+	if r.Method != matcher.r.Method || r.URL.String() != matcher.r.URL.String() {
+		return false
+	}
+
+	for _, param := range matcher.params {
+		if value, ok := router.Params(r).Lookup(param.Key); !ok || value != param.Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (matcher *requestMatcher) String() string {
+	return fmt.Sprintf("%s %s with params %v", matcher.r.Method, matcher.r.URL, matcher.params)
+}
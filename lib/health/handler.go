@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+type Checker interface {
+	Ping(ctx context.Context) error
+}
+
+type response struct {
+	Status string `json:"status"`
+}
+
+type Handler struct {
+	checkers []Checker
+	timeout  time.Duration
+	ready    atomic.Bool
+}
+
+func NewHandler(checkers ...Checker) *Handler {
+	handler := &Handler{
+		checkers: checkers,
+		timeout:  2 * time.Second,
+	}
+
+	handler.ready.Store(true)
+	return handler
+}
+
+// SetReady lets the readiness check fail regardless of the checkers, e.g.
+// while the service shuts down.
+func (handler *Handler) SetReady(ready bool) {
+	handler.ready.Store(ready)
+}
+
+func (handler *Handler) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeStatus(w, http.StatusOK, "up")
+}
+
+func (handler *Handler) Readiness(w http.ResponseWriter, r *http.Request) {
+	if !handler.ready.Load() {
+		writeStatus(w, http.StatusServiceUnavailable, "down")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), handler.timeout)
+	defer cancel()
+
+	for _, checker := range handler.checkers {
+		if err := checker.Ping(ctx); err != nil {
+			log.Printf("readiness check failed: %s", err.Error())
+			writeStatus(w, http.StatusServiceUnavailable, "down")
+			return
+		}
+	}
+
+	writeStatus(w, http.StatusOK, "up")
+}
+
+func writeStatus(w http.ResponseWriter, statusCode int, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response{status})
+}
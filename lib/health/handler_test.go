@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type checkerFunc func(ctx context.Context) error
+
+func (f checkerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("Liveness", func(t *testing.T) {
+		t.Run("should return 200 OK", func(t *testing.T) {
+			// given
+			handler := NewHandler(checkerFunc(func(ctx context.Context) error {
+				return errors.New("database down")
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/healthz", nil)
+
+			// when
+			handler.Liveness(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, `{"status":"up"}`, w.Body.String())
+		})
+	})
+
+	t.Run("Readiness", func(t *testing.T) {
+		t.Run("should return 503 SERVICE UNAVAILABLE if a check fails", func(t *testing.T) {
+			// given
+			handler := NewHandler(
+				checkerFunc(func(ctx context.Context) error { return nil }),
+				checkerFunc(func(ctx context.Context) error { return errors.New("database down") }),
+			)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/readyz", nil)
+
+			// when
+			handler.Readiness(w, r)
+
+			// then
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.JSONEq(t, `{"status":"down"}`, w.Body.String())
+		})
+
+		t.Run("should return 200 OK if all checks pass", func(t *testing.T) {
+			// given
+			var deadlineSet bool
+			handler := NewHandler(checkerFunc(func(ctx context.Context) error {
+				_, deadlineSet = ctx.Deadline()
+				return nil
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/readyz", nil)
+
+			// when
+			handler.Readiness(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.True(t, deadlineSet)
+			assert.JSONEq(t, `{"status":"up"}`, w.Body.String())
+		})
+
+		t.Run("should return 503 SERVICE UNAVAILABLE if not ready", func(t *testing.T) {
+			// given
+			var checked bool
+			handler := NewHandler(checkerFunc(func(ctx context.Context) error {
+				checked = true
+				return nil
+			}))
+			handler.SetReady(false)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/readyz", nil)
+
+			// when
+			handler.Readiness(w, r)
+
+			// then
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.False(t, checked)
+			assert.JSONEq(t, `{"status":"down"}`, w.Body.String())
+		})
+	})
+}
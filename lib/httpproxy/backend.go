@@ -0,0 +1,124 @@
+package httpproxy
+
+import (
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Backend struct {
+	url               *url.URL
+	activeConnections atomic.Int64
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	ejectedUntil        time.Time
+
+	healthy          bool
+	healthCheckCount int
+	lastHealthCheck  time.Time
+	lastHealthError  string
+}
+
+func newBackend(target string) *Backend {
+	targetUrl, err := url.Parse(target)
+	if err != nil {
+		panic(err)
+	}
+
+	return &Backend{
+		url:     targetUrl,
+		healthy: true,
+	}
+}
+
+func (backend *Backend) URL() *url.URL {
+	return backend.url
+}
+
+func (backend *Backend) ActiveConnections() int64 {
+	return backend.activeConnections.Load()
+}
+
+func (backend *Backend) available(now time.Time) bool {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+
+	return backend.healthy && !now.Before(backend.ejectedUntil)
+}
+
+func (backend *Backend) reportFailure(now time.Time, maxFails int, failTimeout time.Duration) {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+
+	backend.consecutiveFailures++
+	if maxFails > 0 && backend.consecutiveFailures >= maxFails {
+		backend.ejectedUntil = now.Add(failTimeout)
+	}
+}
+
+func (backend *Backend) reportSuccess() {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+
+	backend.consecutiveFailures = 0
+	backend.ejectedUntil = time.Time{}
+}
+
+// reportHealthCheck counts consecutive results of the same kind in
+// healthCheckCount: positive values are successes, negative values failures.
+func (backend *Backend) reportHealthCheck(now time.Time, err error, rise int, fall int) {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+
+	backend.lastHealthCheck = now
+
+	if err == nil {
+		backend.lastHealthError = ""
+		if backend.healthCheckCount < 0 {
+			backend.healthCheckCount = 0
+		}
+		backend.healthCheckCount++
+
+		if !backend.healthy && backend.healthCheckCount >= rise {
+			backend.healthy = true
+		}
+		return
+	}
+
+	backend.lastHealthError = err.Error()
+	if backend.healthCheckCount > 0 {
+		backend.healthCheckCount = 0
+	}
+	backend.healthCheckCount--
+
+	if backend.healthy && -backend.healthCheckCount >= fall {
+		backend.healthy = false
+	}
+}
+
+type BackendStatus struct {
+	URL                 string    `json:"url"`
+	Healthy             bool      `json:"healthy"`
+	Ejected             bool      `json:"ejected"`
+	ActiveConnections   int64     `json:"activeConnections"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	LastHealthCheck     time.Time `json:"lastHealthCheck"`
+	LastHealthError     string    `json:"lastHealthError,omitempty"`
+}
+
+func (backend *Backend) status(now time.Time) BackendStatus {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+
+	return BackendStatus{
+		URL:                 backend.url.String(),
+		Healthy:             backend.healthy,
+		Ejected:             now.Before(backend.ejectedUntil),
+		ActiveConnections:   backend.activeConnections.Load(),
+		ConsecutiveFailures: backend.consecutiveFailures,
+		LastHealthCheck:     backend.lastHealthCheck,
+		LastHealthError:     backend.lastHealthError,
+	}
+}
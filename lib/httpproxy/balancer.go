@@ -0,0 +1,115 @@
+package httpproxy
+
+import (
+	"hash/fnv"
+	"net/http"
+	"sync/atomic"
+)
+
+type Balancer interface {
+	Next(r *http.Request, backends []*Backend) *Backend
+}
+
+type KeyFunc func(r *http.Request) string
+
+func HeaderKey(name string) KeyFunc {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+func CookieKey(name string) KeyFunc {
+	return func(r *http.Request) string {
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			return ""
+		}
+
+		return cookie.Value
+	}
+}
+
+type RoundRobinBalancer struct {
+	counter atomic.Uint64
+}
+
+func NewRoundRobinBalancer() *RoundRobinBalancer {
+	return &RoundRobinBalancer{}
+}
+
+func (balancer *RoundRobinBalancer) Next(r *http.Request, backends []*Backend) *Backend {
+	if len(backends) == 0 {
+		return nil
+	}
+
+	n := balancer.counter.Add(1) - 1
+	return backends[n%uint64(len(backends))]
+}
+
+type LeastConnectionsBalancer struct {
+	counter atomic.Uint64
+}
+
+func NewLeastConnectionsBalancer() *LeastConnectionsBalancer {
+	return &LeastConnectionsBalancer{}
+}
+
+func (balancer *LeastConnectionsBalancer) Next(r *http.Request, backends []*Backend) *Backend {
+	if len(backends) == 0 {
+		return nil
+	}
+
+	// start at a rotating offset, so ties are spread over all backends
+	offset := int((balancer.counter.Add(1) - 1) % uint64(len(backends)))
+
+	var selected *Backend
+	for i := 0; i < len(backends); i++ {
+		backend := backends[(offset+i)%len(backends)]
+		if selected == nil || backend.ActiveConnections() < selected.ActiveConnections() {
+			selected = backend
+		}
+	}
+
+	return selected
+}
+
+type ConsistentHashBalancer struct {
+	key      KeyFunc
+	fallback Balancer
+}
+
+func NewConsistentHashBalancer(key KeyFunc) *ConsistentHashBalancer {
+	return &ConsistentHashBalancer{
+		key:      key,
+		fallback: NewRoundRobinBalancer(),
+	}
+}
+
+// Next uses rendezvous hashing, so removing a backend from the pool only
+// remaps the keys which were assigned to that backend.
+func (balancer *ConsistentHashBalancer) Next(r *http.Request, backends []*Backend) *Backend {
+	key := balancer.key(r)
+	if key == "" {
+		return balancer.fallback.Next(r, backends)
+	}
+
+	var selected *Backend
+	var selectedScore uint64
+	for _, backend := range backends {
+		score := hashKey(backend.url.String(), key)
+		if selected == nil || score > selectedScore {
+			selected = backend
+			selectedScore = score
+		}
+	}
+
+	return selected
+}
+
+func hashKey(backend string, key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(backend))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return h.Sum64()
+}
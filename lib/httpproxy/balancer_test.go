@@ -0,0 +1,131 @@
+package httpproxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalancer(t *testing.T) {
+	backends := []*Backend{
+		newBackend("http://backend-1:3000"),
+		newBackend("http://backend-2:3000"),
+		newBackend("http://backend-3:3000"),
+	}
+
+	t.Run("RoundRobinBalancer", func(t *testing.T) {
+		t.Run("should return nil if there are no backends", func(t *testing.T) {
+			// given
+			balancer := NewRoundRobinBalancer()
+			r := httptest.NewRequest("GET", "/", nil)
+
+			// when
+			backend := balancer.Next(r, nil)
+
+			// then
+			assert.Nil(t, backend)
+		})
+
+		t.Run("should rotate through all backends", func(t *testing.T) {
+			// given
+			balancer := NewRoundRobinBalancer()
+			r := httptest.NewRequest("GET", "/", nil)
+
+			// when
+			selected := make([]*Backend, 4)
+			for i := range selected {
+				selected[i] = balancer.Next(r, backends)
+			}
+
+			// then
+			assert.Equal(t, []*Backend{backends[0], backends[1], backends[2], backends[0]}, selected)
+		})
+	})
+
+	t.Run("LeastConnectionsBalancer", func(t *testing.T) {
+		t.Run("should return backend with the fewest active connections", func(t *testing.T) {
+			// given
+			balancer := NewLeastConnectionsBalancer()
+			r := httptest.NewRequest("GET", "/", nil)
+
+			backends[0].activeConnections.Store(5)
+			backends[1].activeConnections.Store(1)
+			backends[2].activeConnections.Store(3)
+			t.Cleanup(func() {
+				for _, backend := range backends {
+					backend.activeConnections.Store(0)
+				}
+			})
+
+			for i := 0; i < len(backends); i++ {
+				// when
+				backend := balancer.Next(r, backends)
+
+				// then
+				assert.Equal(t, backends[1], backend)
+			}
+		})
+	})
+
+	t.Run("ConsistentHashBalancer", func(t *testing.T) {
+		t.Run("should route the same key to the same backend", func(t *testing.T) {
+			// given
+			balancer := NewConsistentHashBalancer(HeaderKey("X-Session"))
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("X-Session", "session-1")
+
+			// when
+			first := balancer.Next(r, backends)
+			second := balancer.Next(r, backends)
+
+			// then
+			assert.NotNil(t, first)
+			assert.Equal(t, first, second)
+		})
+
+		t.Run("should only remap keys of a removed backend", func(t *testing.T) {
+			// given
+			balancer := NewConsistentHashBalancer(CookieKey("session"))
+
+			assignments := make(map[string]*Backend)
+			for i := 0; i < 100; i++ {
+				r := httptest.NewRequest("GET", "/", nil)
+				r.AddCookie(&http.Cookie{Name: "session", Value: fmt.Sprintf("session-%d", i)})
+				assignments[fmt.Sprintf("session-%d", i)] = balancer.Next(r, backends)
+			}
+
+			// when
+			remaining := []*Backend{backends[0], backends[2]}
+
+			// then
+			for key, previous := range assignments {
+				r := httptest.NewRequest("GET", "/", nil)
+				r.AddCookie(&http.Cookie{Name: "session", Value: key})
+				backend := balancer.Next(r, remaining)
+
+				if previous != backends[1] {
+					assert.Equal(t, previous, backend)
+				} else {
+					assert.NotEqual(t, backends[1], backend)
+				}
+			}
+		})
+
+		t.Run("should fall back to round robin if key is missing", func(t *testing.T) {
+			// given
+			balancer := NewConsistentHashBalancer(HeaderKey("X-Session"))
+			r := httptest.NewRequest("GET", "/", nil)
+
+			// when
+			first := balancer.Next(r, backends)
+			second := balancer.Next(r, backends)
+
+			// then
+			assert.Equal(t, backends[0], first)
+			assert.Equal(t, backends[1], second)
+		})
+	})
+}
@@ -0,0 +1,107 @@
+package httpproxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type HealthCheck struct {
+	Path     string
+	Interval time.Duration
+	Timeout  time.Duration
+	Rise     int
+	Fall     int
+}
+
+// WithActiveHealthCheck polls the health check path of every backend. A
+// backend is marked unhealthy after Fall failed checks in a row and healthy
+// again after Rise successful checks in a row.
+func WithActiveHealthCheck(healthCheck HealthCheck) RouteOption {
+	return func(route *route) {
+		if healthCheck.Interval <= 0 {
+			healthCheck.Interval = 10 * time.Second
+		}
+		if healthCheck.Timeout <= 0 {
+			healthCheck.Timeout = 2 * time.Second
+		}
+		if healthCheck.Rise <= 0 {
+			healthCheck.Rise = 2
+		}
+		if healthCheck.Fall <= 0 {
+			healthCheck.Fall = 3
+		}
+
+		route.pool.healthCheck = &healthCheck
+	}
+}
+
+func (proxy *Proxy) StartHealthChecks(ctx context.Context) {
+	client := &http.Client{Transport: proxy.transport}
+
+	for _, route := range proxy.routes {
+		if route.pool.healthCheck == nil {
+			continue
+		}
+
+		go route.pool.runHealthChecks(ctx, client)
+	}
+}
+
+func (pool *Pool) runHealthChecks(ctx context.Context, client *http.Client) {
+	ticker := time.NewTicker(pool.healthCheck.Interval)
+	defer ticker.Stop()
+
+	for {
+		pool.checkHealth(ctx, client)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (pool *Pool) checkHealth(ctx context.Context, client *http.Client) {
+	var wg sync.WaitGroup
+
+	for _, backend := range pool.backends {
+		wg.Add(1)
+		go func(backend *Backend) {
+			defer wg.Done()
+			err := pool.probe(ctx, client, backend)
+			backend.reportHealthCheck(pool.now(), err, pool.healthCheck.Rise, pool.healthCheck.Fall)
+		}(backend)
+	}
+
+	wg.Wait()
+}
+
+func (pool *Pool) probe(ctx context.Context, client *http.Client, backend *Backend) error {
+	ctx, cancel := context.WithTimeout(ctx, pool.healthCheck.Timeout)
+	defer cancel()
+
+	target := *backend.url
+	target.Path = joinPaths(target.Path, pool.healthCheck.Path)
+	target.RawQuery = ""
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package httpproxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheck(t *testing.T) {
+	var status atomic.Int32
+	var checkedPath atomic.Value
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checkedPath.Store(r.URL.Path)
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(upstream.Close)
+
+	newTestRoute := func() *route {
+		route := &route{prefix: "/", pool: NewPool([]string{upstream.URL})}
+		WithActiveHealthCheck(HealthCheck{Path: "/healthz", Rise: 2, Fall: 2})(route)
+		return route
+	}
+
+	t.Run("should probe the configured health path", func(t *testing.T) {
+		// given
+		route := newTestRoute()
+		status.Store(http.StatusOK)
+
+		// when
+		route.pool.checkHealth(context.Background(), http.DefaultClient)
+
+		// then
+		assert.Equal(t, "/healthz", checkedPath.Load())
+		assert.True(t, route.pool.Status()[0].Healthy)
+	})
+
+	t.Run("should mark backend unhealthy after fall threshold", func(t *testing.T) {
+		// given
+		route := newTestRoute()
+		status.Store(http.StatusServiceUnavailable)
+
+		// when
+		route.pool.checkHealth(context.Background(), http.DefaultClient)
+		healthyAfterFirstCheck := route.pool.Status()[0].Healthy
+		route.pool.checkHealth(context.Background(), http.DefaultClient)
+
+		// then
+		assert.True(t, healthyAfterFirstCheck)
+		assert.False(t, route.pool.Status()[0].Healthy)
+		assert.Equal(t, "unexpected status code 503", route.pool.Status()[0].LastHealthError)
+		assert.Nil(t, route.pool.Next(httptest.NewRequest("GET", "/", nil)))
+	})
+
+	t.Run("should mark backend healthy again after rise threshold", func(t *testing.T) {
+		// given
+		route := newTestRoute()
+		status.Store(http.StatusServiceUnavailable)
+		route.pool.checkHealth(context.Background(), http.DefaultClient)
+		route.pool.checkHealth(context.Background(), http.DefaultClient)
+
+		// when
+		status.Store(http.StatusOK)
+		route.pool.checkHealth(context.Background(), http.DefaultClient)
+		healthyAfterFirstCheck := route.pool.Status()[0].Healthy
+		route.pool.checkHealth(context.Background(), http.DefaultClient)
+
+		// then
+		assert.False(t, healthyAfterFirstCheck)
+		assert.True(t, route.pool.Status()[0].Healthy)
+		assert.NotNil(t, route.pool.Next(httptest.NewRequest("GET", "/", nil)))
+	})
+
+	t.Run("should report status of all routes", func(t *testing.T) {
+		// given
+		proxy := New()
+		proxy.MapPool("/api/v1/products", []string{"http://backend-1:3000", "http://backend-2:3000"})
+		proxy.Map("/", "http://web:3000")
+
+		// when
+		statuses := proxy.Status()
+
+		// then
+		assert.Len(t, statuses, 2)
+		assert.Equal(t, "/api/v1/products", statuses[0].Prefix)
+		assert.Len(t, statuses[0].Backends, 2)
+		assert.Equal(t, "http://backend-1:3000", statuses[0].Backends[0].URL)
+		assert.True(t, statuses[0].Backends[0].Healthy)
+	})
+}
@@ -0,0 +1,71 @@
+package httpproxy
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	defaultMaxFails    = 3
+	defaultFailTimeout = 30 * time.Second
+)
+
+type Pool struct {
+	backends    []*Backend
+	balancer    Balancer
+	maxFails    int
+	failTimeout time.Duration
+	healthCheck *HealthCheck
+	now         func() time.Time
+}
+
+func NewPool(targets []string) *Pool {
+	backends := make([]*Backend, len(targets))
+	for i, target := range targets {
+		backends[i] = newBackend(target)
+	}
+
+	return &Pool{
+		backends:    backends,
+		balancer:    NewRoundRobinBalancer(),
+		maxFails:    defaultMaxFails,
+		failTimeout: defaultFailTimeout,
+		now:         time.Now,
+	}
+}
+
+func (pool *Pool) Backends() []*Backend {
+	return pool.backends
+}
+
+func (pool *Pool) Next(r *http.Request) *Backend {
+	now := pool.now()
+
+	available := make([]*Backend, 0, len(pool.backends))
+	for _, backend := range pool.backends {
+		if backend.available(now) {
+			available = append(available, backend)
+		}
+	}
+
+	return pool.balancer.Next(r, available)
+}
+
+func (pool *Pool) reportFailure(backend *Backend) {
+	backend.reportFailure(pool.now(), pool.maxFails, pool.failTimeout)
+}
+
+func (pool *Pool) reportSuccess(backend *Backend) {
+	backend.reportSuccess()
+}
+
+func (pool *Pool) Status() []BackendStatus {
+	now := pool.now()
+
+	statuses := make([]BackendStatus, len(pool.backends))
+	for i, backend := range pool.backends {
+		statuses[i] = backend.status(now)
+	}
+
+	return statuses
+}
@@ -0,0 +1,77 @@
+package httpproxy
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool(t *testing.T) {
+	now := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	newTestPool := func() *Pool {
+		pool := NewPool([]string{"http://backend-1:3000", "http://backend-2:3000"})
+		pool.maxFails = 2
+		pool.failTimeout = 10 * time.Second
+		pool.now = func() time.Time { return now }
+		return pool
+	}
+
+	t.Run("should eject backend after max consecutive failures", func(t *testing.T) {
+		// given
+		pool := newTestPool()
+		r := httptest.NewRequest("GET", "/", nil)
+
+		// when
+		pool.reportFailure(pool.backends[0])
+		pool.reportFailure(pool.backends[0])
+
+		// then
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, pool.backends[1], pool.Next(r))
+		}
+	})
+
+	t.Run("should not eject backend if failures are not consecutive", func(t *testing.T) {
+		// given
+		pool := newTestPool()
+
+		// when
+		pool.reportFailure(pool.backends[0])
+		pool.reportSuccess(pool.backends[0])
+		pool.reportFailure(pool.backends[0])
+
+		// then
+		assert.True(t, pool.backends[0].available(now))
+	})
+
+	t.Run("should return ejected backend after fail timeout", func(t *testing.T) {
+		// given
+		pool := newTestPool()
+		pool.reportFailure(pool.backends[0])
+		pool.reportFailure(pool.backends[0])
+
+		// when
+		available := pool.backends[0].available(now.Add(10 * time.Second))
+
+		// then
+		assert.True(t, available)
+	})
+
+	t.Run("should return nil if all backends are ejected", func(t *testing.T) {
+		// given
+		pool := newTestPool()
+		r := httptest.NewRequest("GET", "/", nil)
+
+		// when
+		for _, backend := range pool.backends {
+			pool.reportFailure(backend)
+			pool.reportFailure(backend)
+		}
+
+		// then
+		assert.Nil(t, pool.Next(r))
+	})
+}
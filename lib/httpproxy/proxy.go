@@ -0,0 +1,344 @@
+package httpproxy
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type route struct {
+	prefix      string
+	pool        *Pool
+	stripPrefix bool
+	middlewares []func(http.Handler) http.Handler
+	handler     http.Handler
+}
+
+type RouteOption func(*route)
+
+func StripPrefix() RouteOption {
+	return func(route *route) {
+		route.stripPrefix = true
+	}
+}
+
+// WithMiddleware wraps the forwarding of this route. Middlewares are applied in
+// the given order, so the first one sees the request first.
+func WithMiddleware(middlewares ...func(http.Handler) http.Handler) RouteOption {
+	return func(route *route) {
+		route.middlewares = append(route.middlewares, middlewares...)
+	}
+}
+
+func WithBalancer(balancer Balancer) RouteOption {
+	return func(route *route) {
+		route.pool.balancer = balancer
+	}
+}
+
+// WithPassiveHealthCheck removes a backend from the pool for failTimeout after
+// maxFails consecutive connection failures. A maxFails of 0 disables it.
+func WithPassiveHealthCheck(maxFails int, failTimeout time.Duration) RouteOption {
+	return func(route *route) {
+		route.pool.maxFails = maxFails
+		route.pool.failTimeout = failTimeout
+	}
+}
+
+type Proxy struct {
+	routes    []*route
+	transport http.RoundTripper
+	logger    *slog.Logger
+}
+
+type Option func(*Proxy)
+
+// WithTransport sets the transport used to forward requests to the upstreams
+// and to probe their health.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(proxy *Proxy) {
+		proxy.transport = transport
+	}
+}
+
+// WithLogger sets the logger for failed upstream requests, default
+// slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(proxy *Proxy) {
+		proxy.logger = logger
+	}
+}
+
+func New(opts ...Option) *Proxy {
+	proxy := &Proxy{
+		transport: http.DefaultTransport,
+		logger:    slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(proxy)
+	}
+
+	return proxy
+}
+
+func (proxy *Proxy) Map(prefix string, target string, opts ...RouteOption) {
+	proxy.MapPool(prefix, []string{target}, opts...)
+}
+
+func (proxy *Proxy) MapPool(prefix string, targets []string, opts ...RouteOption) {
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+
+	route := &route{
+		prefix: prefix,
+		pool:   NewPool(targets),
+	}
+
+	for _, opt := range opts {
+		opt(route)
+	}
+
+	route.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxy.forward(w, r, route)
+	})
+	for i := len(route.middlewares) - 1; i >= 0; i-- {
+		route.handler = route.middlewares[i](route.handler)
+	}
+
+	proxy.routes = append(proxy.routes, route)
+	sort.SliceStable(proxy.routes, func(i, j int) bool {
+		return len(proxy.routes[i].prefix) > len(proxy.routes[j].prefix)
+	})
+}
+
+type RouteStatus struct {
+	Prefix   string          `json:"prefix"`
+	Backends []BackendStatus `json:"backends"`
+}
+
+func (proxy *Proxy) Status() []RouteStatus {
+	statuses := make([]RouteStatus, len(proxy.routes))
+	for i, route := range proxy.routes {
+		statuses[i] = RouteStatus{
+			Prefix:   route.prefix,
+			Backends: route.pool.Status(),
+		}
+	}
+
+	return statuses
+}
+
+func (proxy *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route := proxy.match(r.URL.Path)
+	if route == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	route.handler.ServeHTTP(w, r)
+}
+
+func (proxy *Proxy) forward(w http.ResponseWriter, r *http.Request, route *route) {
+	backend := route.pool.Next(r)
+	if backend == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	backend.activeConnections.Add(1)
+	defer backend.activeConnections.Add(-1)
+
+	res, err := proxy.transport.RoundTrip(route.createUpstreamRequest(r, backend.url))
+	if err != nil && r.Context().Err() != nil {
+		// The client went away, which says nothing about the health of the
+		// backend.
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	if err != nil {
+		proxy.logger.ErrorContext(r.Context(), "could not reach upstream",
+			slog.String("upstream", backend.url.String()),
+			slog.String("error", err.Error()),
+		)
+		route.pool.reportFailure(backend)
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	defer res.Body.Close()
+
+	route.pool.reportSuccess(backend)
+
+	removeHopByHopHeaders(res.Header)
+	copyHeader(w.Header(), res.Header)
+
+	w.WriteHeader(res.StatusCode)
+
+	if err := copyBody(w, res.Body, res.ContentLength == -1); err != nil {
+		proxy.logger.ErrorContext(r.Context(), "could not stream response",
+			slog.String("upstream", backend.url.String()),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	for key, values := range res.Trailer {
+		for _, value := range values {
+			w.Header().Add(http.TrailerPrefix+key, value)
+		}
+	}
+}
+
+func (proxy *Proxy) match(path string) *route {
+	for _, route := range proxy.routes {
+		if route.matches(path) {
+			return route
+		}
+	}
+
+	return nil
+}
+
+func (route *route) matches(path string) bool {
+	if !strings.HasPrefix(path, route.prefix) {
+		return false
+	}
+
+	return len(path) == len(route.prefix) ||
+		strings.HasSuffix(route.prefix, "/") ||
+		path[len(route.prefix)] == '/'
+}
+
+func (route *route) createUpstreamRequest(r *http.Request, target *url.URL) *http.Request {
+	upstreamReq := r.Clone(r.Context())
+	upstreamReq.RequestURI = ""
+	upstreamReq.Host = target.Host
+	upstreamReq.Close = false
+
+	if r.ContentLength == 0 {
+		upstreamReq.Body = nil
+	}
+
+	path := r.URL.Path
+	if route.stripPrefix {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(route.prefix, "/"))
+	}
+
+	upstreamReq.URL.Scheme = target.Scheme
+	upstreamReq.URL.Host = target.Host
+	upstreamReq.URL.Path = joinPaths(target.Path, path)
+	upstreamReq.URL.RawPath = ""
+
+	if target.RawQuery == "" || r.URL.RawQuery == "" {
+		upstreamReq.URL.RawQuery = target.RawQuery + r.URL.RawQuery
+	} else {
+		upstreamReq.URL.RawQuery = target.RawQuery + "&" + r.URL.RawQuery
+	}
+
+	removeHopByHopHeaders(upstreamReq.Header)
+	setForwardedHeaders(upstreamReq.Header, r)
+
+	return upstreamReq
+}
+
+func joinPaths(a, b string) string {
+	if b == "" {
+		b = "/"
+	}
+
+	aSlash := strings.HasSuffix(a, "/")
+	bSlash := strings.HasPrefix(b, "/")
+
+	switch {
+	case aSlash && bSlash:
+		return a + b[1:]
+	case !aSlash && !bSlash:
+		return a + "/" + b
+	}
+
+	return a + b
+}
+
+var hopByHopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func removeHopByHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+
+	for _, name := range hopByHopHeaders {
+		header.Del(name)
+	}
+}
+
+func setForwardedHeaders(header http.Header, r *http.Request) {
+	if clientIp, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		if prior := header.Values("X-Forwarded-For"); len(prior) > 0 {
+			clientIp = strings.Join(prior, ", ") + ", " + clientIp
+		}
+		header.Set("X-Forwarded-For", clientIp)
+	}
+
+	header.Set("X-Forwarded-Host", r.Host)
+
+	if r.TLS != nil {
+		header.Set("X-Forwarded-Proto", "https")
+	} else {
+		header.Set("X-Forwarded-Proto", "http")
+	}
+}
+
+func copyHeader(dst, src http.Header) {
+	for key, values := range src {
+		for _, value := range values {
+			dst.Add(key, value)
+		}
+	}
+}
+
+func copyBody(w http.ResponseWriter, body io.Reader, flushEachWrite bool) error {
+	flusher, canFlush := w.(http.Flusher)
+	if !flushEachWrite || !canFlush {
+		_, err := io.Copy(w, body)
+		return err
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := body.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+
+		if readErr == io.EOF {
+			return nil
+		}
+
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package httpproxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxy(t *testing.T) {
+	var upstreamReq *http.Request
+	var upstreamBody string
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		upstreamReq = r
+		upstreamBody = string(body)
+
+		w.Header().Set("Connection", "X-Internal")
+		w.Header().Set("X-Internal", "secret")
+		w.Header().Set("X-Upstream", "products")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("upstream response"))
+	}))
+	t.Cleanup(upstream.Close)
+
+	t.Run("should return 404 NOT FOUND if no route matches", func(t *testing.T) {
+		// given
+		proxy := New()
+		proxy.Map("/api/v1/products", upstream.URL)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/productsandmore", nil)
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should return 502 BAD GATEWAY if upstream is not reachable", func(t *testing.T) {
+		// given
+		proxy := New()
+		proxy.Map("/", "http://127.0.0.1:1")
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+	})
+
+	t.Run("should forward request to the longest matching prefix", func(t *testing.T) {
+		// given
+		proxy := New()
+		proxy.Map("/", "http://127.0.0.1:1")
+		proxy.Map("/api/v1/products", upstream.URL)
+		proxy.Map("/api/v1", "http://127.0.0.1:1")
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products/1?fields=name", nil)
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "upstream response", w.Body.String())
+		assert.Equal(t, "products", w.Header().Get("X-Upstream"))
+		assert.Equal(t, "/api/v1/products/1", upstreamReq.URL.Path)
+		assert.Equal(t, "fields=name", upstreamReq.URL.RawQuery)
+	})
+
+	t.Run("should strip prefix if configured", func(t *testing.T) {
+		// given
+		proxy := New()
+		proxy.Map("/api/v1/products", upstream.URL+"/v2", StripPrefix())
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products/1", nil)
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "/v2/1", upstreamReq.URL.Path)
+	})
+
+	t.Run("should stream request body to upstream", func(t *testing.T) {
+		// given
+		proxy := New()
+		proxy.Map("/", upstream.URL)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/products", strings.NewReader(`{"name":"test product"}`))
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, `{"name":"test product"}`, upstreamBody)
+	})
+
+	t.Run("should remove hop-by-hop headers", func(t *testing.T) {
+		// given
+		proxy := New()
+		proxy.Map("/", upstream.URL)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Connection", "X-Client-Hop")
+		r.Header.Set("X-Client-Hop", "value")
+		r.Header.Set("Proxy-Authorization", "Basic abc")
+		r.Header.Set("X-End-To-End", "value")
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Empty(t, upstreamReq.Header.Get("X-Client-Hop"))
+		assert.Empty(t, upstreamReq.Header.Get("Proxy-Authorization"))
+		assert.Equal(t, "value", upstreamReq.Header.Get("X-End-To-End"))
+		assert.Empty(t, w.Header().Get("Connection"))
+		assert.Empty(t, w.Header().Get("X-Internal"))
+	})
+
+	t.Run("should set X-Forwarded headers", func(t *testing.T) {
+		// given
+		proxy := New()
+		proxy.Map("/", upstream.URL)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "http://shop.example.com/", nil)
+		r.RemoteAddr = "10.0.0.2:51234"
+		r.Header.Set("X-Forwarded-For", "192.168.0.1")
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, "192.168.0.1, 10.0.0.2", upstreamReq.Header.Get("X-Forwarded-For"))
+		assert.Equal(t, "shop.example.com", upstreamReq.Header.Get("X-Forwarded-Host"))
+		assert.Equal(t, "http", upstreamReq.Header.Get("X-Forwarded-Proto"))
+	})
+
+	t.Run("should balance requests across the pool", func(t *testing.T) {
+		// given
+		var hits [2]int
+		first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits[0]++ }))
+		second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits[1]++ }))
+		t.Cleanup(first.Close)
+		t.Cleanup(second.Close)
+
+		proxy := New()
+		proxy.MapPool("/", []string{first.URL, second.URL}, WithBalancer(NewRoundRobinBalancer()))
+
+		// when
+		for i := 0; i < 4; i++ {
+			proxy.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}
+
+		// then
+		assert.Equal(t, [2]int{2, 2}, hits)
+	})
+
+	t.Run("should return 503 SERVICE UNAVAILABLE if all backends are ejected", func(t *testing.T) {
+		// given
+		proxy := New()
+		proxy.Map("/", "http://127.0.0.1:1", WithPassiveHealthCheck(1, time.Minute))
+		proxy.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("should not eject backend if client cancels the request", func(t *testing.T) {
+		// given
+		received := make(chan struct{}, 1)
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				received <- struct{}{}
+				<-r.Context().Done()
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(slow.Close)
+
+		proxy := New()
+		proxy.Map("/", slow.URL, WithPassiveHealthCheck(1, time.Minute))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-received
+			cancel()
+		}()
+		proxy.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil).WithContext(ctx))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should apply route middlewares in order", func(t *testing.T) {
+		// given
+		var order []string
+		middleware := func(name string) func(http.Handler) http.Handler {
+			return func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					order = append(order, name)
+					r.Header.Set("X-Middleware", name)
+					next.ServeHTTP(w, r)
+				})
+			}
+		}
+
+		proxy := New()
+		proxy.Map("/", upstream.URL, WithMiddleware(middleware("first"), middleware("second")))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, []string{"first", "second"}, order)
+		assert.Equal(t, "second", upstreamReq.Header.Get("X-Middleware"))
+	})
+
+	t.Run("should not forward request if middleware responds", func(t *testing.T) {
+		// given
+		upstreamReq = nil
+		reject := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			})
+		}
+
+		proxy := New()
+		proxy.Map("/", upstream.URL, WithMiddleware(reject))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Nil(t, upstreamReq)
+	})
+	t.Run("should forward requests with the configured transport", func(t *testing.T) {
+		// given
+		var transportReq *http.Request
+		transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			transportReq = r
+			return http.DefaultTransport.RoundTrip(r)
+		})
+
+		proxy := New(WithTransport(transport))
+		proxy.Map("/", upstream.URL)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products", nil)
+
+		// when
+		proxy.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "/api/v1/products", transportReq.URL.Path)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,79 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyId     string `json:"kid"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+func NewJSONWebKey(publicKey *ecdsa.PublicKey) JSONWebKey {
+	size := (publicKey.Curve.Params().BitSize + 7) / 8
+
+	return JSONWebKey{
+		KeyType:   "EC",
+		KeyId:     Thumbprint(publicKey),
+		Use:       "sig",
+		Algorithm: "ES256",
+		Curve:     publicKey.Curve.Params().Name,
+		X:         base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size))),
+		Y:         base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size))),
+	}
+}
+
+func (key JSONWebKey) PublicKey() (*ecdsa.PublicKey, error) {
+	if key.KeyType != "EC" || key.Curve != "P-256" {
+		return nil, fmt.Errorf("unsupported key type %s with curve %s", key.KeyType, key.Curve)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(key.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(key.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+
+	if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+		return nil, errors.New("point is not on curve")
+	}
+
+	return publicKey, nil
+}
+
+// Thumbprint returns the RFC 7638 thumbprint of the key, which is used as its
+// key id.
+func Thumbprint(publicKey *ecdsa.PublicKey) string {
+	size := (publicKey.Curve.Params().BitSize + 7) / 8
+	x := base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size)))
+	y := base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size)))
+
+	canonical := fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, publicKey.Curve.Params().Name, x, y)
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
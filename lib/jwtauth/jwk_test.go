@@ -0,0 +1,86 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONWebKey(t *testing.T) {
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	t.Run("NewJSONWebKey", func(t *testing.T) {
+		t.Run("should encode public key", func(t *testing.T) {
+			// given
+			// when
+			key := NewJSONWebKey(&privateKey.PublicKey)
+
+			// then
+			assert.Equal(t, "EC", key.KeyType)
+			assert.Equal(t, "P-256", key.Curve)
+			assert.Equal(t, "ES256", key.Algorithm)
+			assert.Equal(t, Thumbprint(&privateKey.PublicKey), key.KeyId)
+			assert.Len(t, key.X, 43)
+			assert.Len(t, key.Y, 43)
+		})
+	})
+
+	t.Run("PublicKey", func(t *testing.T) {
+		t.Run("should return error if key type is not supported", func(t *testing.T) {
+			// given
+			key := JSONWebKey{KeyType: "RSA"}
+
+			// when
+			_, err := key.PublicKey()
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should return error if point is not on curve", func(t *testing.T) {
+			// given
+			key := NewJSONWebKey(&privateKey.PublicKey)
+			key.Y = key.X
+
+			// when
+			_, err := key.PublicKey()
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should decode encoded public key", func(t *testing.T) {
+			// given
+			data, _ := json.Marshal(NewJSONWebKey(&privateKey.PublicKey))
+
+			var key JSONWebKey
+			json.Unmarshal(data, &key)
+
+			// when
+			publicKey, err := key.PublicKey()
+
+			// then
+			assert.NoError(t, err)
+			assert.True(t, privateKey.PublicKey.Equal(publicKey))
+		})
+	})
+
+	t.Run("Thumbprint", func(t *testing.T) {
+		t.Run("should differ between keys", func(t *testing.T) {
+			// given
+			otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+			// when
+			first := Thumbprint(&privateKey.PublicKey)
+			second := Thumbprint(&otherKey.PublicKey)
+
+			// then
+			assert.NotEqual(t, first, second)
+			assert.Equal(t, first, Thumbprint(&privateKey.PublicKey))
+		})
+	})
+}
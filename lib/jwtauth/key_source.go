@@ -0,0 +1,135 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var ErrUnknownKey = errors.New("unknown key")
+
+type KeySource interface {
+	PublicKey(kid string) (*ecdsa.PublicKey, error)
+}
+
+type staticKeySource struct {
+	publicKey *ecdsa.PublicKey
+}
+
+func (source staticKeySource) PublicKey(kid string) (*ecdsa.PublicKey, error) {
+	return source.publicKey, nil
+}
+
+// JWKSKeySource fetches the public keys from a JSON Web Key Set endpoint. The
+// keys are refreshed after maxAge, or earlier if a token references an unknown
+// key id, but at most once per minRefreshInterval. Keys are fetched without
+// holding the lock and concurrent refreshes share one request. If a refresh
+// fails, the last keys are used further and the refresh is retried after
+// minRefreshInterval.
+type JWKSKeySource struct {
+	url                string
+	client             *http.Client
+	maxAge             time.Duration
+	minRefreshInterval time.Duration
+	now                func() time.Time
+	refreshes          singleflight.Group
+
+	mu          sync.Mutex
+	keys        map[string]*ecdsa.PublicKey
+	lastRefresh time.Time
+	lastAttempt time.Time
+}
+
+func NewJWKSKeySource(url string) *JWKSKeySource {
+	return &JWKSKeySource{
+		url:                url,
+		client:             &http.Client{Timeout: 5 * time.Second},
+		maxAge:             15 * time.Minute,
+		minRefreshInterval: 30 * time.Second,
+		now:                time.Now,
+	}
+}
+
+func (source *JWKSKeySource) PublicKey(kid string) (*ecdsa.PublicKey, error) {
+	publicKey, known, refresh := source.lookup(kid)
+	if refresh {
+		source.refreshes.Do("", func() (interface{}, error) {
+			return nil, source.refresh()
+		})
+		publicKey, known, _ = source.lookup(kid)
+	}
+
+	if !known {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+
+	return publicKey, nil
+}
+
+// lookup returns the key and whether the keys should be refreshed first.
+func (source *JWKSKeySource) lookup(kid string) (*ecdsa.PublicKey, bool, bool) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	now := source.now()
+	publicKey, known := source.keys[kid]
+
+	expired := now.Sub(source.lastRefresh) >= source.maxAge
+	mayRefresh := now.Sub(source.lastAttempt) >= source.minRefreshInterval
+	return publicKey, known, mayRefresh && (expired || !known)
+}
+
+func (source *JWKSKeySource) refresh() error {
+	keys, err := source.fetch()
+
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	now := source.now()
+	source.lastAttempt = now
+	if err != nil {
+		log.Printf("could not refresh keys from %s: %s", source.url, err.Error())
+		return err
+	}
+
+	source.keys = keys
+	source.lastRefresh = now
+	return nil
+}
+
+func (source *JWKSKeySource) fetch() (map[string]*ecdsa.PublicKey, error) {
+	res, err := source.client.Get(source.url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	var keySet JSONWebKeySet
+	if err := json.NewDecoder(res.Body).Decode(&keySet); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*ecdsa.PublicKey, len(keySet.Keys))
+	for _, key := range keySet.Keys {
+		publicKey, err := key.PublicKey()
+		if err != nil {
+			log.Printf("skipping key %s: %s", key.KeyId, err.Error())
+			continue
+		}
+
+		keys[key.KeyId] = publicKey
+	}
+
+	return keys, nil
+}
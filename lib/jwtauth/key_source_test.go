@@ -0,0 +1,196 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWKSKeySource(t *testing.T) {
+	firstKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	secondKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	var published atomic.Value
+	var requests atomic.Int32
+	var failing atomic.Bool
+	var delay atomic.Int64
+	published.Store([]*ecdsa.PrivateKey{firstKey})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(time.Duration(delay.Load()))
+
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var keySet JSONWebKeySet
+		for _, key := range published.Load().([]*ecdsa.PrivateKey) {
+			keySet.Keys = append(keySet.Keys, NewJSONWebKey(&key.PublicKey))
+		}
+
+		json.NewEncoder(w).Encode(keySet)
+	}))
+	t.Cleanup(server.Close)
+
+	now := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	newTestSource := func() *JWKSKeySource {
+		requests.Store(0)
+		failing.Store(false)
+		delay.Store(0)
+		published.Store([]*ecdsa.PrivateKey{firstKey})
+
+		source := NewJWKSKeySource(server.URL)
+		source.now = func() time.Time { return now }
+		return source
+	}
+
+	t.Run("should return published key", func(t *testing.T) {
+		// given
+		source := newTestSource()
+
+		// when
+		publicKey, err := source.PublicKey(Thumbprint(&firstKey.PublicKey))
+
+		// then
+		assert.NoError(t, err)
+		assert.True(t, firstKey.PublicKey.Equal(publicKey))
+	})
+
+	t.Run("should return error if key is unknown", func(t *testing.T) {
+		// given
+		source := newTestSource()
+
+		// when
+		_, err := source.PublicKey("unknown")
+
+		// then
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("should cache keys", func(t *testing.T) {
+		// given
+		source := newTestSource()
+
+		// when
+		source.PublicKey(Thumbprint(&firstKey.PublicKey))
+		source.PublicKey(Thumbprint(&firstKey.PublicKey))
+		source.PublicKey("unknown")
+
+		// then
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("should refresh keys if unknown key is referenced", func(t *testing.T) {
+		// given
+		source := newTestSource()
+		source.PublicKey(Thumbprint(&firstKey.PublicKey))
+		published.Store([]*ecdsa.PrivateKey{firstKey, secondKey})
+
+		// when
+		now = now.Add(source.minRefreshInterval)
+		publicKey, err := source.PublicKey(Thumbprint(&secondKey.PublicKey))
+
+		// then
+		assert.NoError(t, err)
+		assert.True(t, secondKey.PublicKey.Equal(publicKey))
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("should drop removed keys after max age", func(t *testing.T) {
+		// given
+		source := newTestSource()
+		source.PublicKey(Thumbprint(&firstKey.PublicKey))
+		published.Store([]*ecdsa.PrivateKey{secondKey})
+
+		// when
+		now = now.Add(source.maxAge)
+		_, err := source.PublicKey(Thumbprint(&firstKey.PublicKey))
+
+		// then
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("should keep last keys if refresh fails", func(t *testing.T) {
+		// given
+		source := newTestSource()
+		source.PublicKey(Thumbprint(&firstKey.PublicKey))
+		failing.Store(true)
+
+		// when
+		now = now.Add(source.maxAge)
+		publicKey, err := source.PublicKey(Thumbprint(&firstKey.PublicKey))
+
+		// then
+		assert.NoError(t, err)
+		assert.True(t, firstKey.PublicKey.Equal(publicKey))
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("should not retry failed refresh before min refresh interval", func(t *testing.T) {
+		// given
+		source := newTestSource()
+		failing.Store(true)
+		source.PublicKey(Thumbprint(&firstKey.PublicKey))
+
+		// when
+		_, err := source.PublicKey(Thumbprint(&firstKey.PublicKey))
+
+		// then
+		assert.ErrorIs(t, err, ErrUnknownKey)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("should share one refresh between concurrent lookups", func(t *testing.T) {
+		// given
+		source := newTestSource()
+		delay.Store(int64(50 * time.Millisecond))
+
+		// when
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				source.PublicKey(Thumbprint(&firstKey.PublicKey))
+			}()
+		}
+		wg.Wait()
+
+		// then
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("should be usable by verifier", func(t *testing.T) {
+		// given
+		source := newTestSource()
+		verifier := NewKeySourceVerifier(source)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+			"email": "test@test.com",
+			"iss":   DefaultIssuer,
+			"aud":   DefaultAudience,
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = Thumbprint(&firstKey.PublicKey)
+		signed, _ := token.SignedString(firstKey)
+
+		// when
+		claims, err := verifier.Verify(signed)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, "test@test.com", claims["email"])
+	})
+}
@@ -0,0 +1,164 @@
+package jwtauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+)
+
+type contextKey struct{}
+
+var DefaultClaimHeaders = map[string]string{
+	"sub":   "X-User-Id",
+	"email": "X-User-Email",
+	"role":  "X-User-Role",
+}
+
+type Option func(*Middleware)
+
+// RequiredForMethods rejects requests with one of the given methods if they do
+// not carry a valid token. The method "*" matches every request.
+func RequiredForMethods(methods ...string) Option {
+	return func(middleware *Middleware) {
+		middleware.required = func(r *http.Request) bool {
+			for _, method := range methods {
+				if method == "*" || strings.EqualFold(method, r.Method) {
+					return true
+				}
+			}
+
+			return false
+		}
+	}
+}
+
+func WithClaimHeaders(claimHeaders map[string]string) Option {
+	return func(middleware *Middleware) {
+		middleware.claimHeaders = claimHeaders
+	}
+}
+
+type Middleware struct {
+	verifier     TokenVerifier
+	required     func(r *http.Request) bool
+	claimHeaders map[string]string
+}
+
+func NewMiddleware(verifier TokenVerifier, opts ...Option) *Middleware {
+	middleware := &Middleware{
+		verifier:     verifier,
+		required:     func(r *http.Request) bool { return false },
+		claimHeaders: DefaultClaimHeaders,
+	}
+
+	for _, opt := range opts {
+		opt(middleware)
+	}
+
+	return middleware
+}
+
+// Handler verifies the bearer token of the request and forwards its claims as
+// trusted headers. Incoming headers with the same names are always removed,
+// so clients cannot pretend to be someone else.
+func (middleware *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deleteHeaders(r, middleware.claimHeaders)
+
+		token, ok := BearerToken(r)
+		if !ok {
+			if middleware.required(r) {
+				w.Header().Add("WWW-Authenticate", `Bearer`)
+				problem.WriteStatus(w, r, http.StatusUnauthorized, "a bearer token is required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := middleware.verifier.Verify(token)
+		if err != nil {
+			if middleware.required(r) {
+				w.Header().Add("WWW-Authenticate", `Bearer error="invalid_token"`)
+				problem.WriteStatus(w, r, http.StatusUnauthorized, "the bearer token is invalid")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		for claim, header := range middleware.claimHeaders {
+			if value, ok := claims[claim]; ok {
+				r.Header.Set(header, headerValue(value))
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), claims)))
+	})
+}
+
+// StripClaimHeaders removes the claim headers from every request without
+// verifying tokens. Without a verifier, the headers must still never be
+// taken from clients. Empty claimHeaders strip the DefaultClaimHeaders.
+func StripClaimHeaders(claimHeaders map[string]string) func(http.Handler) http.Handler {
+	if len(claimHeaders) == 0 {
+		claimHeaders = DefaultClaimHeaders
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deleteHeaders(r, claimHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func deleteHeaders(r *http.Request, claimHeaders map[string]string) {
+	for _, header := range claimHeaders {
+		r.Header.Del(header)
+	}
+}
+
+func NewContext(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(Claims)
+	return claims, ok
+}
+
+// BearerToken returns the token of the Authorization header. The scheme is
+// matched case-insensitively.
+func BearerToken(r *http.Request) (string, bool) {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return "", false
+	}
+
+	token := strings.TrimSpace(authorization[7:])
+	return token, token != ""
+}
+
+func headerValue(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []interface{}:
+		values := make([]string, len(value))
+		for i, v := range value {
+			values[i] = headerValue(v)
+		}
+		return strings.Join(values, ",")
+	}
+
+	return fmt.Sprint(value)
+}
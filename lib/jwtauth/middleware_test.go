@@ -0,0 +1,211 @@
+package jwtauth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type verifierFunc func(token string) (Claims, error)
+
+func (f verifierFunc) Verify(token string) (Claims, error) {
+	return f(token)
+}
+
+func TestMiddleware(t *testing.T) {
+	verifier := verifierFunc(func(token string) (Claims, error) {
+		if token != "valid" {
+			return nil, ErrInvalidToken
+		}
+
+		return Claims{"sub": "42", "email": "test@test.com", "exp": float64(1700000000)}, nil
+	})
+
+	var nextReq *http.Request
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextReq = r
+	})
+
+	t.Run("should return 401 UNAUTHORIZED if token is required but missing", func(t *testing.T) {
+		// given
+		nextReq = nil
+		handler := NewMiddleware(verifier, RequiredForMethods("POST")).Handler(next)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/products", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+		assert.Nil(t, nextReq)
+	})
+
+	t.Run("should return 401 UNAUTHORIZED if token is required but invalid", func(t *testing.T) {
+		// given
+		nextReq = nil
+		handler := NewMiddleware(verifier, RequiredForMethods("*")).Handler(next)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products", nil)
+		r.Header.Set("Authorization", "Bearer invalid")
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Bearer error="invalid_token"`, w.Header().Get("WWW-Authenticate"))
+		assert.Nil(t, nextReq)
+	})
+
+	t.Run("should pass requests without token if not required", func(t *testing.T) {
+		// given
+		nextReq = nil
+		handler := NewMiddleware(verifier, RequiredForMethods("POST")).Handler(next)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products", nil)
+		r.Header.Set("Authorization", "Bearer invalid")
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotNil(t, nextReq)
+		_, ok := ClaimsFromContext(nextReq.Context())
+		assert.False(t, ok)
+	})
+
+	t.Run("should remove spoofed claim headers", func(t *testing.T) {
+		// given
+		nextReq = nil
+		handler := NewMiddleware(verifier).Handler(next)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products", nil)
+		r.Header.Set("X-User-Id", "1")
+		r.Header.Set("X-User-Email", "admin@test.com")
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Empty(t, nextReq.Header.Get("X-User-Id"))
+		assert.Empty(t, nextReq.Header.Get("X-User-Email"))
+	})
+
+	t.Run("should forward claims of valid token as headers", func(t *testing.T) {
+		// given
+		nextReq = nil
+		handler := NewMiddleware(verifier,
+			RequiredForMethods("POST"),
+			WithClaimHeaders(map[string]string{"email": "X-User-Email", "exp": "X-Token-Expires"}),
+		).Handler(next)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/products", nil)
+		r.Header.Set("Authorization", "Bearer valid")
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "test@test.com", nextReq.Header.Get("X-User-Email"))
+		assert.Equal(t, "1700000000", nextReq.Header.Get("X-Token-Expires"))
+		assert.Empty(t, nextReq.Header.Get("X-User-Id"))
+
+		claims, ok := ClaimsFromContext(nextReq.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "42", claims["sub"])
+	})
+
+	t.Run("should ignore authorization headers of other schemes", func(t *testing.T) {
+		// given
+		nextReq = nil
+		handler := NewMiddleware(verifierFunc(func(token string) (Claims, error) {
+			return nil, errors.New("must not be called")
+		}), RequiredForMethods("GET")).Handler(next)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products", nil)
+		r.Header.Set("Authorization", "Basic dGVzdDp0ZXN0")
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should strip claim headers without verifier", func(t *testing.T) {
+		// given
+		nextReq = nil
+		handler := StripClaimHeaders(map[string]string{"sub": "X-Subject"})(next)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products", nil)
+		r.Header.Set("X-Subject", "1")
+		r.Header.Set("X-Other", "kept")
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Empty(t, nextReq.Header.Get("X-Subject"))
+		assert.Equal(t, "kept", nextReq.Header.Get("X-Other"))
+	})
+
+	t.Run("should strip default claim headers without verifier", func(t *testing.T) {
+		// given
+		nextReq = nil
+		handler := StripClaimHeaders(nil)(next)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products", nil)
+		r.Header.Set("X-User-Id", "1")
+		r.Header.Set("X-User-Role", "admin")
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Empty(t, nextReq.Header.Get("X-User-Id"))
+		assert.Empty(t, nextReq.Header.Get("X-User-Role"))
+	})
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		authorization string
+		token         string
+		ok            bool
+	}{
+		{"Bearer token", "token", true},
+		{"bearer token", "token", true},
+		{"BEARER  token ", "token", true},
+		{"Bearer ", "", false},
+		{"Basic dXNlcjpwYXNz", "", false},
+		{"", "", false},
+	}
+
+	for _, test := range tests {
+		// given
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", test.authorization)
+
+		// when
+		token, ok := BearerToken(r)
+
+		// then
+		assert.Equal(t, test.token, token, test.authorization)
+		assert.Equal(t, test.ok, ok, test.authorization)
+	}
+}
@@ -0,0 +1,133 @@
+package jwtauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var ErrRevokedToken = fmt.Errorf("%w: token has been revoked", ErrInvalidToken)
+
+type RevocationList interface {
+	IsRevoked(jti string) (bool, error)
+}
+
+// RevokedTokens is the list of revoked token ids the user-service publishes
+// at /.well-known/revoked-tokens.json. Tokens leave the list once they have
+// expired on their own.
+type RevokedTokens struct {
+	Tokens []RevokedToken `json:"tokens"`
+}
+
+type RevokedToken struct {
+	Id        string `json:"jti"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// HTTPRevocationList fetches the revoked token ids from the user-service and
+// refreshes them after maxAge, so a revoked token is rejected at most maxAge
+// after its revocation. The list is fetched without holding the lock and
+// concurrent refreshes share one request.
+//
+// The list fails closed: until it has been fetched once, IsRevoked returns an
+// error, so verifiers reject tokens instead of accepting revoked ones. Once it
+// has been fetched, failed refreshes keep the last list. Failed fetches are
+// retried after retryInterval.
+type HTTPRevocationList struct {
+	url           string
+	client        *http.Client
+	maxAge        time.Duration
+	retryInterval time.Duration
+	now           func() time.Time
+	refreshes     singleflight.Group
+
+	mu          sync.Mutex
+	revoked     map[string]time.Time
+	lastRefresh time.Time
+	lastAttempt time.Time
+	lastErr     error
+}
+
+func NewHTTPRevocationList(url string) *HTTPRevocationList {
+	return &HTTPRevocationList{
+		url:           url,
+		client:        &http.Client{Timeout: 5 * time.Second},
+		maxAge:        10 * time.Second,
+		retryInterval: time.Second,
+		now:           time.Now,
+	}
+}
+
+func (list *HTTPRevocationList) IsRevoked(jti string) (bool, error) {
+	if list.needsRefresh() {
+		list.refreshes.Do("", func() (interface{}, error) {
+			return nil, list.refresh()
+		})
+	}
+
+	list.mu.Lock()
+	defer list.mu.Unlock()
+
+	if list.revoked == nil {
+		return false, fmt.Errorf("could not fetch revoked tokens: %w", list.lastErr)
+	}
+
+	expiresAt, ok := list.revoked[jti]
+	return ok && list.now().Before(expiresAt), nil
+}
+
+func (list *HTTPRevocationList) needsRefresh() bool {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+
+	now := list.now()
+	return now.Sub(list.lastRefresh) >= list.maxAge && now.Sub(list.lastAttempt) >= list.retryInterval
+}
+
+func (list *HTTPRevocationList) refresh() error {
+	revoked, err := list.fetch()
+
+	list.mu.Lock()
+	defer list.mu.Unlock()
+
+	now := list.now()
+	list.lastAttempt = now
+	list.lastErr = err
+	if err != nil {
+		log.Printf("could not refresh revoked tokens from %s: %s", list.url, err.Error())
+		return err
+	}
+
+	list.revoked = revoked
+	list.lastRefresh = now
+	return nil
+}
+
+func (list *HTTPRevocationList) fetch() (map[string]time.Time, error) {
+	res, err := list.client.Get(list.url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	var tokens RevokedTokens
+	if err := json.NewDecoder(res.Body).Decode(&tokens); err != nil {
+		return nil, err
+	}
+
+	revoked := make(map[string]time.Time, len(tokens.Tokens))
+	for _, token := range tokens.Tokens {
+		revoked[token.Id] = time.Unix(token.ExpiresAt, 0)
+	}
+
+	return revoked, nil
+}
@@ -0,0 +1,138 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPRevocationList(t *testing.T) {
+	now := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	var published atomic.Value
+	var status atomic.Int32
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if code := int(status.Load()); code != http.StatusOK {
+			w.WriteHeader(code)
+			return
+		}
+
+		json.NewEncoder(w).Encode(published.Load())
+	}))
+	t.Cleanup(server.Close)
+
+	newTestList := func(tokens ...RevokedToken) (*HTTPRevocationList, *time.Time) {
+		status.Store(http.StatusOK)
+		requests.Store(0)
+		published.Store(RevokedTokens{Tokens: tokens})
+
+		clock := now
+		list := NewHTTPRevocationList(server.URL)
+		list.now = func() time.Time { return clock }
+		return list, &clock
+	}
+
+	t.Run("should report published tokens as revoked", func(t *testing.T) {
+		// given
+		list, _ := newTestList(RevokedToken{Id: "abc", ExpiresAt: now.Add(time.Hour).Unix()})
+
+		// when
+		revoked, err := list.IsRevoked("abc")
+		other, _ := list.IsRevoked("def")
+
+		// then
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+		assert.False(t, other)
+	})
+
+	t.Run("should pick up revocations after max age", func(t *testing.T) {
+		// given
+		list, clock := newTestList()
+		list.IsRevoked("abc")
+		published.Store(RevokedTokens{Tokens: []RevokedToken{{Id: "abc", ExpiresAt: now.Add(time.Hour).Unix()}}})
+
+		// when
+		cached, _ := list.IsRevoked("abc")
+		*clock = clock.Add(list.maxAge)
+		refreshed, err := list.IsRevoked("abc")
+
+		// then
+		assert.NoError(t, err)
+		assert.False(t, cached)
+		assert.True(t, refreshed)
+	})
+
+	t.Run("should keep last list if refresh fails", func(t *testing.T) {
+		// given
+		list, clock := newTestList(RevokedToken{Id: "abc", ExpiresAt: now.Add(time.Hour).Unix()})
+		list.IsRevoked("abc")
+		status.Store(http.StatusServiceUnavailable)
+
+		// when
+		*clock = clock.Add(list.maxAge)
+		revoked, err := list.IsRevoked("abc")
+
+		// then
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("should return error until list was fetched once", func(t *testing.T) {
+		// given
+		list, clock := newTestList()
+		status.Store(http.StatusServiceUnavailable)
+
+		// when
+		_, first := list.IsRevoked("abc")
+		_, second := list.IsRevoked("abc")
+
+		// then
+		assert.Error(t, first)
+		assert.Error(t, second)
+		assert.Equal(t, int32(1), requests.Load())
+
+		// when
+		status.Store(http.StatusOK)
+		*clock = clock.Add(list.retryInterval)
+		revoked, err := list.IsRevoked("abc")
+
+		// then
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("should be usable by verifier", func(t *testing.T) {
+		// given
+		privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		list, _ := newTestList(RevokedToken{Id: "abc", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		list.now = time.Now
+		verifier := NewVerifier(&privateKey.PublicKey, WithRevocationList(list))
+
+		token := createToken(t, jwt.SigningMethodES256, privateKey, jwt.MapClaims{
+			"jti": "abc",
+			"iss": DefaultIssuer,
+			"aud": DefaultAudience,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+
+		// when
+		_, err := verifier.Verify(token)
+
+		// then
+		assert.ErrorIs(t, err, ErrRevokedToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
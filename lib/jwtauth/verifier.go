@@ -0,0 +1,185 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type Claims map[string]interface{}
+
+// String returns the claim if it is a string, otherwise an empty string.
+func (claims Claims) String(name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// Time returns a NumericDate claim like exp or iat, or the zero time if the
+// claim is missing.
+func (claims Claims) Time(name string) time.Time {
+	value, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(value), 0)
+}
+
+type TokenVerifier interface {
+	Verify(token string) (Claims, error)
+}
+
+// The issuer and audience of the tokens the user-service issues by default.
+const (
+	DefaultIssuer   = "user-service"
+	DefaultAudience = "shop"
+)
+
+type VerifierOption func(*Verifier)
+
+// WithIssuer sets the expected iss claim. An empty issuer keeps the default.
+func WithIssuer(issuer string) VerifierOption {
+	return func(verifier *Verifier) {
+		if issuer != "" {
+			verifier.issuer = issuer
+		}
+	}
+}
+
+// WithAudience sets the expected aud claim. An empty audience keeps the
+// default.
+func WithAudience(audience string) VerifierOption {
+	return func(verifier *Verifier) {
+		if audience != "" {
+			verifier.audience = audience
+		}
+	}
+}
+
+// WithRevocationList rejects tokens whose jti is on the list.
+func WithRevocationList(revocations RevocationList) VerifierOption {
+	return func(verifier *Verifier) {
+		verifier.revocations = revocations
+	}
+}
+
+type Verifier struct {
+	keys        KeySource
+	revocations RevocationList
+	issuer      string
+	audience    string
+	parser      *jwt.Parser
+}
+
+func NewVerifier(publicKey *ecdsa.PublicKey, opts ...VerifierOption) *Verifier {
+	return NewKeySourceVerifier(staticKeySource{publicKey}, opts...)
+}
+
+func NewKeySourceVerifier(keys KeySource, opts ...VerifierOption) *Verifier {
+	verifier := &Verifier{
+		keys:     keys,
+		issuer:   DefaultIssuer,
+		audience: DefaultAudience,
+		parser:   &jwt.Parser{ValidMethods: []string{jwt.SigningMethodES256.Alg()}},
+	}
+
+	for _, opt := range opts {
+		opt(verifier)
+	}
+
+	return verifier
+}
+
+// Verify checks the ES256 signature of the token, its exp, nbf and iat claims
+// as well as issuer and audience. Tokens without exp are rejected, just like
+// revoked tokens if a revocation list is set.
+func (verifier *Verifier) Verify(token string) (Claims, error) {
+	claims := jwt.MapClaims{}
+
+	parsed, err := verifier.parser.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return verifier.keys.PublicKey(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err.Error())
+	}
+
+	if !parsed.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("%w: token has no expiration", ErrInvalidToken)
+	}
+
+	if !claims.VerifyIssuer(verifier.issuer, true) {
+		return nil, fmt.Errorf("%w: unexpected issuer %v", ErrInvalidToken, claims["iss"])
+	}
+
+	if !claims.VerifyAudience(verifier.audience, true) {
+		return nil, fmt.Errorf("%w: unexpected audience %v", ErrInvalidToken, claims["aud"])
+	}
+
+	if jti, ok := claims["jti"].(string); ok && jti != "" && verifier.revocations != nil {
+		revoked, err := verifier.revocations.IsRevoked(jti)
+		if err != nil {
+			return nil, err
+		}
+
+		if revoked {
+			return nil, ErrRevokedToken
+		}
+	}
+
+	return Claims(claims), nil
+}
+
+func ReadPublicKey(path string) (*ecdsa.PublicKey, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePublicKey(bytes)
+}
+
+// ParsePublicKey accepts a PEM encoded ECDSA public key or an ECDSA private
+// key, from which the public key is derived.
+func ParsePublicKey(bytes []byte) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode(bytes)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, errors.New("public key is not an ECDSA key")
+		}
+
+		return publicKey, nil
+	case "EC PRIVATE KEY":
+		privateKey, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		return &privateKey.PublicKey, nil
+	}
+
+	return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+}
@@ -0,0 +1,213 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func createToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("could not sign token: %s", err.Error())
+	}
+
+	return token
+}
+
+func TestVerifier(t *testing.T) {
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	verifier := NewVerifier(&privateKey.PublicKey)
+
+	t.Run("Verify", func(t *testing.T) {
+		t.Run("should return error if token is malformed", func(t *testing.T) {
+			// given
+			token := "not.a.token"
+
+			// when
+			claims, err := verifier.Verify(token)
+
+			// then
+			assert.ErrorIs(t, err, ErrInvalidToken)
+			assert.Nil(t, claims)
+		})
+
+		t.Run("should return error if signature does not match", func(t *testing.T) {
+			// given
+			token := createToken(t, jwt.SigningMethodES256, otherKey, jwt.MapClaims{"email": "test@test.com"})
+
+			// when
+			_, err := verifier.Verify(token)
+
+			// then
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+
+		t.Run("should return error if algorithm is not ES256", func(t *testing.T) {
+			// given
+			token := createToken(t, jwt.SigningMethodHS256, []byte("secret"), jwt.MapClaims{"email": "test@test.com"})
+
+			// when
+			_, err := verifier.Verify(token)
+
+			// then
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+
+		t.Run("should return error if token is expired", func(t *testing.T) {
+			// given
+			token := createToken(t, jwt.SigningMethodES256, privateKey, jwt.MapClaims{
+				"exp": time.Now().Add(-time.Minute).Unix(),
+			})
+
+			// when
+			_, err := verifier.Verify(token)
+
+			// then
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+
+		t.Run("should return error if token is not valid yet", func(t *testing.T) {
+			// given
+			token := createToken(t, jwt.SigningMethodES256, privateKey, jwt.MapClaims{
+				"nbf": time.Now().Add(time.Hour).Unix(),
+			})
+
+			// when
+			_, err := verifier.Verify(token)
+
+			// then
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+
+		t.Run("should return error if token has no expiration", func(t *testing.T) {
+			// given
+			token := createToken(t, jwt.SigningMethodES256, privateKey, jwt.MapClaims{
+				"iss": DefaultIssuer,
+				"aud": DefaultAudience,
+			})
+
+			// when
+			_, err := verifier.Verify(token)
+
+			// then
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+
+		t.Run("should return error if issuer or audience do not match", func(t *testing.T) {
+			tests := []jwt.MapClaims{
+				{"aud": DefaultAudience},
+				{"iss": "someone else", "aud": DefaultAudience},
+				{"iss": DefaultIssuer},
+				{"iss": DefaultIssuer, "aud": "another app"},
+			}
+
+			for _, claims := range tests {
+				// given
+				claims["exp"] = time.Now().Add(time.Hour).Unix()
+				token := createToken(t, jwt.SigningMethodES256, privateKey, claims)
+
+				// when
+				_, err := verifier.Verify(token)
+
+				// then
+				assert.ErrorIs(t, err, ErrInvalidToken)
+			}
+		})
+
+		t.Run("should accept configured issuer and audience", func(t *testing.T) {
+			// given
+			verifier := NewVerifier(&privateKey.PublicKey, WithIssuer("auth"), WithAudience("api"))
+			token := createToken(t, jwt.SigningMethodES256, privateKey, jwt.MapClaims{
+				"iss": "auth",
+				"aud": []string{"api", "web"},
+				"exp": time.Now().Add(time.Hour).Unix(),
+			})
+
+			// when
+			_, err := verifier.Verify(token)
+
+			// then
+			assert.NoError(t, err)
+		})
+
+		t.Run("should return claims of valid token", func(t *testing.T) {
+			// given
+			token := createToken(t, jwt.SigningMethodES256, privateKey, jwt.MapClaims{
+				"email": "test@test.com",
+				"iss":   DefaultIssuer,
+				"aud":   DefaultAudience,
+				"exp":   time.Now().Add(time.Hour).Unix(),
+			})
+
+			// when
+			claims, err := verifier.Verify(token)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, "test@test.com", claims["email"])
+		})
+	})
+
+	t.Run("Claims", func(t *testing.T) {
+		t.Run("should return NumericDate claim as time", func(t *testing.T) {
+			// given
+			claims := Claims{"exp": float64(1700000000)}
+
+			// when
+			exp := claims.Time("exp")
+			iat := claims.Time("iat")
+
+			// then
+			assert.Equal(t, time.Unix(1700000000, 0), exp)
+			assert.True(t, iat.IsZero())
+		})
+	})
+
+	t.Run("ParsePublicKey", func(t *testing.T) {
+		t.Run("should return error if data is not PEM encoded", func(t *testing.T) {
+			// given
+			data := []byte("not a key")
+
+			// when
+			_, err := ParsePublicKey(data)
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should parse public key", func(t *testing.T) {
+			// given
+			der, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+			data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+			// when
+			publicKey, err := ParsePublicKey(data)
+
+			// then
+			assert.NoError(t, err)
+			assert.True(t, privateKey.PublicKey.Equal(publicKey))
+		})
+
+		t.Run("should derive public key from private key", func(t *testing.T) {
+			// given
+			der, _ := x509.MarshalECPrivateKey(privateKey)
+			data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+
+			// when
+			publicKey, err := ParsePublicKey(data)
+
+			// then
+			assert.NoError(t, err)
+			assert.True(t, privateKey.PublicKey.Equal(publicKey))
+		})
+	})
+}
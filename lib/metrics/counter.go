@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Counter is a monotonically increasing value per combination of label
+// values.
+type Counter struct {
+	vec *prometheus.CounterVec
+}
+
+// NewCounter creates a counter in the default registry.
+func NewCounter(name string, help string, labels ...string) *Counter {
+	return Default.NewCounter(name, help, labels...)
+}
+
+func (registry *Registry) NewCounter(name string, help string, labels ...string) *Counter {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+	registry.registry.MustRegister(vec)
+	return &Counter{vec}
+}
+
+func (counter *Counter) Inc(labelValues ...string) {
+	counter.vec.WithLabelValues(labelValues...).Inc()
+}
+
+func (counter *Counter) Add(value float64, labelValues ...string) {
+	counter.vec.WithLabelValues(labelValues...).Add(value)
+}
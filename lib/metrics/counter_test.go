@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(registry *Registry) string {
+	w := httptest.NewRecorder()
+	registry.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	return w.Body.String()
+}
+
+func TestCounter(t *testing.T) {
+	t.Run("should expose counter in text format", func(t *testing.T) {
+		// given
+		registry := NewRegistry()
+		counter := registry.NewCounter("logins_total", "Total number of logins.", "result")
+
+		// when
+		counter.Inc("succeeded")
+		counter.Add(2, "failed")
+		counter.Inc("succeeded")
+
+		// then
+		w := httptest.NewRecorder()
+		registry.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+		assert.Equal(t, `# HELP logins_total Total number of logins.
+# TYPE logins_total counter
+logins_total{result="failed"} 2
+logins_total{result="succeeded"} 2
+`, w.Body.String())
+	})
+
+	t.Run("should expose counter without labels", func(t *testing.T) {
+		// given
+		registry := NewRegistry()
+		counter := registry.NewCounter("registrations_total", "Total number of registrations.")
+
+		// when
+		counter.Inc()
+
+		// then
+		assert.Contains(t, scrape(registry), "\nregistrations_total 1\n")
+	})
+
+	t.Run("should escape label values and help", func(t *testing.T) {
+		// given
+		registry := NewRegistry()
+		counter := registry.NewCounter("requests_total", "Requests\nper \\ route.", "route")
+
+		// when
+		counter.Inc("/a\"b\\c\nd")
+
+		// then
+		assert.Equal(t, `# HELP requests_total Requests\nper \\ route.
+# TYPE requests_total counter
+requests_total{route="/a\"b\\c\nd"} 1
+`, scrape(registry))
+	})
+
+	t.Run("should panic if label values don't match labels", func(t *testing.T) {
+		// given
+		counter := NewRegistry().NewCounter("logins_total", "Total number of logins.", "result")
+
+		// when
+		// then
+		assert.Panics(t, func() { counter.Inc() })
+		assert.Panics(t, func() { counter.Inc("succeeded", "extra") })
+	})
+
+	t.Run("should panic if counter decreases", func(t *testing.T) {
+		// given
+		counter := NewRegistry().NewCounter("logins_total", "Total number of logins.")
+
+		// when
+		// then
+		assert.Panics(t, func() { counter.Add(-1) })
+	})
+
+	t.Run("should panic if metric is already registered", func(t *testing.T) {
+		// given
+		registry := NewRegistry()
+		registry.NewCounter("logins_total", "Total number of logins.", "result")
+
+		// when
+		// then
+		assert.Panics(t, func() { registry.NewCounter("logins_total", "Total number of logins.", "result") })
+	})
+}
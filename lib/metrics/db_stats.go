@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterDBStats reports the connection pool statistics of databases, keyed
+// by the value of their db_name label, e.g. the Stats method of a *sql.DB.
+// The metrics are named like those of collectors.NewDBStatsCollector.
+func (registry *Registry) RegisterDBStats(stats map[string]func() sql.DBStats) {
+	for name, stats := range stats {
+		name, stats := name, stats
+		labels := prometheus.Labels{"db_name": name}
+		gauge := func(name string, help string, value func(sql.DBStats) float64) prometheus.Collector {
+			return prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help, ConstLabels: labels},
+				func() float64 { return value(stats()) })
+		}
+		counter := func(name string, help string, value func(sql.DBStats) float64) prometheus.Collector {
+			return prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help, ConstLabels: labels},
+				func() float64 { return value(stats()) })
+		}
+
+		registry.registry.MustRegister(
+			gauge("go_sql_max_open_connections", "Maximum number of open connections to the database.",
+				func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }),
+			gauge("go_sql_open_connections", "The number of established connections both in use and idle.",
+				func(s sql.DBStats) float64 { return float64(s.OpenConnections) }),
+			gauge("go_sql_in_use_connections", "The number of connections currently in use.",
+				func(s sql.DBStats) float64 { return float64(s.InUse) }),
+			gauge("go_sql_idle_connections", "The number of idle connections.",
+				func(s sql.DBStats) float64 { return float64(s.Idle) }),
+			counter("go_sql_wait_count_total", "The total number of connections waited for.",
+				func(s sql.DBStats) float64 { return float64(s.WaitCount) }),
+			counter("go_sql_wait_duration_seconds_total", "The total time blocked waiting for a new connection.",
+				func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }),
+		)
+	}
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBStats(t *testing.T) {
+	t.Run("should expose pool stats of every database", func(t *testing.T) {
+		// given
+		registry := NewRegistry()
+		registry.RegisterDBStats(map[string]func() sql.DBStats{
+			"users": func() sql.DBStats {
+				return sql.DBStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2, WaitCount: 4, WaitDuration: 1500 * time.Millisecond}
+			},
+			"sessions": func() sql.DBStats {
+				return sql.DBStats{OpenConnections: 1, Idle: 1}
+			},
+		})
+
+		// when
+		metrics := scrape(registry)
+
+		// then
+		assert.Contains(t, metrics, "# TYPE go_sql_open_connections gauge\n"+
+			"go_sql_open_connections{db_name=\"sessions\"} 1\n"+
+			"go_sql_open_connections{db_name=\"users\"} 3\n")
+		assert.Contains(t, metrics, "go_sql_max_open_connections{db_name=\"users\"} 10\n")
+		assert.Contains(t, metrics, "go_sql_in_use_connections{db_name=\"users\"} 1\n")
+		assert.Contains(t, metrics, "go_sql_idle_connections{db_name=\"users\"} 2\n")
+		assert.Contains(t, metrics, "# TYPE go_sql_wait_count_total counter\n")
+		assert.Contains(t, metrics, "go_sql_wait_count_total{db_name=\"users\"} 4\n")
+		assert.Contains(t, metrics, "go_sql_wait_duration_seconds_total{db_name=\"users\"} 1.5\n")
+	})
+}
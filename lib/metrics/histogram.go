@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultBuckets fit request latencies in seconds.
+var DefaultBuckets = prometheus.DefBuckets
+
+// Histogram counts observations in cumulative buckets per combination of
+// label values.
+type Histogram struct {
+	vec *prometheus.HistogramVec
+}
+
+// NewHistogram creates a histogram in the default registry.
+func NewHistogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labels...)
+}
+
+func (registry *Registry) NewHistogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labels)
+	registry.registry.MustRegister(vec)
+	return &Histogram{vec}
+}
+
+func (histogram *Histogram) Observe(value float64, labelValues ...string) {
+	histogram.vec.WithLabelValues(labelValues...).Observe(value)
+}
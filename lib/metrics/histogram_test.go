@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	t.Run("should expose cumulative buckets, sum and count", func(t *testing.T) {
+		// given
+		registry := NewRegistry()
+		histogram := registry.NewHistogram("request_duration_seconds", "Latency.", []float64{1, 0.1}, "route")
+
+		// when
+		histogram.Observe(0.05, "/products")
+		histogram.Observe(0.1, "/products")
+		histogram.Observe(0.5, "/products")
+		histogram.Observe(3, "/products")
+
+		// then
+		assert.Equal(t, `# HELP request_duration_seconds Latency.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{route="/products",le="0.1"} 2
+request_duration_seconds_bucket{route="/products",le="1"} 3
+request_duration_seconds_bucket{route="/products",le="+Inf"} 4
+request_duration_seconds_sum{route="/products"} 3.65
+request_duration_seconds_count{route="/products"} 4
+`, scrape(registry))
+	})
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/middleware"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+)
+
+// HTTPMetrics counts requests and observes their latency. Requests are
+// labeled by route instead of path, so the number of series stays bounded.
+type HTTPMetrics struct {
+	requests *Counter
+	duration *Histogram
+}
+
+func NewHTTPMetrics(registry *Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: registry.NewCounter("http_requests_total",
+			"Total number of HTTP requests.", "method", "route", "status"),
+		duration: registry.NewHistogram("http_request_duration_seconds",
+			"Latency of HTTP requests in seconds.", DefaultBuckets, "method", "route"),
+	}
+}
+
+// Handler records requests with the pattern of the lib/router route they
+// matched. Requests without a route are recorded with an empty route.
+func (m *HTTPMetrics) Handler(next http.Handler) http.Handler {
+	return m.handler(next, router.Pattern)
+}
+
+// Route records requests with a fixed route, for handlers which are not
+// routed by lib/router.
+func (m *HTTPMetrics) Route(route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return m.handler(next, func(*http.Request) string { return route })
+	}
+}
+
+func (m *HTTPMetrics) handler(next http.Handler, route func(*http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := middleware.NewResponseWriter(w)
+
+		next.ServeHTTP(rw, r)
+
+		method, pattern := normalizeMethod(r.Method), route(r)
+		m.requests.Inc(method, pattern, strconv.Itoa(rw.StatusCode()))
+		m.duration.Observe(time.Since(start).Seconds(), method, pattern)
+	})
+}
+
+// normalizeMethod maps unknown methods to OTHER, so clients can't create new
+// series.
+func normalizeMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+
+	return "OTHER"
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	t.Run("should label requests with route pattern", func(t *testing.T) {
+		// given
+		registry := NewRegistry()
+		routes := router.New()
+		routes.Use(NewHTTPMetrics(registry).Handler)
+		routes.GET("/products/:productid", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		// when
+		routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/products/1", nil))
+		routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/products/2", nil))
+		routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/unknown", nil))
+
+		// then
+		metrics := scrape(registry)
+		assert.Contains(t, metrics, `http_requests_total{method="GET",route="/products/:productid",status="404"} 2`)
+		assert.Contains(t, metrics, `http_requests_total{method="GET",route="",status="404"} 1`)
+		assert.Contains(t, metrics, `http_request_duration_seconds_count{method="GET",route="/products/:productid"} 2`)
+		assert.NotContains(t, metrics, "/products/1")
+	})
+
+	t.Run("should label requests with fixed route", func(t *testing.T) {
+		// given
+		registry := NewRegistry()
+		handler := NewHTTPMetrics(registry).Route("/static/")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("content"))
+		}))
+
+		// when
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/static/app.js", nil))
+
+		// then
+		assert.Contains(t, scrape(registry), `http_requests_total{method="GET",route="/static/",status="200"} 1`)
+	})
+
+	t.Run("should label unknown methods as OTHER", func(t *testing.T) {
+		// given
+		registry := NewRegistry()
+		handler := NewHTTPMetrics(registry).Route("/")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		// when
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("FOO", "/", nil))
+
+		// then
+		assert.Contains(t, scrape(registry), `http_requests_total{method="OTHER",route="/",status="200"} 1`)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("should expose Go runtime and process metrics", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/metrics", nil)
+
+		// when
+		Handler().ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "\ngo_goroutines ")
+	})
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds metrics and serves them in the Prometheus exposition
+// format, using the Prometheus client library.
+type Registry struct {
+	registry *prometheus.Registry
+	handler  http.Handler
+}
+
+// Default is the registry of the package level constructors. It also holds
+// the Go runtime and process metrics.
+var Default = newDefaultRegistry()
+
+func NewRegistry() *Registry {
+	registry := prometheus.NewRegistry()
+	return &Registry{registry, promhttp.HandlerFor(registry, promhttp.HandlerOpts{})}
+}
+
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	registry.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return registry
+}
+
+// Handler serves the metrics of the default registry.
+func Handler() http.Handler {
+	return Default
+}
+
+func (registry *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	registry.handler.ServeHTTP(w, r)
+}
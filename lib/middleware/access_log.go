@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+)
+
+// AccessLog logs every request after it has been served. The route is the
+// pattern of the lib/router route the request matched and is empty for
+// requests which were not routed by it. Request and trace ids are added by
+// tracing.LogHandler.
+func AccessLog(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := NewResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+				slog.String("method", r.Method),
+				slog.String("route", router.Pattern(r)),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rw.StatusCode()),
+				slog.Int("bytes", rw.BytesWritten()),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			)
+		})
+	}
+}
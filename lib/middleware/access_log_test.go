@@ -0,0 +1,86 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/middleware"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLog(t *testing.T) {
+	decode := func(t *testing.T, logs *bytes.Buffer) map[string]any {
+		var entry map[string]any
+		assert.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+		return entry
+	}
+
+	t.Run("should log request with route pattern as JSON line", func(t *testing.T) {
+		// given
+		var logs bytes.Buffer
+		routes := router.New()
+		routes.Use(
+			tracing.Middleware(tracing.NewTracer("test", nil)),
+			middleware.AccessLog(slog.New(tracing.NewLogHandler(slog.NewJSONHandler(&logs, nil)))),
+		)
+		routes.GET("/products/:productid", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("hello"))
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/products/1", nil)
+		r.Header.Set("X-Request-ID", "abc")
+
+		// when
+		routes.ServeHTTP(w, r)
+
+		// then
+		entry := decode(t, &logs)
+		assert.Equal(t, "request", entry["msg"])
+		assert.Equal(t, "GET", entry["method"])
+		assert.Equal(t, "/products/:productid", entry["route"])
+		assert.Equal(t, "/products/1", entry["path"])
+		assert.Equal(t, float64(http.StatusCreated), entry["status"])
+		assert.Equal(t, float64(5), entry["bytes"])
+		assert.Equal(t, "abc", entry["request_id"])
+		assert.Len(t, entry["trace_id"], 32)
+		assert.Contains(t, entry, "latency_ms")
+	})
+
+	t.Run("should log 200 OK if handler does not write a status", func(t *testing.T) {
+		// given
+		var logs bytes.Buffer
+		handler := middleware.AccessLog(slog.New(slog.NewJSONHandler(&logs, nil)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		// when
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		// then
+		entry := decode(t, &logs)
+		assert.Equal(t, float64(http.StatusOK), entry["status"])
+		assert.Equal(t, "", entry["route"])
+	})
+
+	t.Run("should log status written by Recover", func(t *testing.T) {
+		// given
+		var logs bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&logs, nil))
+		handler := middleware.AccessLog(logger)(middleware.Recover(slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("something went wrong")
+		})))
+
+		// when
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		// then
+		entry := decode(t, &logs)
+		assert.Equal(t, float64(http.StatusInternalServerError), entry["status"])
+	})
+}
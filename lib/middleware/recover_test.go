@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	t.Run("should return 500 INTERNAL SERVER ERROR and log the panic", func(t *testing.T) {
+		// given
+		var logs bytes.Buffer
+		handler := Recover(slog.New(slog.NewJSONHandler(&logs, nil)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("something went wrong")
+		}))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/products/1", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, logs.String(), `"panic":"something went wrong"`)
+		assert.Contains(t, logs.String(), `"path":"/products/1"`)
+		assert.Contains(t, logs.String(), "recover_test.go")
+	})
+
+	t.Run("should keep status if response has already been started", func(t *testing.T) {
+		// given
+		handler := Recover(slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("something went wrong")
+		}))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusAccepted, w.Code)
+	})
+
+	t.Run("should not recover http.ErrAbortHandler", func(t *testing.T) {
+		// given
+		handler := Recover(slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+
+		// when
+		// then
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() { handler.ServeHTTP(w, r) })
+	})
+}
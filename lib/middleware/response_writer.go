@@ -0,0 +1,72 @@
+package middleware
+
+import "net/http"
+
+// ResponseWriter records the status code and the number of bytes written, so
+// middlewares can log, trace or count responses after they have been served.
+type ResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+// NewResponseWriter wraps w, unless it already is a ResponseWriter.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+func (w *ResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *ResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+func (w *ResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+
+		flusher.Flush()
+	}
+}
+
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Written reports whether the header has been written.
+func (w *ResponseWriter) Written() bool {
+	return w.status != 0
+}
+
+// StatusCode returns the written status code, or 200 OK if none has been
+// written yet.
+func (w *ResponseWriter) StatusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+
+	return w.status
+}
+
+// BytesWritten returns the number of body bytes written.
+func (w *ResponseWriter) BytesWritten() int {
+	return w.bytes
+}
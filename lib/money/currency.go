@@ -0,0 +1,93 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Currency is an ISO 4217 currency code.
+type Currency string
+
+const (
+	EUR Currency = "EUR"
+	USD Currency = "USD"
+	GBP Currency = "GBP"
+	CHF Currency = "CHF"
+	JPY Currency = "JPY"
+)
+
+type currencyInfo struct {
+	digits int
+	symbol string
+}
+
+var currencies = map[Currency]currencyInfo{
+	"AUD": {2, "A$"},
+	"BHD": {3, "BHD"},
+	"CAD": {2, "CA$"},
+	"CHF": {2, "CHF"},
+	"CNY": {2, "CN¥"},
+	"CZK": {2, "Kč"},
+	"DKK": {2, "kr."},
+	"EUR": {2, "€"},
+	"GBP": {2, "£"},
+	"HUF": {2, "Ft"},
+	"ISK": {0, "kr"},
+	"JOD": {3, "JOD"},
+	"JPY": {0, "¥"},
+	"KRW": {0, "₩"},
+	"KWD": {3, "KWD"},
+	"NOK": {2, "kr"},
+	"NZD": {2, "NZ$"},
+	"OMR": {3, "OMR"},
+	"PLN": {2, "zł"},
+	"SEK": {2, "kr"},
+	"TND": {3, "TND"},
+	"TRY": {2, "₺"},
+	"USD": {2, "$"},
+}
+
+// Currencies returns all known currencies, sorted by code.
+func Currencies() []Currency {
+	codes := make([]Currency, 0, len(currencies))
+	for currency := range currencies {
+		codes = append(codes, currency)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	return codes
+}
+
+// ParseCurrency returns the currency of an ISO 4217 code, ignoring case.
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(code))
+	if !currency.IsValid() {
+		return "", fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+	}
+
+	return currency, nil
+}
+
+func (currency Currency) IsValid() bool {
+	_, ok := currencies[currency]
+	return ok
+}
+
+// Digits returns the number of minor unit digits, e.g. 2 for EUR and 0 for
+// JPY.
+func (currency Currency) Digits() int {
+	return currencies[currency].digits
+}
+
+// Symbol returns the symbol of the currency, or its code if it has none.
+func (currency Currency) Symbol() string {
+	if info, ok := currencies[currency]; ok {
+		return info.symbol
+	}
+
+	return string(currency)
+}
@@ -0,0 +1,47 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCurrency(t *testing.T) {
+	t.Run("should parse known codes ignoring case", func(t *testing.T) {
+		// when
+		currency, err := ParseCurrency("eur")
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, EUR, currency)
+		assert.Equal(t, 2, currency.Digits())
+		assert.Equal(t, "€", currency.Symbol())
+	})
+
+	t.Run("should return error for unknown codes", func(t *testing.T) {
+		tests := []string{"", "EURO", "XYZ"}
+
+		for _, test := range tests {
+			// when
+			_, err := ParseCurrency(test)
+
+			// then
+			assert.ErrorIs(t, err, ErrUnknownCurrency, test)
+		}
+	})
+}
+
+func TestCurrencies(t *testing.T) {
+	t.Run("should return known currencies sorted by code", func(t *testing.T) {
+		// when
+		currencies := Currencies()
+
+		// then
+		assert.Contains(t, currencies, EUR)
+		assert.Contains(t, currencies, JPY)
+		assert.IsIncreasing(t, currencies)
+		for _, currency := range currencies {
+			assert.True(t, currency.IsValid())
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Money is an exact amount in the minor unit of its currency, like cents for
+// EUR. The zero value has no currency.
+type Money struct {
+	amount   int64
+	currency Currency
+}
+
+// New returns the amount of minor units in currency, New(999, EUR) is 9.99 €.
+func New(minor int64, currency Currency) Money {
+	return Money{minor, currency}
+}
+
+// Parse parses a decimal amount like "9.99" or "-5" exactly, which is how
+// Postgres writes numeric values. Digits beyond the minor unit of the
+// currency have to be zero.
+func Parse(amount string, currency Currency) (Money, error) {
+	if !currency.IsValid() {
+		return Money{}, fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+
+	invalid := fmt.Errorf("%w %q", ErrInvalidAmount, amount)
+
+	negative := strings.HasPrefix(amount, "-")
+	units, fraction, hasFraction := strings.Cut(strings.TrimPrefix(amount, "-"), ".")
+	if !isDigits(units) || (hasFraction && !isDigits(fraction)) {
+		return Money{}, invalid
+	}
+
+	digits := currency.Digits()
+	if len(fraction) > digits {
+		if strings.Trim(fraction[digits:], "0") != "" {
+			return Money{}, fmt.Errorf("%w %q: %s has %d decimal places", ErrInvalidAmount, amount, currency, digits)
+		}
+		fraction = fraction[:digits]
+	}
+	fraction += strings.Repeat("0", digits-len(fraction))
+
+	minor, err := strconv.ParseInt(units+fraction, 10, 64)
+	if err != nil {
+		return Money{}, invalid
+	}
+
+	if negative {
+		minor = -minor
+	}
+
+	return Money{minor, currency}, nil
+}
+
+// MustParse is like Parse but panics if the amount is invalid.
+func MustParse(amount string, currency Currency) Money {
+	m, err := Parse(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Minor returns the amount in minor units.
+func (m Money) Minor() int64 {
+	return m.amount
+}
+
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+// Decimal returns the exact amount with all minor unit digits, like "9.90".
+func (m Money) Decimal() string {
+	digits := m.currency.Digits()
+
+	s := strconv.FormatInt(m.amount, 10)
+	sign := ""
+	if m.amount < 0 {
+		sign, s = "-", s[1:]
+	}
+
+	if digits == 0 {
+		return sign + s
+	}
+
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.currency)
+}
+
+type jsonMoney struct {
+	Amount   string   `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// MarshalJSON writes the amount as a decimal string, so clients don't lose
+// precision, e.g. {"amount":"9.99","currency":"EUR"}. The zero value is
+// written as null.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m == (Money{}) {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(jsonMoney{m.Decimal(), m.currency})
+}
+
+// UnmarshalJSON reads money written by MarshalJSON. Like for structs, missing
+// members keep their current value: {"amount":"19.99"} only changes the
+// amount and a bare amount like "19.99" or 19.99 is read in the current
+// currency. If only the currency changes, the amount is kept as well.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var amount json.RawMessage
+	currency := m.currency
+
+	if len(data) > 0 && data[0] == '{' {
+		var value struct {
+			Amount   json.RawMessage `json:"amount"`
+			Currency *string         `json:"currency"`
+		}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+
+		if value.Currency != nil {
+			c, err := ParseCurrency(*value.Currency)
+			if err != nil {
+				return err
+			}
+			currency = c
+		}
+
+		amount = value.Amount
+	} else {
+		amount = data
+	}
+
+	text := m.Decimal()
+	if amount != nil {
+		var err error
+		if text, err = decodeAmount(amount); err != nil {
+			return err
+		}
+	} else if currency == m.currency {
+		return nil
+	}
+
+	if currency == "" {
+		return fmt.Errorf("%w: amount %q has no currency", ErrUnknownCurrency, text)
+	}
+
+	parsed, err := Parse(text, currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// decodeAmount returns the text of a JSON string or number without going
+// through float64.
+func decodeAmount(data json.RawMessage) (string, error) {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		err := json.Unmarshal(data, &s)
+		return s, err
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return "", fmt.Errorf("%w %s", ErrInvalidAmount, data)
+	}
+
+	return number.String(), nil
+}
+
+// Locale describes how amounts are written for display.
+type Locale struct {
+	DecimalSeparator string
+	GroupSeparator   string
+	SymbolFirst      bool
+}
+
+var (
+	German  = Locale{DecimalSeparator: ",", GroupSeparator: ".", SymbolFirst: false}
+	English = Locale{DecimalSeparator: ".", GroupSeparator: ",", SymbolFirst: true}
+)
+
+// Format writes m for display with the minor unit digits and symbol of its
+// currency, e.g. "1.234,50 €" in German or "€1,234.50" in English.
+func (m Money) Format(locale Locale) string {
+	decimal := m.Decimal()
+
+	sign := ""
+	if m.amount < 0 {
+		sign, decimal = "-", decimal[1:]
+	}
+
+	units, fraction, hasFraction := strings.Cut(decimal, ".")
+
+	var b strings.Builder
+	for i, r := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			b.WriteString(locale.GroupSeparator)
+		}
+		b.WriteRune(r)
+	}
+
+	if hasFraction {
+		b.WriteString(locale.DecimalSeparator)
+		b.WriteString(fraction)
+	}
+
+	symbol := m.currency.Symbol()
+	if !locale.SymbolFirst {
+		return sign + b.String() + " " + symbol
+	}
+
+	// Symbols ending with a letter, like CHF or kr., are separated from the
+	// amount, signs like $ are not.
+	if last, _ := utf8.DecodeLastRuneInString(symbol); unicode.IsLetter(last) || last == '.' {
+		symbol += " "
+	}
+
+	return sign + symbol + b.String()
+}
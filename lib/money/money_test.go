@@ -0,0 +1,188 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should parse decimal amounts exactly", func(t *testing.T) {
+		tests := []struct {
+			amount   string
+			currency Currency
+			minor    int64
+		}{
+			{"9.99", EUR, 999},
+			{"9.9", EUR, 990},
+			{"9", EUR, 900},
+			{"0.01", EUR, 1},
+			{"-5.50", EUR, -550},
+			{"9.9900", EUR, 999},
+			{"1000", JPY, 1000},
+			{"1.500", "KWD", 1500},
+			{"92233720368547758.07", EUR, 9223372036854775807},
+		}
+
+		for _, test := range tests {
+			// when
+			m, err := Parse(test.amount, test.currency)
+
+			// then
+			assert.NoError(t, err, test.amount)
+			assert.Equal(t, New(test.minor, test.currency), m, test.amount)
+		}
+	})
+
+	t.Run("should return error for invalid amounts", func(t *testing.T) {
+		tests := []struct {
+			amount   string
+			currency Currency
+		}{
+			{"", EUR},
+			{"-", EUR},
+			{".99", EUR},
+			{"9.", EUR},
+			{"+9", EUR},
+			{"9,99", EUR},
+			{"1e3", EUR},
+			{"9.999", EUR},
+			{"9.5", JPY},
+			{"92233720368547758.08", EUR},
+			{"9.99", "XYZ"},
+		}
+
+		for _, test := range tests {
+			// when
+			_, err := Parse(test.amount, test.currency)
+
+			// then
+			assert.Error(t, err, test.amount)
+		}
+	})
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		money    Money
+		expected string
+	}{
+		{New(999, EUR), "9.99"},
+		{New(5, EUR), "0.05"},
+		{New(-5, EUR), "-0.05"},
+		{New(-1234, EUR), "-12.34"},
+		{New(0, EUR), "0.00"},
+		{New(1000, JPY), "1000"},
+		{New(1, "KWD"), "0.001"},
+	}
+
+	for _, test := range tests {
+		// when
+		decimal := test.money.Decimal()
+
+		// then
+		assert.Equal(t, test.expected, decimal)
+		assert.Equal(t, test.money, MustParse(decimal, test.money.Currency()))
+	}
+}
+
+func TestJSON(t *testing.T) {
+	t.Run("should write amount as decimal string", func(t *testing.T) {
+		// when
+		data, err := json.Marshal(New(999, EUR))
+
+		// then
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"amount":"9.99","currency":"EUR"}`, string(data))
+	})
+
+	t.Run("should write zero value as null", func(t *testing.T) {
+		// when
+		data, err := json.Marshal(Money{})
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, "null", string(data))
+	})
+
+	t.Run("should read money and keep missing members", func(t *testing.T) {
+		tests := []struct {
+			current  Money
+			data     string
+			expected Money
+		}{
+			{Money{}, `{"amount":"9.99","currency":"EUR"}`, New(999, EUR)},
+			{Money{}, `{"amount":9.99,"currency":"eur"}`, New(999, EUR)},
+			{New(100, EUR), `{"amount":"19.99"}`, New(1999, EUR)},
+			{New(100, EUR), `"19.99"`, New(1999, EUR)},
+			{New(100, EUR), `19.99`, New(1999, EUR)},
+			{New(100, EUR), `{"currency":"USD"}`, New(100, USD)},
+			{New(100, EUR), `{"currency":"JPY"}`, New(1, JPY)},
+			{New(100, EUR), `null`, New(100, EUR)},
+		}
+
+		for _, test := range tests {
+			// given
+			m := test.current
+
+			// when
+			err := json.Unmarshal([]byte(test.data), &m)
+
+			// then
+			assert.NoError(t, err, test.data)
+			assert.Equal(t, test.expected, m, test.data)
+		}
+	})
+
+	t.Run("should return error for invalid money", func(t *testing.T) {
+		tests := []struct {
+			current Money
+			data    string
+		}{
+			{Money{}, `"9.99"`},
+			{Money{}, `{"amount":"9.99"}`},
+			{New(100, EUR), `{"amount":"9.99","currency":"XYZ"}`},
+			{New(100, EUR), `{"amount":"9.999"}`},
+			{New(100, EUR), `{"amount":true}`},
+			{New(150, EUR), `{"currency":"JPY"}`},
+			{New(100, EUR), `"cheap"`},
+		}
+
+		for _, test := range tests {
+			// given
+			m := test.current
+
+			// when
+			err := json.Unmarshal([]byte(test.data), &m)
+
+			// then
+			assert.Error(t, err, test.data)
+		}
+	})
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		money    Money
+		locale   Locale
+		expected string
+	}{
+		{New(999, EUR), German, "9,99 €"},
+		{New(123456789, EUR), German, "1.234.567,89 €"},
+		{New(-123450, EUR), German, "-1.234,50 €"},
+		{New(123450, JPY), German, "123.450 ¥"},
+		{New(999, EUR), English, "€9.99"},
+		{New(-123450, USD), English, "-$1,234.50"},
+		{New(1250, CHF), English, "CHF 12.50"},
+		{New(1250, "DKK"), English, "kr. 12.50"},
+	}
+
+	for _, test := range tests {
+		// when
+		formatted := test.money.Format(test.locale)
+
+		// then
+		assert.Equal(t, test.expected, formatted)
+	}
+}
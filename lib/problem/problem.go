@@ -0,0 +1,128 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const ContentType = "application/problem+json"
+
+// Errors wrapping these sentinels are rendered as 404 Not Found and 409
+// Conflict, e.g. fmt.Errorf("product %w", problem.ErrNotFound).
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflict")
+)
+
+// Problem is a problem details object (RFC 7807). Without a type it stands
+// for about:blank, so the title is the status text.
+type Problem struct {
+	Type     string       `json:"type,omitempty"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+func New(status int, detail string) *Problem {
+	return &Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+
+	return p.Title
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the invalid fields of a request. It is rendered as
+// 422 Unprocessable Entity with the fields as errors.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (err *ValidationError) Add(field, message string) {
+	err.Fields = append(err.Fields, FieldError{field, message})
+}
+
+// Err returns err if a field has been added and nil otherwise.
+func (err *ValidationError) Err() error {
+	if len(err.Fields) == 0 {
+		return nil
+	}
+
+	return err
+}
+
+func (err *ValidationError) Error() string {
+	fields := make([]string, len(err.Fields))
+	for i, field := range err.Fields {
+		fields[i] = fmt.Sprintf("%s %s", field.Field, field.Message)
+	}
+
+	return "invalid fields: " + strings.Join(fields, ", ")
+}
+
+// FromError returns the problem for err. Errors which are neither a Problem,
+// a ValidationError nor wrap a sentinel become 500 Internal Server Error
+// without details, so internal errors are not leaked.
+func FromError(err error) *Problem {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		return problem
+	}
+
+	var validation *ValidationError
+	if errors.As(err, &validation) {
+		problem := New(http.StatusUnprocessableEntity, "the request has invalid fields")
+		problem.Errors = validation.Fields
+		return problem
+	}
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return New(http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrConflict):
+		return New(http.StatusConflict, err.Error())
+	}
+
+	return New(http.StatusInternalServerError, "")
+}
+
+// Write writes p as application/problem+json. The instance defaults to the
+// path of the request.
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	if p.Instance == "" && r != nil {
+		instance := *p
+		instance.Instance = r.URL.Path
+		p = &instance
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// WriteStatus writes a problem with the status and detail.
+func WriteStatus(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	Write(w, r, New(status, detail))
+}
+
+// WriteError writes the problem for err, see FromError.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	Write(w, r, FromError(err))
+}
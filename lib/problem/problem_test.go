@@ -0,0 +1,120 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromError(t *testing.T) {
+	t.Run("should map errors to statuses", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+			detail string
+		}{
+			{fmt.Errorf("product %w", ErrNotFound), http.StatusNotFound, "product not found"},
+			{fmt.Errorf("%w: email is taken", ErrConflict), http.StatusConflict, "conflict: email is taken"},
+			{fmt.Errorf("wrapped: %w", New(http.StatusTeapot, "short and stout")), http.StatusTeapot, "short and stout"},
+			{errors.New("connection refused"), http.StatusInternalServerError, ""},
+		}
+
+		for _, test := range tests {
+			// when
+			problem := FromError(test.err)
+
+			// then
+			assert.Equal(t, test.status, problem.Status, test.err)
+			assert.Equal(t, http.StatusText(test.status), problem.Title, test.err)
+			assert.Equal(t, test.detail, problem.Detail, test.err)
+		}
+	})
+
+	t.Run("should list invalid fields of validation errors", func(t *testing.T) {
+		// given
+		var validation ValidationError
+		validation.Add("name", "is required")
+		validation.Add("price", "must not be negative")
+
+		// when
+		problem := FromError(fmt.Errorf("could not create product: %w", validation.Err()))
+
+		// then
+		assert.Equal(t, http.StatusUnprocessableEntity, problem.Status)
+		assert.Equal(t, []FieldError{{"name", "is required"}, {"price", "must not be negative"}}, problem.Errors)
+	})
+}
+
+func TestValidationError(t *testing.T) {
+	t.Run("should return nil without invalid fields", func(t *testing.T) {
+		// given
+		var validation ValidationError
+
+		// when
+		err := validation.Err()
+
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("should describe invalid fields", func(t *testing.T) {
+		// given
+		var validation ValidationError
+		validation.Add("name", "is required")
+		validation.Add("price", "must not be negative")
+
+		// when
+		err := validation.Err()
+
+		// then
+		assert.EqualError(t, err, "invalid fields: name is required, price must not be negative")
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("should write problem json with request path as instance", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products/1", nil)
+		problem := New(http.StatusNotFound, "product not found")
+
+		// when
+		Write(w, r, problem)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{
+			"title": "Not Found",
+			"status": 404,
+			"detail": "product not found",
+			"instance": "/api/v1/products/1"
+		}`, w.Body.String())
+		assert.Empty(t, problem.Instance)
+	})
+
+	t.Run("should write errors of validation problems", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/products", nil)
+
+		var validation ValidationError
+		validation.Add("name", "is required")
+
+		// when
+		WriteError(w, r, validation.Err())
+
+		// then
+		var problem Problem
+		err := json.NewDecoder(w.Body).Decode(&problem)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, []FieldError{{"name", "is required"}}, problem.Errors)
+	})
+}
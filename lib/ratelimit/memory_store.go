@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type memoryBucket struct {
+	bucket
+	limit Limit
+}
+
+// MemoryStore keeps the buckets of a single process. Buckets which have been
+// refilled completely are dropped from time to time.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*memoryBucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (store *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Decision, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := store.now()
+	if now.Sub(store.lastSweep) >= sweepInterval {
+		store.sweep(now)
+	}
+
+	b, ok := store.buckets[key]
+	if !ok {
+		b = &memoryBucket{bucket: bucket{tokens: limit.burst(), last: now}}
+		store.buckets[key] = b
+	}
+
+	b.limit = limit
+	return b.take(limit, now), nil
+}
+
+func (store *MemoryStore) sweep(now time.Time) {
+	for key, b := range store.buckets {
+		if b.full(b.limit, now) {
+			delete(store.buckets, key)
+		}
+	}
+
+	store.lastSweep = now
+}
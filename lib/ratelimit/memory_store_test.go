@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	limit := Limit{Requests: 1, Period: time.Second, Burst: 2}
+
+	newStore := func(now *time.Time) *MemoryStore {
+		store := NewMemoryStore()
+		store.lastSweep = *now
+		store.now = func() time.Time { return *now }
+		return store
+	}
+
+	t.Run("should allow burst and reject further requests", func(t *testing.T) {
+		// given
+		now := time.Unix(0, 0)
+		store := newStore(&now)
+
+		// when
+		first, _ := store.Take(context.Background(), "key", limit)
+		second, _ := store.Take(context.Background(), "key", limit)
+		third, _ := store.Take(context.Background(), "key", limit)
+
+		// then
+		assert.Equal(t, Decision{Allowed: true, Remaining: 1}, first)
+		assert.Equal(t, Decision{Allowed: true, Remaining: 0}, second)
+		assert.Equal(t, Decision{RetryAfter: time.Second}, third)
+	})
+
+	t.Run("should refill tokens over time", func(t *testing.T) {
+		// given
+		now := time.Unix(0, 0)
+		store := newStore(&now)
+		store.Take(context.Background(), "key", limit)
+		store.Take(context.Background(), "key", limit)
+
+		// when
+		now = now.Add(500 * time.Millisecond)
+		early, _ := store.Take(context.Background(), "key", limit)
+		now = now.Add(500 * time.Millisecond)
+		late, _ := store.Take(context.Background(), "key", limit)
+
+		// then
+		assert.Equal(t, Decision{RetryAfter: 500 * time.Millisecond}, early)
+		assert.Equal(t, Decision{Allowed: true, Remaining: 0}, late)
+	})
+
+	t.Run("should keep separate buckets per key", func(t *testing.T) {
+		// given
+		now := time.Unix(0, 0)
+		store := newStore(&now)
+		store.Take(context.Background(), "first", limit)
+		store.Take(context.Background(), "first", limit)
+
+		// when
+		decision, _ := store.Take(context.Background(), "second", limit)
+
+		// then
+		assert.True(t, decision.Allowed)
+	})
+
+	t.Run("should default burst to requests", func(t *testing.T) {
+		// given
+		now := time.Unix(0, 0)
+		store := newStore(&now)
+		limit := Limit{Requests: 3, Period: time.Minute}
+
+		// when
+		decision, _ := store.Take(context.Background(), "key", limit)
+
+		// then
+		assert.Equal(t, Decision{Allowed: true, Remaining: 2}, decision)
+	})
+
+	t.Run("should drop refilled buckets", func(t *testing.T) {
+		// given
+		now := time.Unix(0, 0)
+		store := newStore(&now)
+		store.Take(context.Background(), "idle", limit)
+		now = now.Add(sweepInterval - time.Second)
+		store.Take(context.Background(), "busy", limit)
+		store.Take(context.Background(), "busy", limit)
+
+		// when
+		now = now.Add(time.Second)
+		store.Take(context.Background(), "busy", limit)
+
+		// then
+		assert.NotContains(t, store.buckets, "idle")
+		assert.Contains(t, store.buckets, "busy")
+	})
+}
@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/metrics"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+)
+
+var storeErrors = metrics.NewCounter("ratelimit_store_errors_total",
+	"Total number of requests whose rate limit could not be checked, by limiter and whether they were allowed or rejected.",
+	"limiter", "action")
+
+// KeyFunc selects the bucket of a request. Requests with an empty key are not
+// limited.
+type KeyFunc func(r *http.Request) string
+
+// ClientIP keys requests by the address of the connected client.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// ForwardedClientIP keys requests by the last address of X-Forwarded-For,
+// which is the one added by the proxy in front of the service. It must only
+// be used behind such a proxy, because clients can send the header as well.
+func ForwardedClientIP(r *http.Request) string {
+	values := r.Header.Values("X-Forwarded-For")
+	if len(values) == 0 {
+		return ClientIP(r)
+	}
+
+	addrs := strings.Split(values[len(values)-1], ",")
+	if addr := strings.TrimSpace(addrs[len(addrs)-1]); addr != "" {
+		return "ip:" + addr
+	}
+
+	return ClientIP(r)
+}
+
+// Subject keys requests by the sub claim of their verified token and falls
+// back to the client address for anonymous requests. The jwtauth middleware
+// has to run first.
+func Subject(r *http.Request) string {
+	if claims, ok := jwtauth.ClaimsFromContext(r.Context()); ok {
+		if subject := claims.String("sub"); subject != "" {
+			return "sub:" + subject
+		}
+	}
+
+	return ClientIP(r)
+}
+
+func HeaderKey(name string) KeyFunc {
+	return func(r *http.Request) string {
+		if value := r.Header.Get(name); value != "" {
+			return "header:" + value
+		}
+
+		return ""
+	}
+}
+
+type Option func(*Middleware)
+
+func WithKey(key KeyFunc) Option {
+	return func(middleware *Middleware) {
+		middleware.key = key
+	}
+}
+
+// WithLogger sets the logger for store failures, default slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(middleware *Middleware) {
+		middleware.logger = logger
+	}
+}
+
+// WithFailOpen lets requests through if the store fails, so an unavailable
+// store does not take the service down with it. By default they are answered
+// with 503 Service Unavailable.
+func WithFailOpen(failOpen bool) Option {
+	return func(middleware *Middleware) {
+		middleware.failOpen = failOpen
+	}
+}
+
+type Middleware struct {
+	store    Store
+	name     string
+	limit    Limit
+	key      KeyFunc
+	failOpen bool
+	logger   *slog.Logger
+}
+
+// NewMiddleware limits requests to limit per key. The name separates the
+// buckets of different middlewares sharing the same store. Requests are keyed
+// by ClientIP unless another key is given.
+func NewMiddleware(store Store, name string, limit Limit, opts ...Option) (*Middleware, error) {
+	if err := limit.validate(); err != nil {
+		return nil, err
+	}
+
+	middleware := &Middleware{
+		store:  store,
+		name:   name,
+		limit:  limit,
+		key:    ClientIP,
+		logger: slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(middleware)
+	}
+
+	return middleware, nil
+}
+
+// Handler answers requests exceeding the limit with 429 Too Many Requests.
+// Store failures are logged and counted, see WithFailOpen.
+func (middleware *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := middleware.key(r)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		decision, err := middleware.store.Take(r.Context(), middleware.name+":"+key, middleware.limit)
+		if err != nil {
+			middleware.logger.ErrorContext(r.Context(), "could not take rate limit token",
+				slog.String("limiter", middleware.name),
+				slog.Bool("fail_open", middleware.failOpen),
+				slog.String("error", err.Error()))
+
+			if middleware.failOpen {
+				storeErrors.Inc(middleware.name, "allowed")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			storeErrors.Inc(middleware.name, "rejected")
+			problem.WriteStatus(w, r, http.StatusServiceUnavailable, "the rate limit could not be checked")
+			return
+		}
+
+		if !decision.Allowed {
+			retryAfter := int(math.Max(1, math.Ceil(decision.RetryAfter.Seconds())))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			problem.WriteStatus(w, r, http.StatusTooManyRequests, fmt.Sprintf("rate limit exceeded, retry after %d seconds", retryAfter))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
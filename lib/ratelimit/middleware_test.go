@@ -0,0 +1,224 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/metrics"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrapeMetrics() string {
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	return w.Body.String()
+}
+
+type storeFunc func(ctx context.Context, key string, limit Limit) (Decision, error)
+
+func (f storeFunc) Take(ctx context.Context, key string, limit Limit) (Decision, error) {
+	return f(ctx, key, limit)
+}
+
+func TestMiddleware(t *testing.T) {
+	limit := Limit{Requests: 1, Period: time.Minute}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("should reject invalid limits", func(t *testing.T) {
+		tests := []Limit{
+			{},
+			{Requests: 1},
+			{Period: time.Second},
+			{Requests: -1, Period: time.Second},
+		}
+
+		for _, test := range tests {
+			// when
+			_, err := NewMiddleware(NewMemoryStore(), "test", test)
+
+			// then
+			assert.Error(t, err, test)
+		}
+	})
+
+	t.Run("should return 429 TOO MANY REQUESTS with Retry-After if limit is exceeded", func(t *testing.T) {
+		// given
+		middleware, _ := NewMiddleware(NewMemoryStore(), "test", limit)
+		handler := middleware.Handler(next)
+
+		r := httptest.NewRequest("POST", "/login", nil)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"status":429`)
+	})
+
+	t.Run("should round Retry-After up to a full second", func(t *testing.T) {
+		// given
+		store := storeFunc(func(ctx context.Context, key string, limit Limit) (Decision, error) {
+			return Decision{RetryAfter: 10 * time.Millisecond}, nil
+		})
+		middleware, _ := NewMiddleware(store, "test", limit)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/login", nil)
+
+		// when
+		middleware.Handler(next).ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("should prefix keys with the middleware name", func(t *testing.T) {
+		// given
+		var key string
+		store := storeFunc(func(ctx context.Context, k string, limit Limit) (Decision, error) {
+			key = k
+			return Decision{Allowed: true}, nil
+		})
+		middleware, _ := NewMiddleware(store, "login", limit)
+
+		r := httptest.NewRequest("POST", "/login", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+
+		// when
+		middleware.Handler(next).ServeHTTP(httptest.NewRecorder(), r)
+
+		// then
+		assert.Equal(t, "login:ip:10.0.0.1", key)
+	})
+
+	t.Run("should not limit requests without key", func(t *testing.T) {
+		// given
+		middleware, _ := NewMiddleware(NewMemoryStore(), "test", limit, WithKey(HeaderKey("X-Api-Key")))
+		handler := middleware.Handler(next)
+
+		r := httptest.NewRequest("GET", "/", nil)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should return 503 SERVICE UNAVAILABLE if the store fails", func(t *testing.T) {
+		// given
+		store := storeFunc(func(ctx context.Context, key string, limit Limit) (Decision, error) {
+			return Decision{}, errors.New("store unavailable")
+		})
+		middleware, _ := NewMiddleware(store, "fail-closed", limit)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+
+		// when
+		middleware.Handler(next).ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, scrapeMetrics(), `ratelimit_store_errors_total{action="rejected",limiter="fail-closed"} 1`)
+	})
+
+	t.Run("should let requests through if the store fails and fail open is enabled", func(t *testing.T) {
+		// given
+		store := storeFunc(func(ctx context.Context, key string, limit Limit) (Decision, error) {
+			return Decision{}, errors.New("store unavailable")
+		})
+		middleware, _ := NewMiddleware(store, "fail-open", limit, WithFailOpen(true))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+
+		// when
+		middleware.Handler(next).ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, scrapeMetrics(), `ratelimit_store_errors_total{action="allowed",limiter="fail-open"} 1`)
+	})
+}
+
+func TestKeyFuncs(t *testing.T) {
+	t.Run("ClientIP should use the remote address", func(t *testing.T) {
+		// given
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("X-Forwarded-For", "192.168.0.1")
+
+		// when
+		key := ClientIP(r)
+
+		// then
+		assert.Equal(t, "ip:10.0.0.1", key)
+	})
+
+	t.Run("ForwardedClientIP should use the address added by the proxy", func(t *testing.T) {
+		// given
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Add("X-Forwarded-For", "1.1.1.1")
+		r.Header.Add("X-Forwarded-For", "192.168.0.1, 192.168.0.2")
+
+		// when
+		key := ForwardedClientIP(r)
+
+		// then
+		assert.Equal(t, "ip:192.168.0.2", key)
+	})
+
+	t.Run("ForwardedClientIP should fall back to the remote address", func(t *testing.T) {
+		// given
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+
+		// when
+		key := ForwardedClientIP(r)
+
+		// then
+		assert.Equal(t, "ip:10.0.0.1", key)
+	})
+
+	t.Run("Subject should use the sub claim", func(t *testing.T) {
+		// given
+		r := httptest.NewRequest("GET", "/", nil)
+		r = r.WithContext(jwtauth.NewContext(r.Context(), jwtauth.Claims{"sub": "42"}))
+
+		// when
+		key := Subject(r)
+
+		// then
+		assert.Equal(t, "sub:42", key)
+	})
+
+	t.Run("Subject should fall back to the client address", func(t *testing.T) {
+		// given
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+
+		// when
+		key := Subject(r)
+
+		// then
+		assert.Equal(t, "ip:10.0.0.1", key)
+	})
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Limit allows Burst requests at once, refilled at Requests per Period.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (limit Limit) rate() float64 {
+	return float64(limit.Requests) / float64(limit.Period)
+}
+
+func (limit Limit) burst() float64 {
+	if limit.Burst <= 0 {
+		return float64(limit.Requests)
+	}
+
+	return float64(limit.Burst)
+}
+
+func (limit Limit) validate() error {
+	if limit.Requests <= 0 || limit.Period <= 0 {
+		return fmt.Errorf("rate limit needs positive requests and period, got %d per %s", limit.Requests, limit.Period)
+	}
+
+	return nil
+}
+
+type Decision struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Store takes one token from the bucket of key.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Decision, error)
+}
+
+// NewStore creates a store from a target, which is empty for an in-memory
+// store or a redis:// url for a store shared by all replicas.
+func NewStore(target string) (Store, error) {
+	switch {
+	case target == "":
+		return NewMemoryStore(), nil
+	case strings.HasPrefix(target, "redis://"):
+		return NewRedisStore(target)
+	}
+
+	return nil, fmt.Errorf("unknown rate limit store %q", target)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) take(limit Limit, now time.Time) Decision {
+	burst := limit.burst()
+	b.tokens = math.Min(burst, b.tokens+float64(now.Sub(b.last))*limit.rate())
+	b.last = now
+
+	if b.tokens < 1 {
+		return Decision{RetryAfter: time.Duration(math.Ceil((1 - b.tokens) / limit.rate()))}
+	}
+
+	b.tokens--
+	return Decision{Allowed: true, Remaining: int(b.tokens)}
+}
+
+// full reports whether the bucket has been refilled completely, so it can
+// be dropped without changing any decision.
+func (b *bucket) full(limit Limit, now time.Time) bool {
+	return b.tokens+float64(now.Sub(b.last))*limit.rate() >= limit.burst()
+}
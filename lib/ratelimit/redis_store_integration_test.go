@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/containerhelpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationRedisStore(t *testing.T) {
+	redis, err := containerhelpers.StartRedis()
+	if err != nil {
+		t.Fatalf("could not start redis container: %s", err.Error())
+	}
+
+	t.Cleanup(func() {
+		redis.Terminate(context.Background())
+	})
+
+	port, err := redis.MappedPort(context.Background(), "6379")
+	if err != nil {
+		t.Fatalf("could not get redis container port: %s", err.Error())
+	}
+
+	store, err := NewRedisStore(fmt.Sprintf("redis://0.0.0.0:%d", port.Int()))
+	if err != nil {
+		t.Fatalf("could not create redis store: %s", err.Error())
+	}
+
+	t.Cleanup(func() {
+		store.Close()
+	})
+
+	t.Run("Take", func(t *testing.T) {
+		t.Run("should allow burst and reject further requests", func(t *testing.T) {
+			// given
+			limit := Limit{Requests: 1, Period: time.Minute, Burst: 2}
+
+			// when
+			first, err1 := store.Take(context.Background(), "burst", limit)
+			second, err2 := store.Take(context.Background(), "burst", limit)
+			third, err3 := store.Take(context.Background(), "burst", limit)
+
+			// then
+			assert.NoError(t, err1)
+			assert.NoError(t, err2)
+			assert.NoError(t, err3)
+			assert.Equal(t, Decision{Allowed: true, Remaining: 1}, first)
+			assert.Equal(t, Decision{Allowed: true, Remaining: 0}, second)
+			assert.False(t, third.Allowed)
+			assert.InDelta(t, time.Minute, third.RetryAfter, float64(time.Second))
+		})
+
+		t.Run("should refill tokens over time", func(t *testing.T) {
+			// given
+			limit := Limit{Requests: 10, Period: time.Second, Burst: 1}
+			store.Take(context.Background(), "refill", limit)
+
+			// when
+			time.Sleep(150 * time.Millisecond)
+			decision, err := store.Take(context.Background(), "refill", limit)
+
+			// then
+			assert.NoError(t, err)
+			assert.True(t, decision.Allowed)
+		})
+
+		t.Run("should share buckets between stores", func(t *testing.T) {
+			// given
+			other, _ := NewRedisStore(fmt.Sprintf("redis://0.0.0.0:%d", port.Int()))
+			defer other.Close()
+
+			limit := Limit{Requests: 1, Period: time.Minute}
+			store.Take(context.Background(), "shared", limit)
+
+			// when
+			decision, err := other.Take(context.Background(), "shared", limit)
+
+			// then
+			assert.NoError(t, err)
+			assert.False(t, decision.Allowed)
+		})
+	})
+}
@@ -0,0 +1,103 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+)
+
+// constraint restricts the values a path parameter matches, e.g.
+// ":productid<int>". Paths with other values do not match the route at all.
+type constraint struct {
+	name  string
+	match func(value string) bool
+}
+
+var constraints = map[string]*constraint{
+	"int":   {"int", isInt},
+	"uint":  {"uint", isDigits},
+	"alpha": {"alpha", isAlpha},
+	"uuid":  {"uuid", isUUID},
+}
+
+func (c *constraint) matches(value string) bool {
+	return c == nil || c.match(value)
+}
+
+// parseParam splits a parameter segment without its colon into the name and
+// the optional constraint.
+func parseParam(segment string, pattern string) (string, *constraint) {
+	name, rest, found := strings.Cut(segment, "<")
+	if !found {
+		return name, nil
+	}
+
+	kind, ok := strings.CutSuffix(rest, ">")
+	if !ok {
+		panic(fmt.Sprintf("router: unterminated constraint in pattern %q", pattern))
+	}
+
+	c, ok := constraints[kind]
+	if !ok {
+		panic(fmt.Sprintf("router: unknown constraint <%s> in pattern %q", kind, pattern))
+	}
+
+	return name, c
+}
+
+func isInt(value string) bool {
+	if value != "" && (value[0] == '-' || value[0] == '+') {
+		value = value[1:]
+	}
+
+	return isDigits(value)
+}
+
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isAlpha(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		c := value[i] | 0x20
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isUUID(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if value[i] != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", rune(value[i])) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,80 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Middleware wraps a handler. Middlewares run in the order they are given,
+// the first one being the outermost.
+type Middleware func(http.Handler) http.Handler
+
+func chain(handler http.Handler, middlewares []Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// Group registers routes below a common prefix. Requests pass the global
+// middlewares of the router first, then the middlewares of every enclosing
+// group from the outside in and finally the middlewares of the route itself.
+type Group struct {
+	router      *Router
+	prefix      string
+	middlewares []Middleware
+}
+
+func newGroup(router *Router, prefix string, middlewares []Middleware) *Group {
+	if prefix == "" || prefix[0] != '/' || strings.HasSuffix(prefix, "/") {
+		panic(fmt.Sprintf("router: group prefix %q must start and must not end with '/'", prefix))
+	}
+
+	return &Group{
+		router:      router,
+		prefix:      prefix,
+		middlewares: middlewares,
+	}
+}
+
+// Group creates a nested group, whose prefix and middlewares are appended to
+// the ones of this group.
+func (group *Group) Group(prefix string, middlewares ...Middleware) *Group {
+	return newGroup(group.router, group.prefix+prefix, group.with(middlewares))
+}
+
+func (group *Group) with(middlewares []Middleware) []Middleware {
+	all := make([]Middleware, 0, len(group.middlewares)+len(middlewares))
+	all = append(all, group.middlewares...)
+	return append(all, middlewares...)
+}
+
+func (group *Group) addRoute(method string, pattern string, handler http.Handler, middlewares []Middleware) {
+	group.router.addRoute(method, group.prefix+pattern, handler, group.with(middlewares))
+}
+
+func (group *Group) Handle(method string, pattern string, handler http.Handler, middlewares ...Middleware) {
+	group.addRoute(method, pattern, handler, middlewares)
+}
+
+func (group *Group) GET(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	group.addRoute(http.MethodGet, pattern, handler, middlewares)
+}
+
+func (group *Group) POST(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	group.addRoute(http.MethodPost, pattern, handler, middlewares)
+}
+
+func (group *Group) PUT(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	group.addRoute(http.MethodPut, pattern, handler, middlewares)
+}
+
+func (group *Group) DELETE(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	group.addRoute(http.MethodDelete, pattern, handler, middlewares)
+}
+
+func (group *Group) PATCH(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	group.addRoute(http.MethodPatch, pattern, handler, middlewares)
+}
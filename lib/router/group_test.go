@@ -0,0 +1,148 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	record := func(order *[]string, name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				*order = append(*order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	t.Run("should run global, group and route middlewares from the outside in", func(t *testing.T) {
+		// given
+		var order []string
+		router := New()
+		router.Use(record(&order, "global-1"), record(&order, "global-2"))
+
+		api := router.Group("/api", record(&order, "api"))
+		products := api.Group("/products", record(&order, "products"))
+		products.GET("/:productid", func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "handler")
+		}, record(&order, "route"))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/products/1", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, []string{"global-1", "global-2", "api", "products", "route", "handler"}, order)
+	})
+
+	t.Run("should register group routes below the prefix", func(t *testing.T) {
+		// given
+		var called []string
+		router := New()
+
+		products := router.Group("/api/v1/products")
+		products.GET("", func(w http.ResponseWriter, r *http.Request) { called = append(called, "list") })
+		products.PATCH("/:productid", func(w http.ResponseWriter, r *http.Request) {
+			called = append(called, "patch "+Params(r).Get("productid"))
+		})
+
+		// when
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/products", nil))
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PATCH", "/api/v1/products/7", nil))
+
+		// then
+		assert.Equal(t, []string{"list", "patch 7"}, called)
+	})
+
+	t.Run("should not run group middlewares for other routes", func(t *testing.T) {
+		// given
+		var order []string
+		router := New()
+		router.Group("/admin", record(&order, "admin")).GET("/users", func(w http.ResponseWriter, r *http.Request) {})
+		router.GET("/products", func(w http.ResponseWriter, r *http.Request) {})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/products", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, order)
+	})
+
+	t.Run("should let middlewares stop the request", func(t *testing.T) {
+		// given
+		called := false
+		reject := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			})
+		}
+
+		router := New()
+		router.POST("/products", func(w http.ResponseWriter, r *http.Request) { called = true }, reject)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/products", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.False(t, called)
+	})
+
+	t.Run("should run global middlewares if no route matches", func(t *testing.T) {
+		// given
+		var order []string
+		router := New()
+		router.Use(record(&order, "global"))
+		router.GET("/products", func(w http.ResponseWriter, r *http.Request) {})
+
+		notFound := httptest.NewRecorder()
+		methodNotAllowed := httptest.NewRecorder()
+		options := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(notFound, httptest.NewRequest("GET", "/unknown", nil))
+		router.ServeHTTP(methodNotAllowed, httptest.NewRequest("POST", "/products", nil))
+		router.ServeHTTP(options, httptest.NewRequest("OPTIONS", "/products", nil))
+
+		// then
+		assert.Equal(t, []string{"global", "global", "global"}, order)
+		assert.Equal(t, http.StatusNotFound, notFound.Code)
+		assert.Equal(t, http.StatusMethodNotAllowed, methodNotAllowed.Code)
+		assert.Equal(t, http.StatusNoContent, options.Code)
+	})
+
+	t.Run("should panic if Use is called after routes are registered", func(t *testing.T) {
+		// given
+		router := New()
+		router.GET("/products", func(w http.ResponseWriter, r *http.Request) {})
+
+		// when
+		// then
+		assert.Panics(t, func() { router.Use(record(new([]string), "late")) })
+	})
+
+	t.Run("should panic if group prefix is invalid", func(t *testing.T) {
+		tests := []string{"", "api", "/api/"}
+
+		for _, test := range tests {
+			// given
+			router := New()
+
+			// when
+			// then
+			assert.Panics(t, func() { router.Group(test) })
+		}
+	})
+}
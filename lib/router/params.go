@@ -0,0 +1,88 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+var (
+	ErrMissingParam = errors.New("router: missing path parameter")
+	ErrInvalidParam = errors.New("router: invalid path parameter")
+)
+
+type Param struct {
+	Key   string
+	Value string
+}
+
+// PathParams are the parameters of the matched route in pattern order.
+type PathParams []Param
+
+// Get returns the value of the parameter name or an empty string if it is
+// missing.
+func (params PathParams) Get(name string) string {
+	value, _ := params.Lookup(name)
+	return value
+}
+
+func (params PathParams) Lookup(name string) (string, bool) {
+	for _, param := range params {
+		if param.Key == name {
+			return param.Value, true
+		}
+	}
+
+	return "", false
+}
+
+type routeContextKey struct{}
+
+// routeContext describes the route a request has been matched to.
+type routeContext struct {
+	pattern string
+	params  PathParams
+}
+
+func fromContext(ctx context.Context) routeContext {
+	if route, ok := ctx.Value(routeContextKey{}).(*routeContext); ok {
+		return *route
+	}
+
+	return routeContext{}
+}
+
+// Params returns the path parameters of the request. It is empty if the
+// request was not routed by a Router or the route has no parameters.
+func Params(r *http.Request) PathParams {
+	return fromContext(r.Context()).params
+}
+
+// Pattern returns the pattern of the route the request has been matched to,
+// e.g. "/api/v1/products/:productid<int>". It is empty if no route matched.
+func Pattern(r *http.Request) string {
+	return fromContext(r.Context()).pattern
+}
+
+func ParamInt64(r *http.Request, name string) (int64, error) {
+	value, ok := Params(r).Lookup(name)
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrMissingParam, name)
+	}
+
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: %v", ErrInvalidParam, name, err)
+	}
+
+	return i, nil
+}
+
+// WithParams returns a shallow copy of r carrying the given path parameters,
+// as if it had been routed by a Router. It is meant for tests of handlers.
+func WithParams(r *http.Request, params ...Param) *http.Request {
+	route := &routeContext{pattern: Pattern(r), params: params}
+	return r.WithContext(context.WithValue(r.Context(), routeContextKey{}, route))
+}
@@ -0,0 +1,141 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParams(t *testing.T) {
+	t.Run("should return params of routed request", func(t *testing.T) {
+		// given
+		var params PathParams
+		router := New()
+		router.GET("/products/:productid/images/*path", func(w http.ResponseWriter, r *http.Request) {
+			params = Params(r)
+		})
+
+		// when
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/products/1/images/a/b.png", nil))
+
+		// then
+		assert.Equal(t, "1", params.Get("productid"))
+		assert.Equal(t, "a/b.png", params.Get("path"))
+		assert.Empty(t, params.Get("unknown"))
+	})
+
+	t.Run("should return pattern of matched route", func(t *testing.T) {
+		// given
+		var pattern string
+		router := New()
+		router.Group("/api/v1/products").GET("/:productid<int>", func(w http.ResponseWriter, r *http.Request) {
+			pattern = Pattern(r)
+		})
+
+		// when
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/products/1", nil))
+
+		// then
+		assert.Equal(t, "/api/v1/products/:productid<int>", pattern)
+	})
+
+	t.Run("should return no pattern if no route matched", func(t *testing.T) {
+		// given
+		pattern := "unset"
+		router := New()
+		router.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				pattern = Pattern(r)
+			})
+		})
+
+		// when
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/unknown", nil))
+
+		// then
+		assert.Empty(t, pattern)
+	})
+
+	t.Run("should return no params if request was not routed", func(t *testing.T) {
+		// given
+		r := httptest.NewRequest("GET", "/products/1", nil)
+
+		// when
+		params := Params(r)
+
+		// then
+		assert.Empty(t, params)
+	})
+
+	t.Run("should not collide with string context keys", func(t *testing.T) {
+		// given
+		var value any
+		router := New()
+		router.GET("/products/:productid", func(w http.ResponseWriter, r *http.Request) {
+			value = r.Context().Value("productid")
+		})
+
+		// when
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/products/1", nil))
+
+		// then
+		assert.Nil(t, value)
+	})
+
+	t.Run("should return 404 NOT FOUND if a param violates its constraint", func(t *testing.T) {
+		// given
+		called := false
+		router := New()
+		router.GET("/products/:productid<int>", func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/products/abc", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.False(t, called)
+	})
+}
+
+func TestParamInt64(t *testing.T) {
+	t.Run("should return parsed param", func(t *testing.T) {
+		// given
+		r := WithParams(httptest.NewRequest("GET", "/products/42", nil), Param{"productid", "42"})
+
+		// when
+		id, err := ParamInt64(r, "productid")
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), id)
+	})
+
+	t.Run("should return error if param is missing", func(t *testing.T) {
+		// given
+		r := httptest.NewRequest("GET", "/products", nil)
+
+		// when
+		_, err := ParamInt64(r, "productid")
+
+		// then
+		assert.ErrorIs(t, err, ErrMissingParam)
+	})
+
+	t.Run("should return error if param is no integer", func(t *testing.T) {
+		// given
+		r := WithParams(httptest.NewRequest("GET", "/products/abc", nil), Param{"productid", "abc"})
+
+		// when
+		_, err := ParamInt64(r, "productid")
+
+		// then
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+}
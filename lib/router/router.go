@@ -3,89 +3,216 @@ package router
 import (
 	"context"
 	"net/http"
-	"regexp"
+	"sort"
+	"strings"
+	"sync"
 )
 
-type route struct {
-	method  string
-	pattern *regexp.Regexp
-	handler http.HandlerFunc
-	params  []string
-}
+const maxParams = 8
 
 type Router struct {
-	routes []route
+	trees       map[string]*node
+	params      sync.Pool
+	middlewares []Middleware
+
+	// NotFound handles requests which match no route. It responds with 404
+	// by default.
+	NotFound http.Handler
+
+	// MethodNotAllowed handles requests whose path matches a route for other
+	// methods only. The Allow header is already set when it is called. It
+	// responds with 405 by default.
+	MethodNotAllowed http.Handler
 }
 
 func New() *Router {
-	return &Router{}
+	return &Router{
+		trees: make(map[string]*node),
+		params: sync.Pool{
+			New: func() any {
+				params := make([]Param, 0, maxParams)
+				return &params
+			},
+		},
+	}
 }
 
 func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	for _, route := range router.routes {
-		if r.Method != route.method {
-			continue
-		}
+	path := r.URL.Path
+	if path == "" || path[0] != '/' {
+		chain(router.fallback(nil), router.middlewares).ServeHTTP(w, r)
+		return
+	}
 
-		matches := route.pattern.FindStringSubmatch(r.URL.Path)
+	params := router.params.Get().(*[]Param)
+	defer func() {
+		*params = (*params)[:0]
+		router.params.Put(params)
+	}()
 
-		if len(matches) > 0 {
-			r = createRequestContext(r, route.params, matches[1:])
-			route.handler(w, r)
+	if route := router.lookup(r.Method, path, params); route != nil {
+		route.handler(w, createRequestContext(r, route, *params))
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		if route := router.lookup(http.MethodGet, path, params); route != nil {
+			route.handler(headResponseWriter{w}, createRequestContext(r, route, *params))
 			return
 		}
 	}
 
-	w.WriteHeader(http.StatusNotFound)
+	allowed := router.allowedMethods(path, params)
+	chain(router.fallback(allowed), router.middlewares).ServeHTTP(w, r)
 }
 
-func createRequestContext(r *http.Request, paramKeys []string, paramValues []string) *http.Request {
-	if len(paramKeys) == 0 {
-		return r
+// Use appends middlewares which wrap every request, including those without
+// a matching route. It must be called before any route is registered.
+func (router *Router) Use(middlewares ...Middleware) {
+	if len(router.trees) > 0 {
+		panic("router: Use must be called before routes are registered")
 	}
 
-	ctx := r.Context()
-	for i := 0; i < len(paramKeys); i++ {
-		ctx = context.WithValue(ctx, paramKeys[i], paramValues[i])
+	router.middlewares = append(router.middlewares, middlewares...)
+}
+
+// fallback answers requests which have no route for their method.
+func (router *Router) fallback(allowed []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(allowed) == 0 {
+			router.notFound(w, r)
+			return
+		}
+
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if router.MethodNotAllowed != nil {
+			router.MethodNotAllowed.ServeHTTP(w, r)
+			return
+		}
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+}
+
+func (router *Router) lookup(method string, path string, params *[]Param) *node {
+	tree := router.trees[method]
+	if tree == nil {
+		return nil
 	}
 
-	return r.WithContext(ctx)
+	return tree.lookup(path[1:], params)
 }
 
-func (router *Router) addRoute(method string, pattern string, handler http.HandlerFunc) {
-	paramMatcher := regexp.MustCompile(":([a-zA-Z]+)")
-	paramMatches := paramMatcher.FindAllStringSubmatch(pattern, -1)
+// allowedMethods returns the sorted methods which have a route for path. HEAD
+// is allowed along with GET and OPTIONS is always answered automatically.
+func (router *Router) allowedMethods(path string, params *[]Param) []string {
+	var allowed []string
+	for method := range router.trees {
+		if router.lookup(method, path, params) != nil {
+			allowed = append(allowed, method)
+		}
+		*params = (*params)[:0]
+	}
 
-	params := make([]string, len(paramMatches))
+	if len(allowed) == 0 {
+		return nil
+	}
 
-	if len(paramMatches) > 0 {
-		pattern = paramMatcher.ReplaceAllLiteralString(pattern, "([^/]+)")
+	if contains(allowed, http.MethodGet) && !contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+
+	if !contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
 
-		for i, match := range paramMatches {
-			params[i] = match[1]
+	sort.Strings(allowed)
+	return allowed
+}
+
+func (router *Router) notFound(w http.ResponseWriter, r *http.Request) {
+	if router.NotFound != nil {
+		router.NotFound.ServeHTTP(w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
 		}
 	}
 
-	router.routes = append(router.routes, route{
-		method:  method,
-		pattern: regexp.MustCompile("^" + pattern + "$"),
-		handler: handler,
-		params:  params,
-	})
+	return false
+}
+
+// headResponseWriter drops the body, so GET handlers can answer HEAD requests.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func createRequestContext(r *http.Request, route *node, params []Param) *http.Request {
+	ctx := &routeContext{pattern: route.pattern}
+	if len(params) > 0 {
+		ctx.params = append(PathParams(nil), params...)
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), routeContextKey{}, ctx))
+}
+
+func (router *Router) addRoute(method string, pattern string, handler http.Handler, middlewares []Middleware) {
+	tree, ok := router.trees[method]
+	if !ok {
+		tree = &node{}
+		router.trees[method] = tree
+	}
+
+	all := make([]Middleware, 0, len(router.middlewares)+len(middlewares))
+	all = append(all, router.middlewares...)
+	all = append(all, middlewares...)
+
+	tree.insert(pattern, chain(handler, all).ServeHTTP)
+}
+
+// Group creates a group of routes below prefix. The middlewares of the group
+// wrap its routes inside the global middlewares of the router.
+func (router *Router) Group(prefix string, middlewares ...Middleware) *Group {
+	return newGroup(router, prefix, middlewares)
+}
+
+func (router *Router) Handle(method string, pattern string, handler http.Handler, middlewares ...Middleware) {
+	router.addRoute(method, pattern, handler, middlewares)
+}
+
+func (router *Router) GET(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	router.addRoute(http.MethodGet, pattern, handler, middlewares)
 }
 
-func (router *Router) GET(pattern string, handler http.HandlerFunc) {
-	router.addRoute(http.MethodGet, pattern, handler)
+func (router *Router) POST(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	router.addRoute(http.MethodPost, pattern, handler, middlewares)
 }
 
-func (router *Router) POST(pattern string, handler http.HandlerFunc) {
-	router.addRoute(http.MethodPost, pattern, handler)
+func (router *Router) PUT(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	router.addRoute(http.MethodPut, pattern, handler, middlewares)
 }
 
-func (router *Router) PUT(pattern string, handler http.HandlerFunc) {
-	router.addRoute(http.MethodPut, pattern, handler)
+func (router *Router) DELETE(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	router.addRoute(http.MethodDelete, pattern, handler, middlewares)
 }
 
-func (router *Router) DELETE(pattern string, handler http.HandlerFunc) {
-	router.addRoute(http.MethodDelete, pattern, handler)
+func (router *Router) PATCH(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	router.addRoute(http.MethodPatch, pattern, handler, middlewares)
 }
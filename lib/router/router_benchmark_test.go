@@ -0,0 +1,170 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+// legacyRouter is the former implementation, which matched a regular
+// expression against every route. It is kept to compare the performance.
+type legacyRoute struct {
+	method  string
+	pattern *regexp.Regexp
+	handler http.HandlerFunc
+	params  []string
+}
+
+type legacyRouter struct {
+	routes []legacyRoute
+}
+
+func (router *legacyRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, route := range router.routes {
+		if r.Method != route.method {
+			continue
+		}
+
+		matches := route.pattern.FindStringSubmatch(r.URL.Path)
+
+		if len(matches) > 0 {
+			ctx := r.Context()
+			for i := 0; i < len(route.params); i++ {
+				ctx = context.WithValue(ctx, route.params[i], matches[i+1])
+			}
+
+			route.handler(w, r.WithContext(ctx))
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (router *legacyRouter) addRoute(method string, pattern string, handler http.HandlerFunc) {
+	paramMatcher := regexp.MustCompile(":([a-zA-Z]+)")
+	paramMatches := paramMatcher.FindAllStringSubmatch(pattern, -1)
+
+	params := make([]string, len(paramMatches))
+
+	if len(paramMatches) > 0 {
+		pattern = paramMatcher.ReplaceAllLiteralString(pattern, "([^/]+)")
+
+		for i, match := range paramMatches {
+			params[i] = match[1]
+		}
+	}
+
+	router.routes = append(router.routes, legacyRoute{
+		method:  method,
+		pattern: regexp.MustCompile("^" + pattern + "$"),
+		handler: handler,
+		params:  params,
+	})
+}
+
+var benchmarkRoutes = []struct {
+	method  string
+	pattern string
+}{
+	{"GET", "/healthz"},
+	{"GET", "/readyz"},
+	{"POST", "/api/v1/auth/register"},
+	{"POST", "/api/v1/auth/login"},
+	{"POST", "/api/v1/auth/refresh"},
+	{"POST", "/api/v1/auth/logout"},
+	{"GET", "/api/v1/users/me"},
+	{"GET", "/api/v1/users/:userid"},
+	{"GET", "/api/v1/users/:userid/orders"},
+	{"GET", "/api/v1/users/:userid/orders/:orderid"},
+	{"GET", "/api/v1/retailers/:retailerid/products"},
+	{"GET", "/api/v1/products"},
+	{"POST", "/api/v1/products"},
+	{"GET", "/api/v1/products/:productid"},
+	{"PUT", "/api/v1/products/:productid"},
+	{"DELETE", "/api/v1/products/:productid"},
+	{"GET", "/api/v1/products/:productid/reviews"},
+	{"GET", "/api/v1/products/:productid/reviews/:reviewid"},
+}
+
+func benchmarkRouter(b *testing.B, router http.Handler, method string, path string) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, path, nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		router.ServeHTTP(w, r)
+	}
+}
+
+func newBenchmarkRouters() (*Router, *legacyRouter) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	router := New()
+	legacy := &legacyRouter{}
+	for _, route := range benchmarkRoutes {
+		router.Handle(route.method, route.pattern, http.HandlerFunc(handler))
+		legacy.addRoute(route.method, route.pattern, handler)
+	}
+
+	return router, legacy
+}
+
+func BenchmarkRouter(b *testing.B) {
+	router, legacy := newBenchmarkRouters()
+
+	paths := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{"Static", "GET", "/healthz"},
+		{"StaticLast", "GET", "/api/v1/products"},
+		{"Param", "GET", "/api/v1/products/42"},
+		{"TwoParams", "GET", "/api/v1/products/42/reviews/7"},
+		{"NotFound", "GET", "/api/v1/unknown"},
+	}
+
+	for _, path := range paths {
+		b.Run("Tree/"+path.name, func(b *testing.B) {
+			benchmarkRouter(b, router, path.method, path.path)
+		})
+
+		b.Run("Legacy/"+path.name, func(b *testing.B) {
+			benchmarkRouter(b, legacy, path.method, path.path)
+		})
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	router, _ := newBenchmarkRouters()
+	tree := router.trees[http.MethodGet]
+	params := make([]Param, 0, maxParams)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		params = params[:0]
+		tree.lookup("api/v1/products/42/reviews/7", &params)
+	}
+}
+
+func TestLookupDoesNotAllocate(t *testing.T) {
+	router, _ := newBenchmarkRouters()
+	tree := router.trees[http.MethodGet]
+	params := make([]Param, 0, maxParams)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		params = params[:0]
+		tree.lookup("api/v1/products/42/reviews/7", &params)
+	})
+
+	if allocs != 0 {
+		t.Errorf("expected lookup not to allocate, but got %v allocations", allocs)
+	}
+}
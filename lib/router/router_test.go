@@ -1,7 +1,6 @@
 package router
 
 import (
-	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -43,9 +42,9 @@ func TestRouter(t *testing.T) {
 	t.Run("should route to correct handler with params", func(t *testing.T) {
 		// given
 		router := New()
-		var ctx context.Context
+		var params PathParams
 		router.GET("/the/:route/with/:params", func(w http.ResponseWriter, r *http.Request) {
-			ctx = r.Context()
+			params = Params(r)
 		})
 
 		w := httptest.NewRecorder()
@@ -56,7 +55,120 @@ func TestRouter(t *testing.T) {
 
 		// then
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Equal(t, "route", ctx.Value("route"))
-		assert.Equal(t, "params", ctx.Value("params"))
+		assert.Equal(t, PathParams{{"route", "route"}, {"params", "params"}}, params)
+	})
+	t.Run("should return 405 METHOD NOT ALLOWED with allowed methods if only the method does not match", func(t *testing.T) {
+		// given
+		router := New()
+		router.GET("/products/:productid", func(w http.ResponseWriter, r *http.Request) {})
+		router.PUT("/products/:productid", func(w http.ResponseWriter, r *http.Request) {})
+		router.DELETE("/products/:productid", func(w http.ResponseWriter, r *http.Request) {})
+		router.POST("/products", func(w http.ResponseWriter, r *http.Request) {})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/products/1", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "DELETE, GET, HEAD, OPTIONS, PUT", w.Header().Get("Allow"))
+	})
+
+	t.Run("should answer OPTIONS with allowed methods", func(t *testing.T) {
+		// given
+		router := New()
+		router.POST("/products", func(w http.ResponseWriter, r *http.Request) {})
+		router.PATCH("/products", func(w http.ResponseWriter, r *http.Request) {})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("OPTIONS", "/products", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "OPTIONS, PATCH, POST", w.Header().Get("Allow"))
+	})
+
+	t.Run("should prefer registered OPTIONS handler", func(t *testing.T) {
+		// given
+		router := New()
+		router.GET("/products", func(w http.ResponseWriter, r *http.Request) {})
+		router.Handle("OPTIONS", "/products", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("OPTIONS", "/products", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusTeapot, w.Code)
+	})
+
+	t.Run("should serve HEAD with GET handler without body", func(t *testing.T) {
+		// given
+		router := New()
+		router.GET("/products", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("HEAD", "/products", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("should register routes of any method", func(t *testing.T) {
+		// given
+		router := New()
+		router.Handle("PROPFIND", "/files/*path", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusMultiStatus)
+		}))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PROPFIND", "/files/a/b", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+	})
+
+	t.Run("should use custom NotFound and MethodNotAllowed handlers", func(t *testing.T) {
+		// given
+		router := New()
+		router.GET("/products", func(w http.ResponseWriter, r *http.Request) {})
+		router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		})
+		router.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+
+		notFound := httptest.NewRecorder()
+		methodNotAllowed := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(notFound, httptest.NewRequest("GET", "/unknown", nil))
+		router.ServeHTTP(methodNotAllowed, httptest.NewRequest("DELETE", "/products", nil))
+
+		// then
+		assert.Equal(t, http.StatusGone, notFound.Code)
+		assert.Equal(t, http.StatusTeapot, methodNotAllowed.Code)
+		assert.Equal(t, "GET, HEAD, OPTIONS", methodNotAllowed.Header().Get("Allow"))
 	})
 }
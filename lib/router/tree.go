@@ -0,0 +1,136 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// node is a path segment in the routing tree. Children are matched in a fixed
+// order: static segments first, then a :param segment, then a *wildcard,
+// which swallows the rest of the path.
+type node struct {
+	static   map[string]*node
+	param    *node
+	wildcard *node
+
+	name       string
+	constraint *constraint
+	pattern    string
+	handler    http.HandlerFunc
+}
+
+func (n *node) insert(pattern string, handler http.HandlerFunc) {
+	if pattern == "" || pattern[0] != '/' {
+		panic(fmt.Sprintf("router: pattern %q must start with '/'", pattern))
+	}
+
+	current := n
+	segments := strings.Split(pattern[1:], "/")
+
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name, constraint := parseParam(segment[1:], pattern)
+			if name == "" {
+				panic(fmt.Sprintf("router: empty parameter name in pattern %q", pattern))
+			}
+
+			if current.param == nil {
+				current.param = &node{name: name, constraint: constraint}
+			} else if current.param.name != name || current.param.constraint != constraint {
+				panic(fmt.Sprintf("router: parameter %s in pattern %q conflicts with %s", segment, pattern, current.param.describe()))
+			}
+
+			current = current.param
+		case strings.HasPrefix(segment, "*"):
+			name := segment[1:]
+			if name == "" {
+				panic(fmt.Sprintf("router: empty wildcard name in pattern %q", pattern))
+			}
+
+			if i != len(segments)-1 {
+				panic(fmt.Sprintf("router: wildcard *%s must be the last segment of pattern %q", name, pattern))
+			}
+
+			if current.wildcard == nil {
+				current.wildcard = &node{name: name}
+			} else if current.wildcard.name != name {
+				panic(fmt.Sprintf("router: wildcard *%s in pattern %q conflicts with *%s", name, pattern, current.wildcard.name))
+			}
+
+			current = current.wildcard
+		default:
+			if current.static == nil {
+				current.static = make(map[string]*node)
+			}
+
+			child, ok := current.static[segment]
+			if !ok {
+				child = &node{}
+				current.static[segment] = child
+			}
+
+			current = child
+		}
+	}
+
+	if current.handler != nil {
+		panic(fmt.Sprintf("router: pattern %q is already registered as %q", pattern, current.pattern))
+	}
+
+	current.pattern = pattern
+	current.handler = handler
+}
+
+// lookup finds the node of path, which is the request path without its
+// leading slash. Captured parameters are appended to params, which is left
+// untouched if nothing matches. It does not allocate as long as params has
+// enough capacity.
+func (n *node) lookup(path string, params *[]Param) *node {
+	segment, rest, last := path, "", true
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		segment, rest, last = path[:i], path[i+1:], false
+	}
+
+	if child, ok := n.static[segment]; ok {
+		if found := child.next(rest, last, params); found != nil {
+			return found
+		}
+	}
+
+	if n.param != nil && segment != "" && n.param.constraint.matches(segment) {
+		*params = append(*params, Param{n.param.name, segment})
+		if found := n.param.next(rest, last, params); found != nil {
+			return found
+		}
+		*params = (*params)[:len(*params)-1]
+	}
+
+	if n.wildcard != nil && n.wildcard.handler != nil {
+		*params = append(*params, Param{n.wildcard.name, path})
+		return n.wildcard
+	}
+
+	return nil
+}
+
+func (n *node) next(rest string, last bool, params *[]Param) *node {
+	if last {
+		if n.handler != nil {
+			return n
+		}
+
+		return nil
+	}
+
+	return n.lookup(rest, params)
+}
+
+func (n *node) describe() string {
+	if n.constraint == nil {
+		return ":" + n.name
+	}
+
+	return ":" + n.name + "<" + n.constraint.name + ">"
+}
@@ -0,0 +1,169 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	newTree := func(patterns ...string) *node {
+		tree := &node{}
+		for _, pattern := range patterns {
+			tree.insert(pattern, handler)
+		}
+		return tree
+	}
+
+	lookup := func(tree *node, path string) (string, []Param) {
+		params := make([]Param, 0, maxParams)
+		route := tree.lookup(path[1:], &params)
+		if route == nil {
+			return "", params
+		}
+		return route.pattern, params
+	}
+
+	t.Run("should prefer static over param over wildcard segments", func(t *testing.T) {
+		// given
+		tree := newTree(
+			"/products/*path",
+			"/products/:productid",
+			"/products/new",
+		)
+
+		tests := []struct {
+			path    string
+			pattern string
+			params  []Param
+		}{
+			{"/products/new", "/products/new", []Param{}},
+			{"/products/1", "/products/:productid", []Param{{"productid", "1"}}},
+			{"/products/1/images/2", "/products/*path", []Param{{"path", "1/images/2"}}},
+			{"/products/", "/products/*path", []Param{{"path", ""}}},
+		}
+
+		for _, test := range tests {
+			// when
+			pattern, params := lookup(tree, test.path)
+
+			// then
+			assert.Equal(t, test.pattern, pattern, test.path)
+			assert.Equal(t, test.params, params, test.path)
+		}
+	})
+
+	t.Run("should backtrack if a more specific branch does not match", func(t *testing.T) {
+		// given
+		tree := newTree(
+			"/products/new/draft",
+			"/products/:productid/images",
+		)
+
+		// when
+		pattern, params := lookup(tree, "/products/new/images")
+
+		// then
+		assert.Equal(t, "/products/:productid/images", pattern)
+		assert.Equal(t, []Param{{"productid", "new"}}, params)
+	})
+
+	t.Run("should not match if no route matches", func(t *testing.T) {
+		// given
+		tree := newTree(
+			"/products",
+			"/products/:productid",
+		)
+
+		tests := []string{"/", "/products/", "/products/1/images", "/users"}
+
+		for _, test := range tests {
+			// when
+			pattern, params := lookup(tree, test)
+
+			// then
+			assert.Empty(t, pattern, test)
+			assert.Empty(t, params, test)
+		}
+	})
+
+	t.Run("should only match params which satisfy their constraint", func(t *testing.T) {
+		// given
+		tree := newTree(
+			"/products/:productid<int>",
+			"/categories/:name<alpha>",
+			"/orders/:orderid<uuid>",
+			"/pages/:page<uint>",
+		)
+
+		tests := []struct {
+			path    string
+			pattern string
+		}{
+			{"/products/42", "/products/:productid<int>"},
+			{"/products/-1", "/products/:productid<int>"},
+			{"/products/abc", ""},
+			{"/products/1a", ""},
+			{"/categories/Shoes", "/categories/:name<alpha>"},
+			{"/categories/shoes2", ""},
+			{"/orders/6ba7b810-9dad-11d1-80b4-00c04fd430c8", "/orders/:orderid<uuid>"},
+			{"/orders/6ba7b810", ""},
+			{"/pages/3", "/pages/:page<uint>"},
+			{"/pages/-3", ""},
+		}
+
+		for _, test := range tests {
+			// when
+			pattern, _ := lookup(tree, test.path)
+
+			// then
+			assert.Equal(t, test.pattern, pattern, test.path)
+		}
+	})
+
+	t.Run("should store params without constraint", func(t *testing.T) {
+		// given
+		tree := newTree("/products/:productid<int>")
+
+		// when
+		_, params := lookup(tree, "/products/42")
+
+		// then
+		assert.Equal(t, []Param{{"productid", "42"}}, params)
+	})
+
+	t.Run("should match root", func(t *testing.T) {
+		// given
+		tree := newTree("/")
+
+		// when
+		pattern, _ := lookup(tree, "/")
+
+		// then
+		assert.Equal(t, "/", pattern)
+	})
+
+	t.Run("should panic on invalid or conflicting patterns", func(t *testing.T) {
+		tests := [][]string{
+			{"products"},
+			{"/products/:"},
+			{"/products/*"},
+			{"/products/*path/images"},
+			{"/products/:productid", "/products/:id/images"},
+			{"/products/:productid", "/products/:productid"},
+			{"/products/:productid<int"},
+			{"/products/:productid<float>"},
+			{"/products/:<int>"},
+			{"/products/:productid<int>", "/products/:productid/images"},
+		}
+
+		for _, test := range tests {
+			// when
+			// then
+			assert.Panics(t, func() { newTree(test...) }, test)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config holds the timeouts of all listeners of a runner. Zero timeouts
+// disable the timeout, so configs should start from DefaultConfig.
+//
+// ShutdownDelay is how long /readyz fails before draining starts. It gives
+// load balancers time to stop routing to the instance, so it should be longer
+// than their health check interval.
+type Config struct {
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	ShutdownDelay     time.Duration `yaml:"shutdownDelay"`
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ShutdownDelay:     5 * time.Second,
+		ShutdownTimeout:   20 * time.Second,
+	}
+}
+
+// ConfigFromEnv overrides the defaults with the environment variables
+// SERVER_READ_HEADER_TIMEOUT, SERVER_READ_TIMEOUT, SERVER_WRITE_TIMEOUT,
+// SERVER_IDLE_TIMEOUT, SERVER_SHUTDOWN_DELAY and SERVER_SHUTDOWN_TIMEOUT.
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig()
+
+	vars := []struct {
+		key   string
+		value *time.Duration
+	}{
+		{"SERVER_READ_HEADER_TIMEOUT", &config.ReadHeaderTimeout},
+		{"SERVER_READ_TIMEOUT", &config.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", &config.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", &config.IdleTimeout},
+		{"SERVER_SHUTDOWN_DELAY", &config.ShutdownDelay},
+		{"SERVER_SHUTDOWN_TIMEOUT", &config.ShutdownTimeout},
+	}
+
+	for _, v := range vars {
+		value, ok := os.LookupEnv(v.key)
+		if !ok || value == "" {
+			continue
+		}
+
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s: %w", v.key, err)
+		}
+		*v.value = duration
+	}
+
+	return config, nil
+}
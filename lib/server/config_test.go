@@ -0,0 +1,38 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Run("should override defaults with environment variables", func(t *testing.T) {
+		// given
+		t.Setenv("SERVER_WRITE_TIMEOUT", "1m")
+		t.Setenv("SERVER_SHUTDOWN_DELAY", "0s")
+
+		expected := DefaultConfig()
+		expected.WriteTimeout = time.Minute
+		expected.ShutdownDelay = 0
+
+		// when
+		config, err := ConfigFromEnv()
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, expected, config)
+	})
+
+	t.Run("should return error for invalid durations", func(t *testing.T) {
+		// given
+		t.Setenv("SERVER_READ_TIMEOUT", "10")
+
+		// when
+		_, err := ConfigFromEnv()
+
+		// then
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Readiness is told when the runner starts to shut down, so load balancers
+// stop sending new requests.
+type Readiness interface {
+	SetReady(ready bool)
+}
+
+type listener struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// Runner serves one or more listeners until SIGINT or SIGTERM and then shuts
+// them down gracefully.
+type Runner struct {
+	config    Config
+	listeners []listener
+	readiness []Readiness
+	closers   []func(ctx context.Context) error
+}
+
+func NewRunner(config Config) *Runner {
+	return &Runner{config: config}
+}
+
+// Listen binds addr right away, so a port which is already in use is reported
+// before anything is served.
+func (runner *Runner) Listen(addr string, handler http.Handler) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	runner.listeners = append(runner.listeners, listener{
+		server: &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: runner.config.ReadHeaderTimeout,
+			ReadTimeout:       runner.config.ReadTimeout,
+			WriteTimeout:      runner.config.WriteTimeout,
+			IdleTimeout:       runner.config.IdleTimeout,
+		},
+		listener: l,
+	})
+
+	return nil
+}
+
+func (runner *Runner) AddReadiness(readiness Readiness) {
+	runner.readiness = append(runner.readiness, readiness)
+}
+
+// OnShutdown registers a function which runs after all connections have been
+// drained, like closing a database. Functions run in reverse order of their
+// registration.
+func (runner *Runner) OnShutdown(fn func(ctx context.Context) error) {
+	runner.closers = append(runner.closers, fn)
+}
+
+// Close adapts closer to OnShutdown.
+func Close(closer io.Closer) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return closer.Close()
+	}
+}
+
+// Run serves all listeners until ctx is done, a signal arrives or a listener
+// fails, and then shuts down.
+func (runner *Runner) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, len(runner.listeners))
+	for _, l := range runner.listeners {
+		go func(l listener) {
+			if err := l.server.Serve(l.listener); !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}(l)
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		log.Printf("shutting down")
+	case err = <-errs:
+		log.Printf("shutting down after listener failed: %s", err.Error())
+	}
+	stop()
+
+	return errors.Join(err, runner.shutdown())
+}
+
+func (runner *Runner) shutdown() error {
+	for _, readiness := range runner.readiness {
+		readiness.SetReady(false)
+	}
+
+	time.Sleep(runner.config.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), runner.config.ShutdownTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var errs []error
+
+	var wg sync.WaitGroup
+	for _, l := range runner.listeners {
+		wg.Add(1)
+		go func(server *http.Server) {
+			defer wg.Done()
+
+			if err := server.Shutdown(ctx); err != nil {
+				server.Close()
+
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(l.server)
+	}
+	wg.Wait()
+
+	for i := len(runner.closers) - 1; i >= 0; i-- {
+		if err := runner.closers[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
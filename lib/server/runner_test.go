@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type readinessFunc func(ready bool)
+
+func (f readinessFunc) SetReady(ready bool) {
+	f(ready)
+}
+
+func TestRunner(t *testing.T) {
+	t.Run("should return error if address is in use", func(t *testing.T) {
+		// given
+		runner := NewRunner(DefaultConfig())
+		runner.Listen("127.0.0.1:0", http.NotFoundHandler())
+		addr := runner.listeners[0].listener.Addr().String()
+		t.Cleanup(func() { runner.listeners[0].listener.Close() })
+
+		// when
+		err := NewRunner(DefaultConfig()).Listen(addr, http.NotFoundHandler())
+
+		// then
+		assert.Error(t, err)
+	})
+
+	t.Run("should apply timeouts", func(t *testing.T) {
+		// given
+		config := Config{ReadHeaderTimeout: 1, ReadTimeout: 2, WriteTimeout: 3, IdleTimeout: 4}
+		runner := NewRunner(config)
+
+		// when
+		runner.Listen("127.0.0.1:0", http.NotFoundHandler())
+		t.Cleanup(func() { runner.listeners[0].listener.Close() })
+
+		// then
+		server := runner.listeners[0].server
+		assert.Equal(t, time.Duration(1), server.ReadHeaderTimeout)
+		assert.Equal(t, time.Duration(2), server.ReadTimeout)
+		assert.Equal(t, time.Duration(3), server.WriteTimeout)
+		assert.Equal(t, time.Duration(4), server.IdleTimeout)
+	})
+
+	t.Run("should drain in-flight requests, flip readiness and run closers in reverse order", func(t *testing.T) {
+		// given
+		var mu sync.Mutex
+		var events []string
+		record := func(event string) {
+			mu.Lock()
+			events = append(events, event)
+			mu.Unlock()
+		}
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			record("request handled")
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		runner := NewRunner(Config{ShutdownTimeout: 5 * time.Second})
+		runner.Listen("127.0.0.1:0", handler)
+		addr := runner.listeners[0].listener.Addr().String()
+
+		runner.AddReadiness(readinessFunc(func(ready bool) {
+			if !ready {
+				record("not ready")
+			}
+		}))
+		runner.OnShutdown(func(ctx context.Context) error {
+			record("close database")
+			return nil
+		})
+		runner.OnShutdown(func(ctx context.Context) error {
+			record("flush spans")
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- runner.Run(ctx) }()
+
+		responses := make(chan int)
+		go func() {
+			res, err := http.Get("http://" + addr)
+			if err != nil {
+				responses <- 0
+				return
+			}
+			res.Body.Close()
+			responses <- res.StatusCode
+		}()
+		<-started
+
+		// when
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+
+		// then
+		assert.Equal(t, http.StatusAccepted, <-responses)
+		assert.NoError(t, <-done)
+		assert.Equal(t, []string{"not ready", "request handled", "flush spans", "close database"}, events)
+	})
+
+	t.Run("should return errors of closers", func(t *testing.T) {
+		// given
+		config := DefaultConfig()
+		config.ShutdownDelay = 0
+		runner := NewRunner(config)
+		runner.Listen("127.0.0.1:0", http.NotFoundHandler())
+		runner.OnShutdown(func(ctx context.Context) error {
+			return errors.New("close failed")
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// when
+		err := runner.Run(ctx)
+
+		// then
+		assert.EqualError(t, err, "close failed")
+	})
+
+	t.Run("should give up draining after the shutdown timeout", func(t *testing.T) {
+		// given
+		started := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-r.Context().Done()
+		})
+
+		runner := NewRunner(Config{ShutdownTimeout: 50 * time.Millisecond})
+		runner.Listen("127.0.0.1:0", handler)
+		addr := runner.listeners[0].listener.Addr().String()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- runner.Run(ctx) }()
+
+		go http.Get("http://" + addr)
+		<-started
+
+		// when
+		cancel()
+
+		// then
+		assert.ErrorIs(t, <-done, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// defaultRetry retries failed exports with exponential backoff, so spans
+// survive short outages of the collector.
+var defaultRetry = otlptracehttp.RetryConfig{
+	Enabled:         true,
+	InitialInterval: 5 * time.Second,
+	MaxInterval:     30 * time.Second,
+	MaxElapsedTime:  time.Minute,
+}
+
+// NewExporter creates the exporter for target, which is either "stdout", a
+// file path prefixed with "file:" or the http(s) endpoint of an OTLP
+// collector. An empty target disables the export.
+func NewExporter(target string) (sdktrace.SpanExporter, error) {
+	switch {
+	case target == "":
+		return nil, nil
+	case target == "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case strings.HasPrefix(target, "file:"):
+		file, err := os.OpenFile(strings.TrimPrefix(target, "file:"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		return stdouttrace.New(stdouttrace.WithWriter(file))
+	case strings.HasPrefix(target, "http://"), strings.HasPrefix(target, "https://"):
+		return newOTLPExporter(target, defaultRetry)
+	}
+
+	return nil, fmt.Errorf("unknown trace exporter %q", target)
+}
+
+// newOTLPExporter creates an OTLP/HTTP exporter for the collector at
+// endpoint, e.g. http://otel-collector:4318. Spans are posted to its
+// /v1/traces path.
+func newOTLPExporter(endpoint string, retry otlptracehttp.RetryConfig) (*otlptrace.Exporter, error) {
+	return otlptracehttp.New(
+		context.Background(),
+		otlptracehttp.WithEndpointURL(strings.TrimSuffix(endpoint, "/")+"/v1/traces"),
+		otlptracehttp.WithRetry(retry),
+	)
+}
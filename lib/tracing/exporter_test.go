@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestExporter(t *testing.T) {
+	testRetry := otlptracehttp.RetryConfig{
+		Enabled:         true,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		MaxElapsedTime:  time.Second,
+	}
+
+	testSpans := func() []sdktrace.ReadOnlySpan {
+		return tracetest.SpanStubs{{
+			Name:     "GET /api/v1/products",
+			SpanKind: trace.SpanKindServer,
+		}}.Snapshots()
+	}
+
+	t.Run("should write spans as JSON lines to file", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "spans.jsonl")
+		exporter, err := NewExporter("file:" + path)
+		assert.NoError(t, err)
+
+		tracer := NewTracer("product-service", exporter)
+		_, span := tracer.Start(context.Background(), "GET /api/v1/products", trace.SpanKindServer)
+		span.End()
+
+		// when
+		err = tracer.Shutdown(context.Background())
+
+		// then
+		assert.NoError(t, err)
+
+		b, _ := os.ReadFile(path)
+		var entry map[string]any
+		assert.NoError(t, json.Unmarshal(b, &entry))
+		assert.Equal(t, "GET /api/v1/products", entry["Name"])
+	})
+
+	t.Run("should post spans to the OTLP collector", func(t *testing.T) {
+		// given
+		var path, contentType string
+		var body []byte
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			contentType = r.Header.Get("Content-Type")
+			body, _ = io.ReadAll(r.Body)
+		}))
+		t.Cleanup(collector.Close)
+
+		exporter, _ := newOTLPExporter(collector.URL+"/", testRetry)
+		tracer := NewTracer("product-service", exporter)
+		_, span := tracer.Start(context.Background(), "GET /api/v1/products", trace.SpanKindServer)
+		span.End()
+
+		// when
+		err := tracer.Shutdown(context.Background())
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, "/v1/traces", path)
+		assert.Equal(t, "application/x-protobuf", contentType)
+
+		var request collectortrace.ExportTraceServiceRequest
+		assert.NoError(t, proto.Unmarshal(body, &request))
+
+		resourceSpans := request.GetResourceSpans()
+		assert.Len(t, resourceSpans, 1)
+		assert.Equal(t, "service.name", resourceSpans[0].GetResource().GetAttributes()[0].GetKey())
+		assert.Equal(t, "product-service", resourceSpans[0].GetResource().GetAttributes()[0].GetValue().GetStringValue())
+		assert.Equal(t, "GET /api/v1/products", resourceSpans[0].GetScopeSpans()[0].GetSpans()[0].GetName())
+	})
+
+	t.Run("should retry export if collector is unavailable", func(t *testing.T) {
+		// given
+		var mu sync.Mutex
+		requests := 0
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			requests++
+			if requests == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		t.Cleanup(collector.Close)
+
+		exporter, _ := newOTLPExporter(collector.URL, testRetry)
+
+		// when
+		err := exporter.ExportSpans(context.Background(), testSpans())
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("should return error if collector rejects spans", func(t *testing.T) {
+		// given
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		t.Cleanup(collector.Close)
+
+		exporter, _ := newOTLPExporter(collector.URL, testRetry)
+
+		// when
+		err := exporter.ExportSpans(context.Background(), testSpans())
+
+		// then
+		assert.Error(t, err)
+	})
+
+	t.Run("should create exporter for target", func(t *testing.T) {
+		tests := []struct {
+			target   string
+			exporter sdktrace.SpanExporter
+		}{
+			{"", nil},
+			{"stdout", &stdouttrace.Exporter{}},
+			{"http://otel-collector:4318", &otlptrace.Exporter{}},
+		}
+
+		for _, test := range tests {
+			// when
+			exporter, err := NewExporter(test.target)
+
+			// then
+			assert.NoError(t, err)
+			assert.IsType(t, test.exporter, exporter, test.target)
+		}
+	})
+
+	t.Run("should return error if target is unknown", func(t *testing.T) {
+		// when
+		_, err := NewExporter("kafka://broker")
+
+		// then
+		assert.Error(t, err)
+	})
+}
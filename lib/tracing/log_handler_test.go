@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLogHandler(t *testing.T) {
+	t.Run("should add request id and trace context to records", func(t *testing.T) {
+		// given
+		var logs bytes.Buffer
+		logger := slog.New(NewLogHandler(slog.NewJSONHandler(&logs, nil))).With("service", "test")
+
+		ctx, span := NewTracer("test", nil).Start(WithRequestId(context.Background(), "abc"), "operation", trace.SpanKindInternal)
+
+		// when
+		logger.InfoContext(ctx, "hello")
+
+		// then
+		var entry map[string]any
+		assert.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+		assert.Equal(t, "test", entry["service"])
+		assert.Equal(t, "abc", entry["request_id"])
+		assert.Equal(t, span.SpanContext().TraceID().String(), entry["trace_id"])
+		assert.Equal(t, span.SpanContext().SpanID().String(), entry["span_id"])
+	})
+
+	t.Run("should not add anything without context", func(t *testing.T) {
+		// given
+		var logs bytes.Buffer
+		logger := slog.New(NewLogHandler(slog.NewJSONHandler(&logs, nil)))
+
+		// when
+		logger.Info("hello")
+
+		// then
+		var entry map[string]any
+		assert.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+		assert.NotContains(t, entry, "request_id")
+		assert.NotContains(t, entry, "trace_id")
+	})
+}
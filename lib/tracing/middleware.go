@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/middleware"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware accepts the X-Request-ID and traceparent headers of incoming
+// requests or creates new ones, stores them in the request context and traces
+// the request as server span. The request id is echoed in the response.
+func Middleware(tracer *Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestId := r.Header.Get(RequestIdHeader)
+			if !isValidRequestId(requestId) {
+				requestId = newRequestId()
+			}
+			w.Header().Set(RequestIdHeader, requestId)
+
+			ctx := propagator.Extract(WithRequestId(r.Context(), requestId), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, r.Method, trace.SpanKindServer)
+			defer span.End()
+
+			rw := middleware.NewResponseWriter(w)
+			r = r.WithContext(ctx)
+			next.ServeHTTP(rw, r)
+
+			if pattern := router.Pattern(r); pattern != "" {
+				span.SetName(r.Method + " " + pattern)
+				span.SetAttributes(attribute.String("http.route", pattern))
+			}
+
+			span.SetAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.Int("http.response.status_code", rw.StatusCode()),
+				attribute.String("request.id", requestId),
+			)
+
+			if rw.StatusCode() >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, strconv.Itoa(rw.StatusCode())+" "+http.StatusText(rw.StatusCode()))
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newRecordingTracer returns a tracer and a function returning the spans it
+// has exported so far.
+func newRecordingTracer() (*Tracer, func() tracetest.SpanStubs) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := NewTracer("test", exporter)
+
+	return tracer, func() tracetest.SpanStubs {
+		tracer.provider.ForceFlush(context.Background())
+		return exporter.GetSpans()
+	}
+}
+
+func attributeValue(attributes []attribute.KeyValue, key string) attribute.Value {
+	for _, kv := range attributes {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestMiddleware(t *testing.T) {
+	newTestRouter := func(tracer *Tracer, handler http.HandlerFunc) *router.Router {
+		routes := router.New()
+		routes.Use(Middleware(tracer))
+		routes.GET("/products/:productid", handler)
+		return routes
+	}
+
+	t.Run("should generate request id and trace if headers are missing", func(t *testing.T) {
+		// given
+		var ctx context.Context
+		routes := newTestRouter(NewTracer("test", nil), func(w http.ResponseWriter, r *http.Request) {
+			ctx = r.Context()
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/products/1", nil)
+
+		// when
+		routes.ServeHTTP(w, r)
+
+		// then
+		assert.Len(t, RequestIdFromContext(ctx), 32)
+		assert.Equal(t, RequestIdFromContext(ctx), w.Header().Get(RequestIdHeader))
+		assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+	})
+
+	t.Run("should accept request id and continue trace of incoming request", func(t *testing.T) {
+		// given
+		var ctx context.Context
+		tracer, spans := newRecordingTracer()
+		routes := newTestRouter(tracer, func(w http.ResponseWriter, r *http.Request) {
+			ctx = r.Context()
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/products/1", nil)
+		r.Header.Set(RequestIdHeader, "abc-123")
+		r.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		// when
+		routes.ServeHTTP(w, r)
+
+		// then
+		sc := trace.SpanContextFromContext(ctx)
+		assert.Equal(t, "abc-123", RequestIdFromContext(ctx))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+		assert.NotEqual(t, "00f067aa0ba902b7", sc.SpanID().String())
+		assert.Len(t, spans(), 1)
+		assert.Equal(t, "00f067aa0ba902b7", spans()[0].Parent.SpanID().String())
+	})
+
+	t.Run("should replace invalid request id", func(t *testing.T) {
+		// given
+		routes := newTestRouter(NewTracer("test", nil), func(w http.ResponseWriter, r *http.Request) {})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/products/1", nil)
+		r.Header.Set(RequestIdHeader, "evil\nid")
+
+		// when
+		routes.ServeHTTP(w, r)
+
+		// then
+		assert.Len(t, w.Header().Get(RequestIdHeader), 32)
+	})
+
+	t.Run("should start new trace if traceparent is invalid", func(t *testing.T) {
+		// given
+		var ctx context.Context
+		routes := newTestRouter(NewTracer("test", nil), func(w http.ResponseWriter, r *http.Request) {
+			ctx = r.Context()
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/products/1", nil)
+		r.Header.Set(TraceparentHeader, "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01")
+
+		// when
+		routes.ServeHTTP(w, r)
+
+		// then
+		sc := trace.SpanContextFromContext(ctx)
+		assert.True(t, sc.IsValid())
+		assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+	})
+
+	t.Run("should export server span named after the route", func(t *testing.T) {
+		// given
+		tracer, spans := newRecordingTracer()
+		routes := newTestRouter(tracer, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/products/1", nil)
+
+		// when
+		routes.ServeHTTP(w, r)
+
+		// then
+		assert.Len(t, spans(), 1)
+		span := spans()[0]
+		assert.Equal(t, "GET /products/:productid", span.Name)
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+		assert.Equal(t, int64(http.StatusInternalServerError), attributeValue(span.Attributes, "http.response.status_code").AsInt64())
+		assert.Equal(t, codes.Error, span.Status.Code)
+		assert.Equal(t, "500 Internal Server Error", span.Status.Description)
+	})
+
+	t.Run("should not export spans of unsampled traces", func(t *testing.T) {
+		// given
+		tracer, spans := newRecordingTracer()
+		routes := newTestRouter(tracer, func(w http.ResponseWriter, r *http.Request) {})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/products/1", nil)
+		r.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+
+		// when
+		routes.ServeHTTP(w, r)
+
+		// then
+		assert.Empty(t, spans())
+	})
+}
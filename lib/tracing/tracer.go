@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const scopeName = "github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/tracing"
+
+// propagator reads and writes the W3C traceparent header.
+var propagator = propagation.TraceContext{}
+
+// Tracer starts spans and exports them in batches using the OpenTelemetry
+// SDK. Without an exporter spans are still created, so trace context is
+// propagated, but never exported.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+func NewTracer(service string, exporter sdktrace.SpanExporter) *Tracer {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(service))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.AlwaysSample())),
+	}
+
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	return &Tracer{provider, provider.Tracer(scopeName)}
+}
+
+// Start starts a span as child of the span in ctx. Without a parent a new
+// trace is started.
+func (tracer *Tracer) Start(ctx context.Context, name string, kind trace.SpanKind) (context.Context, trace.Span) {
+	return tracer.tracer.Start(ctx, name, trace.WithSpanKind(kind))
+}
+
+// Shutdown exports the remaining spans and stops the exporter.
+func (tracer *Tracer) Shutdown(ctx context.Context) error {
+	return tracer.provider.Shutdown(ctx)
+}
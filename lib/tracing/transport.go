@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport forwards the request id and trace context of the request context
+// to outgoing requests. Requests of a traced context are traced as client
+// spans, other requests are sent unchanged.
+type Transport struct {
+	tracer *Tracer
+	base   http.RoundTripper
+}
+
+// NewTransport wraps base, which defaults to http.DefaultTransport.
+func NewTransport(tracer *Tracer, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{tracer, base}
+}
+
+func (transport *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	requestId := RequestIdFromContext(r.Context())
+	if requestId == "" && !trace.SpanContextFromContext(r.Context()).IsValid() {
+		return transport.base.RoundTrip(r)
+	}
+
+	ctx, span := transport.tracer.Start(r.Context(), r.Method, trace.SpanKindClient)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("http.request.method", r.Method),
+		attribute.String("server.address", r.URL.Host),
+		attribute.String("url.full", r.URL.String()),
+	)
+
+	outgoing := r.Clone(ctx)
+	propagator.Inject(ctx, propagation.HeaderCarrier(outgoing.Header))
+	if requestId != "" {
+		outgoing.Header.Set(RequestIdHeader, requestId)
+	}
+
+	res, err := transport.base.RoundTrip(outgoing)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+	if res.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, strconv.Itoa(res.StatusCode)+" "+http.StatusText(res.StatusCode))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTransport(t *testing.T) {
+	var upstreamReq *http.Request
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamReq = r
+	}))
+	t.Cleanup(upstream.Close)
+
+	t.Run("should forward request id and trace context", func(t *testing.T) {
+		// given
+		tracer, spans := newRecordingTracer()
+		client := &http.Client{Transport: NewTransport(tracer, nil)}
+
+		ctx, parent := tracer.Start(WithRequestId(context.Background(), "abc"), "parent", trace.SpanKindServer)
+		req, _ := http.NewRequestWithContext(ctx, "GET", upstream.URL+"/api/v1/products", nil)
+
+		// when
+		res, err := client.Do(req)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "abc", upstreamReq.Header.Get(RequestIdHeader))
+
+		assert.Len(t, spans(), 1)
+		span := spans()[0]
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+
+		traceparent := "00-" + span.SpanContext.TraceID().String() + "-" + span.SpanContext.SpanID().String() + "-01"
+		assert.Equal(t, traceparent, upstreamReq.Header.Get(TraceparentHeader))
+		assert.Empty(t, req.Header.Get(TraceparentHeader))
+	})
+
+	t.Run("should send requests without trace context unchanged", func(t *testing.T) {
+		// given
+		tracer, spans := newRecordingTracer()
+		client := &http.Client{Transport: NewTransport(tracer, nil)}
+
+		// when
+		_, err := client.Get(upstream.URL)
+
+		// then
+		assert.NoError(t, err)
+		assert.Empty(t, upstreamReq.Header.Get(TraceparentHeader))
+		assert.Empty(t, upstreamReq.Header.Get(RequestIdHeader))
+		assert.Empty(t, spans())
+	})
+
+	t.Run("should mark client span as failed if upstream is not reachable", func(t *testing.T) {
+		// given
+		tracer, spans := newRecordingTracer()
+		client := &http.Client{Transport: NewTransport(tracer, nil)}
+
+		ctx, _ := tracer.Start(context.Background(), "parent", trace.SpanKindServer)
+		req, _ := http.NewRequestWithContext(ctx, "GET", "http://127.0.0.1:1", nil)
+
+		// when
+		_, err := client.Do(req)
+
+		// then
+		assert.Error(t, err)
+		assert.Len(t, spans(), 1)
+		assert.Equal(t, codes.Error, spans()[0].Status.Code)
+		assert.NotEmpty(t, spans()[0].Status.Description)
+	})
+}
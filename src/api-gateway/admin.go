@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/httpproxy"
+)
+
+type UpstreamStatusProvider interface {
+	Status() []httpproxy.RouteStatus
+}
+
+type AdminHandler struct {
+	mux *http.ServeMux
+}
+
+func NewAdminHandler(upstreams UpstreamStatusProvider, metricsHandler http.Handler) *AdminHandler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metricsHandler)
+	mux.HandleFunc("/upstreams", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(upstreams.Status())
+	})
+
+	return &AdminHandler{mux}
+}
+
+func (handler *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler.mux.ServeHTTP(w, r)
+}
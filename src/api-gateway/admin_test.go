@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/httpproxy"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandler(t *testing.T) {
+	proxy := httpproxy.New()
+	proxy.MapPool("/api/v1/products", []string{"http://products-1:3000", "http://products-2:3000"})
+	handler := NewAdminHandler(proxy, metrics.NewRegistry())
+
+	t.Run("should return 405 METHOD NOT ALLOWED if method is not GET", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/upstreams", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("should return upstream health", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/upstreams", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		var response []httpproxy.RouteStatus
+		err := json.NewDecoder(w.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Len(t, response, 1)
+		assert.Len(t, response[0].Backends, 2)
+		assert.True(t, response[0].Backends[0].Healthy)
+	})
+	t.Run("should return metrics", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/metrics", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	})
+}
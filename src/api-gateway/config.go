@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/cors"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/httpproxy"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/ratelimit"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/server"
+	"gopkg.in/yaml.v3"
+)
+
+type ApplicationConfig struct {
+	Listen    string          `yaml:"listen"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Jwt       JwtConfig       `yaml:"jwt"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	RateLimit RateLimitConfig `yaml:"rateLimit"`
+	Cors      cors.Config     `yaml:"cors"`
+	Server    server.Config   `yaml:"server"`
+	Routes    []RouteConfig   `yaml:"routes"`
+}
+
+type RateLimitConfig struct {
+	Store string `yaml:"store"`
+}
+
+type TracingConfig struct {
+	Exporter string `yaml:"exporter"`
+}
+
+type JwtConfig struct {
+	PublicKey        string            `yaml:"publicKey"`
+	JwksUrl          string            `yaml:"jwksUrl"`
+	Issuer           string            `yaml:"issuer"`
+	Audience         string            `yaml:"audience"`
+	RevokedTokensUrl string            `yaml:"revokedTokensUrl"`
+	ClaimHeaders     map[string]string `yaml:"claimHeaders"`
+}
+
+type AdminConfig struct {
+	Listen string `yaml:"listen"`
+}
+
+type RouteConfig struct {
+	Prefix      string             `yaml:"prefix"`
+	Upstreams   []string           `yaml:"upstreams"`
+	Strategy    string             `yaml:"strategy"`
+	HashKey     HashKeyConfig      `yaml:"hashKey"`
+	StripPrefix bool               `yaml:"stripPrefix"`
+	MaxFails    *int               `yaml:"maxFails"`
+	FailTimeout time.Duration      `yaml:"failTimeout"`
+	HealthCheck *HealthCheckConfig `yaml:"healthCheck"`
+	Auth        AuthConfig         `yaml:"auth"`
+	RateLimit   *RouteLimitConfig  `yaml:"rateLimit"`
+}
+
+type RouteLimitConfig struct {
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+	Burst    int           `yaml:"burst"`
+	Key      string        `yaml:"key"`
+	Header   string        `yaml:"header"`
+	FailOpen bool          `yaml:"failOpen"`
+}
+
+type AuthConfig struct {
+	Methods []string `yaml:"methods"`
+}
+
+type HealthCheckConfig struct {
+	Path     string        `yaml:"path"`
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Rise     int           `yaml:"rise"`
+	Fall     int           `yaml:"fall"`
+}
+
+type HashKeyConfig struct {
+	Header string `yaml:"header"`
+	Cookie string `yaml:"cookie"`
+}
+
+func LoadConfigFromFile(path string) (*ApplicationConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config := ApplicationConfig{
+		Listen: "0.0.0.0:3000",
+		Admin: AdminConfig{
+			Listen: "0.0.0.0:9000",
+		},
+		Server: defaultServerConfig(),
+	}
+	if err := yaml.NewDecoder(f).Decode(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// defaultServerConfig disables the read and write timeouts, because they
+// cover the whole body and the proxy streams bodies of any size and duration.
+// Slow clients are still cut off by ReadHeaderTimeout and IdleTimeout.
+func defaultServerConfig() server.Config {
+	config := server.DefaultConfig()
+	config.ReadTimeout = 0
+	config.WriteTimeout = 0
+	return config
+}
+
+func (config JwtConfig) Verifier() (jwtauth.TokenVerifier, error) {
+	opts := []jwtauth.VerifierOption{
+		jwtauth.WithIssuer(config.Issuer),
+		jwtauth.WithAudience(config.Audience),
+	}
+
+	if config.RevokedTokensUrl != "" {
+		opts = append(opts, jwtauth.WithRevocationList(jwtauth.NewHTTPRevocationList(config.RevokedTokensUrl)))
+	}
+
+	if config.JwksUrl != "" {
+		return jwtauth.NewKeySourceVerifier(jwtauth.NewJWKSKeySource(config.JwksUrl), opts...), nil
+	}
+
+	if config.PublicKey == "" {
+		return nil, nil
+	}
+
+	publicKey, err := jwtauth.ReadPublicKey(config.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwtauth.NewVerifier(publicKey, opts...), nil
+}
+
+func (config RouteConfig) Options(verifier jwtauth.TokenVerifier, claimHeaders map[string]string, limitStore ratelimit.Store, logger *slog.Logger) ([]httpproxy.RouteOption, error) {
+	var opts []httpproxy.RouteOption
+
+	if verifier != nil {
+		authOpts := []jwtauth.Option{jwtauth.RequiredForMethods(config.Auth.Methods...)}
+		if len(claimHeaders) > 0 {
+			authOpts = append(authOpts, jwtauth.WithClaimHeaders(claimHeaders))
+		}
+
+		opts = append(opts, httpproxy.WithMiddleware(jwtauth.NewMiddleware(verifier, authOpts...).Handler))
+	} else if len(config.Auth.Methods) > 0 {
+		return nil, fmt.Errorf("route %s: auth requires a jwt public key or jwks url", config.Prefix)
+	} else {
+		opts = append(opts, httpproxy.WithMiddleware(jwtauth.StripClaimHeaders(claimHeaders)))
+	}
+
+	if config.RateLimit != nil {
+		limiter, err := config.limiter(limitStore, logger)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, httpproxy.WithMiddleware(limiter.Handler))
+	}
+
+	balancer, err := config.balancer()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, httpproxy.WithBalancer(balancer))
+
+	if config.StripPrefix {
+		opts = append(opts, httpproxy.StripPrefix())
+	}
+
+	if config.MaxFails != nil {
+		failTimeout := config.FailTimeout
+		if failTimeout == 0 {
+			failTimeout = 30 * time.Second
+		}
+		opts = append(opts, httpproxy.WithPassiveHealthCheck(*config.MaxFails, failTimeout))
+	}
+
+	if config.HealthCheck != nil {
+		opts = append(opts, httpproxy.WithActiveHealthCheck(httpproxy.HealthCheck{
+			Path:     config.HealthCheck.Path,
+			Interval: config.HealthCheck.Interval,
+			Timeout:  config.HealthCheck.Timeout,
+			Rise:     config.HealthCheck.Rise,
+			Fall:     config.HealthCheck.Fall,
+		}))
+	}
+
+	return opts, nil
+}
+
+func (config RouteConfig) limiter(store ratelimit.Store, logger *slog.Logger) (*ratelimit.Middleware, error) {
+	var key ratelimit.KeyFunc
+	switch config.RateLimit.Key {
+	case "", "ip":
+		key = ratelimit.ClientIP
+	case "subject":
+		key = ratelimit.Subject
+	case "header":
+		if config.RateLimit.Header == "" {
+			return nil, fmt.Errorf("route %s: rate limit key header requires a header", config.Prefix)
+		}
+		key = ratelimit.HeaderKey(config.RateLimit.Header)
+	default:
+		return nil, fmt.Errorf("route %s: unknown rate limit key %q", config.Prefix, config.RateLimit.Key)
+	}
+
+	limit := ratelimit.Limit{
+		Requests: config.RateLimit.Requests,
+		Period:   config.RateLimit.Period,
+		Burst:    config.RateLimit.Burst,
+	}
+
+	limiter, err := ratelimit.NewMiddleware(store, config.Prefix, limit,
+		ratelimit.WithKey(key),
+		ratelimit.WithFailOpen(config.RateLimit.FailOpen),
+		ratelimit.WithLogger(logger))
+	if err != nil {
+		return nil, fmt.Errorf("route %s: %w", config.Prefix, err)
+	}
+
+	return limiter, nil
+}
+
+func (config RouteConfig) balancer() (httpproxy.Balancer, error) {
+	switch config.Strategy {
+	case "", "round-robin":
+		return httpproxy.NewRoundRobinBalancer(), nil
+	case "least-connections":
+		return httpproxy.NewLeastConnectionsBalancer(), nil
+	case "consistent-hash":
+		switch {
+		case config.HashKey.Header != "":
+			return httpproxy.NewConsistentHashBalancer(httpproxy.HeaderKey(config.HashKey.Header)), nil
+		case config.HashKey.Cookie != "":
+			return httpproxy.NewConsistentHashBalancer(httpproxy.CookieKey(config.HashKey.Cookie)), nil
+		}
+		return nil, fmt.Errorf("route %s: consistent-hash requires a hashKey header or cookie", config.Prefix)
+	}
+
+	return nil, fmt.Errorf("route %s: unknown strategy %q", config.Prefix, config.Strategy)
+}
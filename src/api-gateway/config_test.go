@@ -0,0 +1,259 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/httpproxy"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/ratelimit"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig(t *testing.T) {
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	t.Run("LoadConfigFromFile", func(t *testing.T) {
+		t.Run("should return error if file does not exist", func(t *testing.T) {
+			// given
+			path := filepath.Join(t.TempDir(), "missing.yml")
+
+			// when
+			config, err := LoadConfigFromFile(path)
+
+			// then
+			assert.Error(t, err)
+			assert.Nil(t, config)
+		})
+
+		t.Run("should parse routes", func(t *testing.T) {
+			// given
+			path := filepath.Join(t.TempDir(), "config.yml")
+			os.WriteFile(path, []byte(`
+cors:
+  allowedOrigins: [https://shop.example.com]
+  maxAge: 10m
+routes:
+  - prefix: /api/v1/products
+    upstreams: [http://products-1:3000, http://products-2:3000]
+    strategy: consistent-hash
+    hashKey:
+      cookie: session
+    maxFails: 5
+    failTimeout: 1m
+    rateLimit:
+      requests: 100
+      period: 1m
+      key: subject
+      failOpen: true
+`), 0600)
+
+			// when
+			config, err := LoadConfigFromFile(path)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, "0.0.0.0:3000", config.Listen)
+			assert.Equal(t, server.DefaultConfig().ReadHeaderTimeout, config.Server.ReadHeaderTimeout)
+			assert.Equal(t, server.DefaultConfig().IdleTimeout, config.Server.IdleTimeout)
+			assert.Zero(t, config.Server.ReadTimeout)
+			assert.Zero(t, config.Server.WriteTimeout)
+			assert.Equal(t, []string{"https://shop.example.com"}, config.Cors.AllowedOrigins)
+			assert.Equal(t, 10*time.Minute, config.Cors.MaxAge)
+			assert.Len(t, config.Routes, 1)
+			assert.Equal(t, []string{"http://products-1:3000", "http://products-2:3000"}, config.Routes[0].Upstreams)
+			assert.Equal(t, "session", config.Routes[0].HashKey.Cookie)
+			assert.Equal(t, 5, *config.Routes[0].MaxFails)
+			assert.Equal(t, time.Minute, config.Routes[0].FailTimeout)
+			assert.Equal(t, &RouteLimitConfig{Requests: 100, Period: time.Minute, Key: "subject", FailOpen: true}, config.Routes[0].RateLimit)
+		})
+	})
+
+	t.Run("JwtConfig.Verifier", func(t *testing.T) {
+		t.Run("should return nil if no public key is configured", func(t *testing.T) {
+			// given
+			config := JwtConfig{}
+
+			// when
+			verifier, err := config.Verifier()
+
+			// then
+			assert.NoError(t, err)
+			assert.Nil(t, verifier)
+		})
+
+		t.Run("should fetch keys from jwks url", func(t *testing.T) {
+			// given
+			config := JwtConfig{JwksUrl: "http://user-service:8080/.well-known/jwks.json"}
+
+			// when
+			verifier, err := config.Verifier()
+
+			// then
+			assert.NoError(t, err)
+			assert.NotNil(t, verifier)
+		})
+
+		t.Run("should read public key", func(t *testing.T) {
+			// given
+			der, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+			path := filepath.Join(t.TempDir(), "key.pub")
+			os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600)
+
+			config := JwtConfig{PublicKey: path}
+
+			// when
+			verifier, err := config.Verifier()
+
+			// then
+			assert.NoError(t, err)
+			assert.NotNil(t, verifier)
+		})
+	})
+
+	t.Run("RouteConfig.Options", func(t *testing.T) {
+		t.Run("should return error if strategy is unknown", func(t *testing.T) {
+			// given
+			config := RouteConfig{Prefix: "/", Strategy: "random"}
+
+			// when
+			_, err := config.Options(nil, nil, nil, slog.Default())
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should return error if consistent-hash has no key", func(t *testing.T) {
+			// given
+			config := RouteConfig{Prefix: "/", Strategy: "consistent-hash"}
+
+			// when
+			_, err := config.Options(nil, nil, nil, slog.Default())
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should return error if auth is configured without jwt public key", func(t *testing.T) {
+			// given
+			config := RouteConfig{Prefix: "/", Auth: AuthConfig{Methods: []string{"POST"}}}
+
+			// when
+			_, err := config.Options(nil, nil, nil, slog.Default())
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should add auth middleware if verifier is configured", func(t *testing.T) {
+			// given
+			config := RouteConfig{Prefix: "/", Auth: AuthConfig{Methods: []string{"POST"}}}
+			verifier := jwtauth.NewVerifier(&privateKey.PublicKey)
+
+			// when
+			opts, err := config.Options(verifier, nil, nil, slog.Default())
+
+			// then
+			assert.NoError(t, err)
+			assert.Len(t, opts, 2)
+		})
+
+		t.Run("should strip claim headers if no verifier is configured", func(t *testing.T) {
+			// given
+			var upstreamReq *http.Request
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				upstreamReq = r
+			}))
+			defer upstream.Close()
+
+			config := RouteConfig{Prefix: "/", Upstreams: []string{upstream.URL}}
+			opts, err := config.Options(nil, map[string]string{"sub": "X-User-Id"}, nil, slog.Default())
+			assert.NoError(t, err)
+
+			proxy := httpproxy.New()
+			proxy.MapPool(config.Prefix, config.Upstreams, opts...)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/v1/products", nil)
+			r.Header.Set("X-User-Id", "1")
+
+			// when
+			proxy.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, upstreamReq.Header.Get("X-User-Id"))
+		})
+
+		t.Run("should return options for known strategies", func(t *testing.T) {
+			tests := []RouteConfig{
+				{Strategy: ""},
+				{Strategy: "round-robin"},
+				{Strategy: "least-connections"},
+				{Strategy: "consistent-hash", HashKey: HashKeyConfig{Header: "X-Session"}},
+			}
+
+			for _, test := range tests {
+				// given
+				// when
+				opts, err := test.Options(nil, nil, nil, slog.Default())
+
+				// then
+				assert.NoError(t, err)
+				assert.NotEmpty(t, opts)
+			}
+		})
+
+		t.Run("should add rate limit middleware", func(t *testing.T) {
+			tests := []RouteLimitConfig{
+				{Requests: 10, Period: time.Second},
+				{Requests: 10, Period: time.Second, Key: "ip"},
+				{Requests: 10, Period: time.Second, Key: "subject"},
+				{Requests: 10, Period: time.Second, Key: "header", Header: "X-Api-Key"},
+			}
+
+			for _, test := range tests {
+				// given
+				config := RouteConfig{Prefix: "/", RateLimit: &test}
+
+				// when
+				opts, err := config.Options(nil, nil, ratelimit.NewMemoryStore(), slog.Default())
+
+				// then
+				assert.NoError(t, err)
+				assert.Len(t, opts, 3)
+			}
+		})
+
+		t.Run("should return error if rate limit is invalid", func(t *testing.T) {
+			tests := []RouteLimitConfig{
+				{},
+				{Requests: 10},
+				{Requests: 10, Period: time.Second, Key: "cookie"},
+				{Requests: 10, Period: time.Second, Key: "header"},
+			}
+
+			for _, test := range tests {
+				// given
+				config := RouteConfig{Prefix: "/", RateLimit: &test}
+
+				// when
+				_, err := config.Options(nil, nil, ratelimit.NewMemoryStore(), slog.Default())
+
+				// then
+				assert.Error(t, err)
+			}
+		})
+	})
+}
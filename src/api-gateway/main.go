@@ -1,12 +1,98 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"io"
 	"log"
-	"net/http"
+	"log/slog"
+	"os"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/cors"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/httpproxy"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/metrics"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/middleware"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/ratelimit"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/server"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/tracing"
 )
 
 func main() {
-	proxy := httpproxy.New()
-	proxy.Map("/", "http://web-service:3000")
-	log.Fatal(http.ListenAndServe("0.0.0.0:3000", proxy))
+	configPath := flag.String("config", "config.yml", "The path to the configuration file")
+	flag.Parse()
+
+	config, err := LoadConfigFromFile(*configPath)
+	if err != nil {
+		log.Fatalf("could not load application configuration: %s", err.Error())
+	}
+
+	verifier, err := config.Jwt.Verifier()
+	if err != nil {
+		log.Fatalf("could not create JWT verifier: %s", err.Error())
+	}
+
+	exporter, err := tracing.NewExporter(config.Tracing.Exporter)
+	if err != nil {
+		log.Fatalf("could not create trace exporter: %s", err.Error())
+	}
+
+	tracer := tracing.NewTracer("api-gateway", exporter)
+
+	limitStore, err := ratelimit.NewStore(config.RateLimit.Store)
+	if err != nil {
+		log.Fatalf("could not create rate limit store: %s", err.Error())
+	}
+
+	httpMetrics := metrics.NewHTTPMetrics(metrics.Default)
+
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, nil)))
+
+	proxy := httpproxy.New(
+		httpproxy.WithTransport(tracing.NewTransport(tracer, nil)),
+		httpproxy.WithLogger(logger),
+	)
+	for _, route := range config.Routes {
+		opts, err := route.Options(verifier, config.Jwt.ClaimHeaders, limitStore, logger)
+		if err != nil {
+			log.Fatalf("invalid route configuration: %s", err.Error())
+		}
+
+		opts = append([]httpproxy.RouteOption{httpproxy.WithMiddleware(httpMetrics.Route(route.Prefix))}, opts...)
+		proxy.MapPool(route.Prefix, route.Upstreams, opts...)
+	}
+
+	healthChecks, stopHealthChecks := context.WithCancel(context.Background())
+	proxy.StartHealthChecks(healthChecks)
+
+	handler := middleware.Recover(logger)(proxy)
+	if config.Cors.Enabled() {
+		corsMiddleware, err := cors.New(config.Cors)
+		if err != nil {
+			log.Fatalf("invalid cors configuration: %s", err.Error())
+		}
+		handler = corsMiddleware.Handler(handler)
+	}
+	handler = tracing.Middleware(tracer)(middleware.AccessLog(logger)(handler))
+
+	runner := server.NewRunner(config.Server)
+	if err := runner.Listen(config.Listen, handler); err != nil {
+		log.Fatalf("error while listen: %s", err.Error())
+	}
+
+	if err := runner.Listen(config.Admin.Listen, NewAdminHandler(proxy, metrics.Handler())); err != nil {
+		log.Fatalf("error while listen on admin address: %s", err.Error())
+	}
+
+	if closer, ok := limitStore.(io.Closer); ok {
+		runner.OnShutdown(server.Close(closer))
+	}
+	runner.OnShutdown(tracer.Shutdown)
+	runner.OnShutdown(func(ctx context.Context) error {
+		stopHealthChecks()
+		return nil
+	})
+
+	if err := runner.Run(context.Background()); err != nil {
+		log.Fatalf("error while shutting down: %s", err.Error())
+	}
 }
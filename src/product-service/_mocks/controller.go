@@ -74,6 +74,18 @@ func (mr *MockControllerMockRecorder) GetProducts(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockController)(nil).GetProducts), arg0, arg1)
 }
 
+// PatchProduct mocks base method.
+func (m *MockController) PatchProduct(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PatchProduct", arg0, arg1)
+}
+
+// PatchProduct indicates an expected call of PatchProduct.
+func (mr *MockControllerMockRecorder) PatchProduct(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchProduct", reflect.TypeOf((*MockController)(nil).PatchProduct), arg0, arg1)
+}
+
 // PostProducts mocks base method.
 func (m *MockController) PostProducts(arg0 http.ResponseWriter, arg1 *http.Request) {
 	m.ctrl.T.Helper()
@@ -97,3 +109,15 @@ func (mr *MockControllerMockRecorder) PutProduct(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutProduct", reflect.TypeOf((*MockController)(nil).PutProduct), arg0, arg1)
 }
+
+// SearchProducts mocks base method.
+func (m *MockController) SearchProducts(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SearchProducts", arg0, arg1)
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockControllerMockRecorder) SearchProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockController)(nil).SearchProducts), arg0, arg1)
+}
@@ -1,26 +1,60 @@
 package router
 
 import (
+	"log/slog"
 	"net/http"
 
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/authz"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/health"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/products"
 )
 
+const (
+	roleRetailer = "retailer"
+	roleAdmin    = "admin"
+)
+
 type Router struct {
 	router http.Handler
 }
 
 func New(
 	productsController products.Controller,
+	productOwner authz.Policy,
+	healthHandler *health.Handler,
+	logger *slog.Logger,
+	middlewares ...router.Middleware,
 ) *Router {
 	router := router.New()
+	router.Use(middlewares...)
+
+	canCreate := authz.HasRole(roleRetailer, roleAdmin)
+	canManage := authz.AnyOf(
+		authz.HasRole(roleAdmin),
+		authz.AllOf(authz.HasRole(roleRetailer), productOwner),
+	)
+
+	router.GET("/healthz", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
+
+	products := router.Group("/api/v1/products")
+	products.GET("", productsController.GetProducts)
+	products.GET("/search", productsController.SearchProducts)
+	products.POST("", productsController.PostProducts, authz.Require(canCreate, authz.WithLogger(logger)))
+	products.GET("/:productid<int>", productsController.GetProduct)
+	products.PUT("/:productid<int>", productsController.PutProduct, authz.Require(canManage, authz.WithLogger(logger)))
+	products.PATCH("/:productid<int>", productsController.PatchProduct, authz.Require(canManage, authz.WithLogger(logger)))
+	products.DELETE("/:productid<int>", productsController.DeleteProduct, authz.Require(canManage, authz.WithLogger(logger)))
 
-	router.GET("/api/v1/products", productsController.GetProducts)
-	router.POST("/api/v1/products", productsController.PostProducts)
-	router.GET("/api/v1/products/:productid", productsController.GetProduct)
-	router.PUT("/api/v1/products/:productid", productsController.PutProduct)
-	router.DELETE("/api/v1/products/:productid", productsController.DeleteProduct)
+	return &Router{router}
+}
+
+// NewAdmin serves the metrics on a separate port, which must not be exposed
+// to the public.
+func NewAdmin(metricsHandler http.Handler) *Router {
+	router := router.New()
+	router.Handle(http.MethodGet, "/metrics", metricsHandler)
 
 	return &Router{router}
 }
@@ -1,12 +1,19 @@
 package router
 
 import (
-	"context"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/cors"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/gomockhelpers"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/health"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/metrics"
+	librouter "github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
 	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/_mocks"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/products"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
@@ -15,11 +22,64 @@ func TestRouter(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
 	productsController := mocks.NewMockController(ctrl)
-	router := New(productsController)
+	productOwner := func(r *http.Request, claims jwtauth.Claims) (bool, error) {
+		if r.URL.Path == "/api/v1/products/999" {
+			return false, products.ErrNotFound
+		}
+
+		return claims.String("sub") == "42", nil
+	}
+	router := New(productsController, productOwner, health.NewHandler(), slog.Default())
+
+	withClaims := func(r *http.Request, claims jwtauth.Claims) *http.Request {
+		return r.WithContext(jwtauth.NewContext(r.Context(), claims))
+	}
+
+	t.Run("/healthz", func(t *testing.T) {
+		t.Run("should return 200 OK", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/healthz", nil)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	})
+
+	t.Run("/readyz", func(t *testing.T) {
+		t.Run("should return 200 OK", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/readyz", nil)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	})
+
+	t.Run("/metrics", func(t *testing.T) {
+		t.Run("should not be served publicly", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/metrics", nil)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		})
+	})
 
 	t.Run("/api/v1/products", func(t *testing.T) {
-		t.Run("should return 404 NOT FOUND if method is not GET or POST", func(t *testing.T) {
-			tests := []string{"DELETE", "PUT", "HEAD", "CONNECT", "OPTIONS", "TRACE", "PATCH"}
+		t.Run("should return 405 METHOD NOT ALLOWED if method is not GET or POST", func(t *testing.T) {
+			tests := []string{"DELETE", "PUT", "CONNECT", "TRACE", "PATCH"}
 
 			for _, test := range tests {
 				// given
@@ -30,10 +90,62 @@ func TestRouter(t *testing.T) {
 				router.ServeHTTP(w, r)
 
 				// then
-				assert.Equal(t, http.StatusNotFound, w.Code)
+				assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+				assert.Equal(t, "GET, HEAD, OPTIONS, POST", w.Header().Get("Allow"))
 			}
 		})
 
+		t.Run("should return 204 NO CONTENT with allowed methods for OPTIONS", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("OPTIONS", "/api/v1/products", nil)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, "GET, HEAD, OPTIONS, POST", w.Header().Get("Allow"))
+		})
+
+		t.Run("should answer CORS preflight for POST", func(t *testing.T) {
+			// given
+			corsMiddleware, _ := cors.New(cors.Config{AllowedOrigins: []string{"https://shop.example.com"}})
+			router := New(productsController, productOwner, health.NewHandler(), slog.Default(), corsMiddleware.Handler)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("OPTIONS", "/api/v1/products", nil)
+			r.Header.Set("Origin", "https://shop.example.com")
+			r.Header.Set("Access-Control-Request-Method", "POST")
+			r.Header.Set("Access-Control-Request-Headers", "Authorization, Content-Type")
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, "https://shop.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "GET, HEAD, POST", w.Header().Get("Access-Control-Allow-Methods"))
+			assert.Equal(t, "Authorization, Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+		})
+
+		t.Run("should call GET handler for HEAD", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("HEAD", "/api/v1/products", nil)
+
+			productsController.
+				EXPECT().
+				GetProducts(gomock.Any(), gomock.Any()).
+				Times(1)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
 		t.Run("should call GET handler", func(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
@@ -41,7 +153,7 @@ func TestRouter(t *testing.T) {
 
 			productsController.
 				EXPECT().
-				GetProducts(w, r).
+				GetProducts(w, gomockhelpers.Request(r)).
 				Times(1)
 
 			// when
@@ -51,14 +163,55 @@ func TestRouter(t *testing.T) {
 			assert.Equal(t, http.StatusOK, w.Code)
 		})
 
-		t.Run("should call POST handler", func(t *testing.T) {
+		t.Run("should call search handler", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/v1/products/search?q=shirt", nil)
+
+			productsController.
+				EXPECT().
+				SearchProducts(w, gomockhelpers.Request(r)).
+				Times(1)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
+		t.Run("should return 401 UNAUTHORIZED if POST is not authenticated", func(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("POST", "/api/v1/products", nil)
 
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+
+		t.Run("should return 403 FORBIDDEN if customer calls POST", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := withClaims(httptest.NewRequest("POST", "/api/v1/products", nil), jwtauth.Claims{"role": "customer"})
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusForbidden, w.Code)
+		})
+
+		t.Run("should call POST handler", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := withClaims(httptest.NewRequest("POST", "/api/v1/products", nil), jwtauth.Claims{"role": "retailer"})
+
 			productsController.
 				EXPECT().
-				PostProducts(w, r).
+				PostProducts(w, gomockhelpers.Request(r)).
 				Times(1)
 
 			// when
@@ -70,8 +223,20 @@ func TestRouter(t *testing.T) {
 	})
 
 	t.Run("/api/v1/products/:productid", func(t *testing.T) {
-		t.Run("should return 404 NOT FOUND if method is not GET, DELETE or PUT", func(t *testing.T) {
-			tests := []string{"POST", "HEAD", "CONNECT", "OPTIONS", "TRACE", "PATCH"}
+		t.Run("should return 404 NOT FOUND if productid is no integer", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/v1/products/abc", nil)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		})
+
+		t.Run("should return 405 METHOD NOT ALLOWED if method is not GET, DELETE, PATCH or PUT", func(t *testing.T) {
+			tests := []string{"POST", "CONNECT", "TRACE"}
 
 			for _, test := range tests {
 				// given
@@ -82,10 +247,41 @@ func TestRouter(t *testing.T) {
 				router.ServeHTTP(w, r)
 
 				// then
-				assert.Equal(t, http.StatusNotFound, w.Code)
+				assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+				assert.Equal(t, "DELETE, GET, HEAD, OPTIONS, PATCH, PUT", w.Header().Get("Allow"))
 			}
 		})
 
+		t.Run("should return 204 NO CONTENT with allowed methods for OPTIONS", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("OPTIONS", "/api/v1/products/1", nil)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, "DELETE, GET, HEAD, OPTIONS, PATCH, PUT", w.Header().Get("Allow"))
+		})
+
+		t.Run("should call GET handler for HEAD", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("HEAD", "/api/v1/products/1", nil)
+
+			productsController.
+				EXPECT().
+				GetProduct(gomock.Any(), gomock.Any()).
+				Times(1)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
 		t.Run("should call GET handler", func(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
@@ -93,7 +289,7 @@ func TestRouter(t *testing.T) {
 
 			productsController.
 				EXPECT().
-				GetProduct(w, r.WithContext(context.WithValue(r.Context(), "productid", "1"))).
+				GetProduct(w, gomockhelpers.Request(r, librouter.Param{Key: "productid", Value: "1"})).
 				Times(1)
 
 			// when
@@ -103,14 +299,73 @@ func TestRouter(t *testing.T) {
 			assert.Equal(t, http.StatusOK, w.Code)
 		})
 
+		t.Run("should return 403 FORBIDDEN if PUT, PATCH or DELETE is not called by admin or owner", func(t *testing.T) {
+			tests := []struct {
+				method string
+				claims jwtauth.Claims
+			}{
+				{"PUT", jwtauth.Claims{"sub": "42", "role": "customer"}},
+				{"PUT", jwtauth.Claims{"sub": "43", "role": "retailer"}},
+				{"PATCH", jwtauth.Claims{"sub": "42", "role": "customer"}},
+				{"PATCH", jwtauth.Claims{"sub": "43", "role": "retailer"}},
+				{"DELETE", jwtauth.Claims{"sub": "42", "role": "customer"}},
+				{"DELETE", jwtauth.Claims{"sub": "43", "role": "retailer"}},
+			}
+
+			for _, test := range tests {
+				// given
+				w := httptest.NewRecorder()
+				r := withClaims(httptest.NewRequest(test.method, "/api/v1/products/1", nil), test.claims)
+
+				// when
+				router.ServeHTTP(w, r)
+
+				// then
+				assert.Equal(t, http.StatusForbidden, w.Code)
+			}
+		})
+
+		t.Run("should return 404 NOT FOUND if retailer calls PUT, PATCH or DELETE for missing product", func(t *testing.T) {
+			tests := []string{"PUT", "PATCH", "DELETE"}
+
+			for _, test := range tests {
+				// given
+				w := httptest.NewRecorder()
+				r := withClaims(httptest.NewRequest(test, "/api/v1/products/999", nil), jwtauth.Claims{"sub": "42", "role": "retailer"})
+
+				// when
+				router.ServeHTTP(w, r)
+
+				// then
+				assert.Equal(t, http.StatusNotFound, w.Code)
+			}
+		})
+
 		t.Run("should call PUT handler", func(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest("PUT", "/api/v1/products/1", nil)
+			r := withClaims(httptest.NewRequest("PUT", "/api/v1/products/1", nil), jwtauth.Claims{"role": "admin"})
+
+			productsController.
+				EXPECT().
+				PutProduct(w, gomockhelpers.Request(r, librouter.Param{Key: "productid", Value: "1"})).
+				Times(1)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
+		t.Run("should call PATCH handler", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := withClaims(httptest.NewRequest("PATCH", "/api/v1/products/1", nil), jwtauth.Claims{"sub": "42", "role": "retailer"})
 
 			productsController.
 				EXPECT().
-				PutProduct(w, r.WithContext(context.WithValue(r.Context(), "productid", "1"))).
+				PatchProduct(w, gomockhelpers.Request(r, librouter.Param{Key: "productid", Value: "1"})).
 				Times(1)
 
 			// when
@@ -123,11 +378,11 @@ func TestRouter(t *testing.T) {
 		t.Run("should call DELETE handler", func(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest("DELETE", "/api/v1/products/1", nil)
+			r := withClaims(httptest.NewRequest("DELETE", "/api/v1/products/1", nil), jwtauth.Claims{"sub": "42", "role": "retailer"})
 
 			productsController.
 				EXPECT().
-				DeleteProduct(w, r.WithContext(context.WithValue(r.Context(), "productid", "1"))).
+				DeleteProduct(w, gomockhelpers.Request(r, librouter.Param{Key: "productid", Value: "1"})).
 				Times(1)
 
 			// when
@@ -138,3 +393,34 @@ func TestRouter(t *testing.T) {
 		})
 	})
 }
+
+func TestAdminRouter(t *testing.T) {
+	router := NewAdmin(metrics.NewRegistry())
+
+	t.Run("/metrics", func(t *testing.T) {
+		t.Run("should return metrics", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/metrics", nil)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+		})
+	})
+
+	t.Run("should not serve public routes", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/products", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -1,12 +1,24 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"log"
-	"net/http"
+	"log/slog"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/cors"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/database"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/health"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/metrics"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/middleware"
+	librouter "github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/server"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/tracing"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/api/router"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/products"
 )
@@ -36,13 +48,84 @@ func main() {
 	}
 
 	productsController := products.NewDefaultController(productRepository)
-	handler := router.New(productsController)
+	healthHandler := health.NewHandler(productRepository)
+
+	exporter, err := tracing.NewExporter(os.Getenv("TRACES_EXPORTER"))
+	if err != nil {
+		log.Fatalf("could not create trace exporter: %s", err.Error())
+	}
+
+	tracer := tracing.NewTracer("product-service", exporter)
+
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, nil)))
+	metrics.Default.RegisterDBStats(map[string]func() sql.DBStats{"products": productRepository.Stats})
+
+	middlewares := []librouter.Middleware{
+		tracing.Middleware(tracer),
+		metrics.NewHTTPMetrics(metrics.Default).Handler,
+		middleware.AccessLog(logger),
+	}
+
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		corsMiddleware, err := cors.New(cors.Config{
+			AllowedOrigins: strings.Split(origins, ","),
+			AllowedMethods: []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"},
+			ExposedHeaders: []string{tracing.RequestIdHeader},
+			MaxAge:         10 * time.Minute,
+		})
+		if err != nil {
+			log.Fatalf("invalid cors configuration: %s", err.Error())
+		}
+		middlewares = append(middlewares, corsMiddleware.Handler)
+	}
+
+	middlewares = append(middlewares, middleware.Recover(logger))
+
+	if jwksUrl := os.Getenv("JWKS_URL"); jwksUrl != "" {
+		verifierOpts := []jwtauth.VerifierOption{
+			jwtauth.WithIssuer(os.Getenv("JWT_ISSUER")),
+			jwtauth.WithAudience(os.Getenv("JWT_AUDIENCE")),
+		}
+		if revokedUrl := os.Getenv("REVOKED_TOKENS_URL"); revokedUrl != "" {
+			verifierOpts = append(verifierOpts, jwtauth.WithRevocationList(jwtauth.NewHTTPRevocationList(revokedUrl)))
+		}
+
+		verifier := jwtauth.NewKeySourceVerifier(jwtauth.NewJWKSKeySource(jwksUrl), verifierOpts...)
+		middlewares = append(middlewares, jwtauth.NewMiddleware(verifier).Handler)
+	} else {
+		log.Printf("JWKS_URL is not set, all requests which need authorization will be rejected")
+	}
+
+	handler := router.New(productsController, products.OwnerPolicy(productRepository), healthHandler, logger, middlewares...)
 
 	if err := productRepository.Migrate(); err != nil {
 		log.Fatalf("could not migrate: %s", err.Error())
 	}
 
-	if err := http.ListenAndServe(":3000", handler); err != nil {
-		log.Fatalf("error while listen and serve: %s", err.Error())
+	serverConfig, err := server.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("invalid server configuration: %s", err.Error())
+	}
+
+	runner := server.NewRunner(serverConfig)
+	if err := runner.Listen(":3000", handler); err != nil {
+		log.Fatalf("error while listen: %s", err.Error())
+	}
+
+	adminPort := os.Getenv("ADMIN_PORT")
+	if adminPort == "" {
+		adminPort = "9000"
+	}
+
+	if err := runner.Listen(":"+adminPort, router.NewAdmin(metrics.Handler())); err != nil {
+		log.Fatalf("error while listen on admin port: %s", err.Error())
+	}
+
+	runner.AddReadiness(healthHandler)
+	runner.OnShutdown(server.Close(productRepository))
+	runner.OnShutdown(tracer.Shutdown)
+
+	if err := runner.Run(context.Background()); err != nil {
+		log.Fatalf("error while shutting down: %s", err.Error())
 	}
 }
@@ -2,30 +2,120 @@ package products
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/metrics"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/money"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/products/model"
 )
 
+var productsCreated = metrics.NewCounter("products_created_total", "Total number of created products.")
+
+// defaultCurrency is used for prices and price filters without a currency.
+const defaultCurrency = money.EUR
+
 type createProductRequest struct {
-	Name        string  `json:"name"`
-	Retailer    string  `json:"retailer"`
-	Price       float32 `json:"price"`
-	Description string  `json:"description"`
+	Name        string      `json:"name"`
+	Retailer    string      `json:"retailer"`
+	Price       money.Money `json:"price"`
+	Description string      `json:"description"`
 }
 
 type updateProductRequest struct {
-	Name        string  `json:"name"`
-	Retailer    string  `json:"retailer"`
-	Price       float32 `json:"price"`
-	Description string  `json:"description"`
+	Name        string      `json:"name"`
+	Retailer    string      `json:"retailer"`
+	Price       money.Money `json:"price"`
+	Description string      `json:"description"`
+}
+
+// merge applies a JSON Merge Patch (RFC 7396). Members set to null are reset
+// to their zero value, unknown members are rejected.
+func (r *updateProductRequest) merge(patch map[string]json.RawMessage) error {
+	fields := map[string]any{
+		"name":        &r.Name,
+		"retailer":    &r.Retailer,
+		"price":       &r.Price,
+		"description": &r.Description,
+	}
+
+	var validation problem.ValidationError
+	for key, value := range patch {
+		field, ok := fields[key]
+		if !ok {
+			validation.Add(key, "can't be patched")
+			continue
+		}
+
+		if string(value) == "null" {
+			value = zeroValues[key]
+		}
+
+		if err := json.Unmarshal(value, field); err != nil {
+			validation.Add(key, fieldMessage(err))
+		}
+	}
+
+	return validation.Err()
+}
+
+var zeroValues = map[string]json.RawMessage{
+	"name":        json.RawMessage(`""`),
+	"retailer":    json.RawMessage(`""`),
+	"price":       json.RawMessage(`{"amount":"0"}`),
+	"description": json.RawMessage(`""`),
+}
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// decodeRequest reads the JSON body into v. Values of the wrong type and
+// invalid prices are reported as field errors.
+func decodeRequest(r *http.Request, v any) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var field string
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		field = typeErr.Field
+	case errors.Is(err, money.ErrInvalidAmount), errors.Is(err, money.ErrUnknownCurrency):
+		field = "price"
+	default:
+		return problem.New(http.StatusBadRequest, "the request body is not valid JSON")
+	}
+
+	var validation problem.ValidationError
+	validation.Add(field, fieldMessage(err))
+	return validation.Err()
 }
 
-func (r createProductRequest) isValid() bool {
-	return r.Name != "" && r.Retailer != ""
+// fieldMessage describes why a JSON value could not be read into a field.
+func fieldMessage(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return "must not be a " + typeErr.Value
+	}
+
+	if errors.Is(err, money.ErrInvalidAmount) || errors.Is(err, money.ErrUnknownCurrency) {
+		return err.Error()
+	}
+
+	return "is invalid"
 }
 
+var errInvalidProductId = problem.New(http.StatusBadRequest, "the product id is not a number")
+
 type DefaultController struct {
 	productRepository Repository
 }
@@ -36,52 +126,179 @@ func NewDefaultController(
 	return &DefaultController{productRepository}
 }
 
+// parseQuery reads the paging, sorting and filter parameters of the product
+// list. Sorting is part of the cursor, so it may only be repeated unchanged
+// together with a cursor.
+func parseQuery(values url.Values) (model.Query, error) {
+	query := model.Query{
+		Sort:     model.SortKey(values.Get("sort")),
+		Retailer: values.Get("retailer"),
+		Name:     values.Get("name"),
+		Limit:    model.DefaultLimit,
+	}
+
+	if query.Sort == "" {
+		query.Sort = model.SortByCreatedAt
+	} else if !query.Sort.IsValid() {
+		return query, fmt.Errorf("invalid sort %q", query.Sort)
+	}
+
+	switch order := values.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, fmt.Errorf("invalid order %q", order)
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > model.MaxLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", model.MaxLimit)
+		}
+		query.Limit = n
+	}
+
+	if total := values.Get("total"); total != "" {
+		countTotal, err := strconv.ParseBool(total)
+		if err != nil {
+			return query, fmt.Errorf("invalid total %q", total)
+		}
+		query.CountTotal = countTotal
+	}
+
+	if currency := values.Get("currency"); currency != "" {
+		c, err := money.ParseCurrency(currency)
+		if err != nil {
+			return query, err
+		}
+		query.Currency = c
+	}
+
+	// Prices can only be compared in the same currency, so a price range
+	// only matches products in the currency of the range.
+	for name, price := range map[string]**money.Money{"minPrice": &query.MinPrice, "maxPrice": &query.MaxPrice} {
+		if value := values.Get(name); value != "" {
+			if query.Currency == "" {
+				query.Currency = defaultCurrency
+			}
+
+			p, err := money.Parse(value, query.Currency)
+			if err != nil || p.IsNegative() {
+				return query, fmt.Errorf("invalid %s %q", name, value)
+			}
+			*price = &p
+		}
+	}
+
+	if query.MinPrice != nil && query.MaxPrice != nil && query.MinPrice.Minor() > query.MaxPrice.Minor() {
+		return query, errors.New("minPrice is greater than maxPrice")
+	}
+
+	if cursor := values.Get("cursor"); cursor != "" {
+		after, err := model.DecodeCursor(cursor)
+		if err != nil {
+			return query, err
+		}
+
+		if (values.Has("sort") && after.Sort != query.Sort) || (values.Has("order") && after.Descending != query.Descending) {
+			return query, errors.New("sort and order do not match the cursor")
+		}
+
+		query.Sort, query.Descending, query.After = after.Sort, after.Descending, after
+	}
+
+	return query, nil
+}
+
 func (ctrl *DefaultController) GetProducts(w http.ResponseWriter, r *http.Request) {
-	products, err := ctrl.productRepository.FindAll()
+	query, err := parseQuery(r.URL.Query())
+	if err != nil {
+		problem.WriteStatus(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := ctrl.productRepository.Find(query)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		problem.WriteError(w, r, err)
 		return
 	}
 
 	w.Header().Add("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(products)
+	json.NewEncoder(w).Encode(page)
 }
 
-func (ctrl *DefaultController) PostProducts(w http.ResponseWriter, r *http.Request) {
-	var request createProductRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+type searchResponse struct {
+	Results []*model.SearchResult `json:"results"`
+}
+
+func (ctrl *DefaultController) SearchProducts(w http.ResponseWriter, r *http.Request) {
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if text == "" {
+		problem.WriteStatus(w, r, http.StatusBadRequest, "q is required")
 		return
 	}
 
-	if !request.isValid() {
-		w.WriteHeader(http.StatusBadRequest)
+	limit := model.DefaultLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > model.MaxLimit {
+			problem.WriteStatus(w, r, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", model.MaxLimit))
+			return
+		}
+		limit = n
+	}
+
+	results, err := ctrl.productRepository.Search(text, limit)
+	if err != nil {
+		problem.WriteError(w, r, err)
 		return
 	}
 
-	if err := ctrl.productRepository.Create([]*model.Product{{
+	w.Header().Add("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(searchResponse{results})
+}
+
+func (ctrl *DefaultController) PostProducts(w http.ResponseWriter, r *http.Request) {
+	request := createProductRequest{Price: money.New(0, defaultCurrency)}
+	if err := decodeRequest(r, &request); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	claims, _ := jwtauth.ClaimsFromContext(r.Context())
+
+	product := &model.Product{
 		Name:        request.Name,
 		Retailer:    request.Retailer,
 		Price:       request.Price,
 		Description: request.Description,
-	}}); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		OwnerId:     claims.String("sub"),
+	}
+
+	if err := product.Validate(); err != nil {
+		problem.WriteError(w, r, err)
 		return
 	}
+
+	if err := ctrl.productRepository.Create([]*model.Product{product}); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	productsCreated.Inc()
 }
 
 func (ctrl *DefaultController) GetProduct(w http.ResponseWriter, r *http.Request) {
-	productId := r.Context().Value("productid").(string)
-
-	id, err := strconv.ParseInt(productId, 10, 64)
+	id, err := router.ParamInt64(r, "productid")
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		problem.Write(w, r, errInvalidProductId)
 		return
 	}
 
 	product, err := ctrl.productRepository.FindById(id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		problem.WriteError(w, r, err)
 		return
 	}
 
@@ -90,43 +307,96 @@ func (ctrl *DefaultController) GetProduct(w http.ResponseWriter, r *http.Request
 }
 
 func (ctrl *DefaultController) PutProduct(w http.ResponseWriter, r *http.Request) {
-	productId := r.Context().Value("productid").(string)
+	id, err := router.ParamInt64(r, "productid")
+	if err != nil {
+		problem.Write(w, r, errInvalidProductId)
+		return
+	}
+
+	request := updateProductRequest{Price: money.New(0, defaultCurrency)}
+	if err := decodeRequest(r, &request); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
 
-	id, err := strconv.ParseInt(productId, 10, 64)
+	ctrl.update(w, r, id, request)
+}
+
+func (ctrl *DefaultController) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := router.ParamInt64(r, "productid")
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		problem.Write(w, r, errInvalidProductId)
 		return
 	}
 
-	var request updateProductRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+			w.Header().Set("Accept-Patch", mergePatchContentType)
+			problem.WriteStatus(w, r, http.StatusUnsupportedMediaType, "patches must be "+mergePatchContentType)
+			return
+		}
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		problem.WriteStatus(w, r, http.StatusBadRequest, "the patch is not a JSON object")
 		return
 	}
 
-	if err := ctrl.productRepository.Create([]*model.Product{{
+	product, err := ctrl.productRepository.FindById(id)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	request := updateProductRequest{
+		Name:        product.Name,
+		Retailer:    product.Retailer,
+		Price:       product.Price,
+		Description: product.Description,
+	}
+
+	if err := request.merge(patch); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	ctrl.update(w, r, id, request)
+}
+
+func (ctrl *DefaultController) update(w http.ResponseWriter, r *http.Request, id int64, request updateProductRequest) {
+	product := &model.Product{
 		ID:          id,
 		Name:        request.Name,
 		Retailer:    request.Retailer,
 		Price:       request.Price,
 		Description: request.Description,
-	}}); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	if err := product.Validate(); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	if err := ctrl.productRepository.Update(product); err != nil {
+		problem.WriteError(w, r, err)
 		return
 	}
+
+	w.Header().Add("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
 }
 
 func (ctrl *DefaultController) DeleteProduct(w http.ResponseWriter, r *http.Request) {
-	productId := r.Context().Value("productid").(string)
-
-	id, err := strconv.ParseInt(productId, 10, 64)
+	id, err := router.ParamInt64(r, "productid")
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		problem.Write(w, r, errInvalidProductId)
 		return
 	}
 
 	if err := ctrl.productRepository.Delete([]*model.Product{{ID: id}}); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		problem.WriteError(w, r, err)
 		return
 	}
 }
@@ -1,15 +1,19 @@
 package products
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strings"
 	"testing"
 
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/money"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
 	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/_mocks"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/products/model"
 	"github.com/stretchr/testify/assert"
@@ -30,7 +34,7 @@ func TestDefaultController(t *testing.T) {
 
 			productRepository.
 				EXPECT().
-				FindAll().
+				Find(gomock.Any()).
 				Return(nil, errors.New("query failed")).
 				Times(1)
 
@@ -41,15 +45,106 @@ func TestDefaultController(t *testing.T) {
 			assert.Equal(t, http.StatusInternalServerError, w.Code)
 		})
 
-		t.Run("should return all products", func(t *testing.T) {
+		t.Run("should return 400 BAD REQUEST if query is invalid", func(t *testing.T) {
+			cursor := model.Cursor{Sort: model.SortByPrice, Value: "9.99", Id: 1}.Encode()
+
+			tests := []string{
+				"sort=owner",
+				"order=up",
+				"limit=0",
+				"limit=101",
+				"limit=ten",
+				"minPrice=-1",
+				"maxPrice=cheap",
+				"minPrice=10&maxPrice=5",
+				"minPrice=9.999",
+				"minPrice=5.5&currency=JPY",
+				"currency=XYZ",
+				"total=maybe",
+				"cursor=invalid",
+				"cursor=" + cursor + "&sort=name",
+				"cursor=" + cursor + "&order=desc",
+				"cursor=" + model.Cursor{Sort: model.SortByPrice, Value: "'; drop", Id: 1}.Encode() + "&sort=price",
+				"cursor=" + model.Cursor{Sort: model.SortByPrice, Value: "1e5", Id: 1}.Encode() + "&sort=price",
+				"cursor=" + model.Cursor{Sort: model.SortByCreatedAt, Value: "yesterday", Id: 1}.Encode(),
+				"cursor=" + model.Cursor{Sort: model.SortByName, Value: "a\x00b", Id: 1}.Encode() + "&sort=name",
+			}
+
+			for _, test := range tests {
+				// given
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/v1/products?"+test, nil)
+
+				// when
+				controller.GetProducts(w, r)
+
+				// then
+				assert.Equal(t, http.StatusBadRequest, w.Code, test)
+			}
+		})
+
+		t.Run("should pass sorting and filters to the repository", func(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest("GET", "/api/v1/products", nil)
+			r := httptest.NewRequest("GET", "/api/v1/products?sort=price&order=desc&retailer=the+company&name=shirt&minPrice=5&maxPrice=20.5&limit=10", nil)
+
+			minPrice, maxPrice := money.New(500, money.EUR), money.New(2050, money.EUR)
+			productRepository.
+				EXPECT().
+				Find(model.Query{
+					Sort:       model.SortByPrice,
+					Descending: true,
+					Retailer:   "the company",
+					Name:       "shirt",
+					Currency:   money.EUR,
+					MinPrice:   &minPrice,
+					MaxPrice:   &maxPrice,
+					Limit:      10,
+				}).
+				Return(&model.Page{Products: []*model.Product{}}, nil).
+				Times(1)
+
+			// when
+			controller.GetProducts(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
+		t.Run("should continue after the cursor", func(t *testing.T) {
+			// given
+			cursor := model.Cursor{Sort: model.SortByName, Descending: true, Value: "shirt", Id: 7}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/v1/products?sort=name&cursor="+cursor.Encode(), nil)
+
+			productRepository.
+				EXPECT().
+				Find(model.Query{
+					Sort:       model.SortByName,
+					Descending: true,
+					Limit:      model.DefaultLimit,
+					After:      &cursor,
+				}).
+				Return(&model.Page{Products: []*model.Product{}}, nil).
+				Times(1)
+
+			// when
+			controller.GetProducts(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
 
+		t.Run("should return a page of products", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/v1/products?total=true", nil)
+
+			total := 21
 			productRepository.
 				EXPECT().
-				FindAll().
-				Return([]*model.Product{{ID: 999}}, nil).
+				Find(model.Query{Sort: model.SortByCreatedAt, Limit: model.DefaultLimit, CountTotal: true}).
+				Return(&model.Page{Products: []*model.Product{{ID: 999}}, NextCursor: "next", Total: &total}, nil).
 				Times(1)
 
 			// when
@@ -57,14 +152,90 @@ func TestDefaultController(t *testing.T) {
 
 			// then
 			res := w.Result()
-			var response []model.Product
+			var response model.Page
 			err := json.NewDecoder(res.Body).Decode(&response)
 
 			assert.NoError(t, err)
 			assert.Equal(t, http.StatusOK, w.Code)
 			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
-			assert.Len(t, response, 1)
-			assert.Equal(t, int64(999), response[0].ID)
+			assert.Len(t, response.Products, 1)
+			assert.Equal(t, int64(999), response.Products[0].ID)
+			assert.Equal(t, "next", response.NextCursor)
+			assert.Equal(t, 21, *response.Total)
+		})
+	})
+
+	t.Run("SearchProducts", func(t *testing.T) {
+		t.Run("should return 400 BAD REQUEST if query is invalid", func(t *testing.T) {
+			tests := []string{
+				"",
+				"q=",
+				"q=%20%20",
+				"q=shirt&limit=0",
+				"q=shirt&limit=101",
+			}
+
+			for _, test := range tests {
+				// given
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/v1/products/search?"+test, nil)
+
+				// when
+				controller.SearchProducts(w, r)
+
+				// then
+				assert.Equal(t, http.StatusBadRequest, w.Code, test)
+			}
+		})
+
+		t.Run("should return 500 INTERNAL SERVER ERROR if search failed", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/v1/products/search?q=shirt", nil)
+
+			productRepository.
+				EXPECT().
+				Search("shirt", model.DefaultLimit).
+				Return(nil, errors.New("database error")).
+				Times(1)
+
+			// when
+			controller.SearchProducts(w, r)
+
+			// then
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+		})
+
+		t.Run("should return ranked results", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/v1/products/search?q=+red+shi&limit=5", nil)
+
+			productRepository.
+				EXPECT().
+				Search("red shi", 5).
+				Return([]*model.SearchResult{{
+					Product:    model.Product{ID: 1, Name: "red shirt"},
+					Rank:       0.5,
+					Highlights: model.Highlights{Name: "<mark>red</mark> <mark>shirt</mark>"},
+				}}, nil).
+				Times(1)
+
+			// when
+			controller.SearchProducts(w, r)
+
+			// then
+			var response struct {
+				Results []model.SearchResult `json:"results"`
+			}
+			err := json.NewDecoder(w.Result().Body).Decode(&response)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Len(t, response.Results, 1)
+			assert.Equal(t, int64(1), response.Results[0].ID)
+			assert.Equal(t, "<mark>red</mark> <mark>shirt</mark>", response.Results[0].Highlights.Name)
 		})
 	})
 
@@ -88,26 +259,52 @@ func TestDefaultController(t *testing.T) {
 			}
 		})
 
-		t.Run("should return 400 BAD REQUEST if payload is incomplete", func(t *testing.T) {
-			tests := []io.Reader{
-				strings.NewReader(`{"price": 99.99}`),
-				strings.NewReader(`{"description": "amazing product"}`),
-				strings.NewReader(`{"retailer": "the company"}`),
+		t.Run("should return 422 UNPROCESSABLE ENTITY with invalid fields", func(t *testing.T) {
+			tests := []struct {
+				payload string
+				fields  []string
+			}{
+				{`{"price": 99.99}`, []string{"name", "retailer"}},
+				{`{"description": "amazing product"}`, []string{"name", "retailer"}},
+				{`{"retailer": "the company"}`, []string{"name"}},
+				{`{"name": "test product", "retailer": "the company", "price": -1}`, []string{"price"}},
+				{`{"name": "test product", "retailer": "the company", "price": "9.999"}`, []string{"price"}},
+				{`{"name": 42, "retailer": "the company"}`, []string{"name"}},
 			}
 
 			for _, test := range tests {
 				// given
 				w := httptest.NewRecorder()
-				r := httptest.NewRequest("POST", "/api/v1/products", test)
+				r := httptest.NewRequest("POST", "/api/v1/products", strings.NewReader(test.payload))
 
 				// when
 				controller.PostProducts(w, r)
 
 				// then
-				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.Equal(t, http.StatusUnprocessableEntity, w.Code, test.payload)
+				assert.Equal(t, test.fields, problemFields(t, w), test.payload)
 			}
 		})
 
+		t.Run("should return 409 CONFLICT if product exists", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v1/products",
+				strings.NewReader(`{"name":"test product","retailer":"the company"}`))
+
+			productRepository.
+				EXPECT().
+				Create(gomock.Any()).
+				Return(ErrConflict)
+
+			// when
+			controller.PostProducts(w, r)
+
+			// then
+			assert.Equal(t, http.StatusConflict, w.Code)
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		})
+
 		t.Run("should return 500 INTERNAL SERVER ERROR if persisting failed", func(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
@@ -116,7 +313,7 @@ func TestDefaultController(t *testing.T) {
 
 			productRepository.
 				EXPECT().
-				Create([]*model.Product{{Name: "test product", Retailer: "the company"}}).
+				Create([]*model.Product{{Name: "test product", Retailer: "the company", Price: money.New(0, money.EUR)}}).
 				Return(errors.New("database error"))
 
 			// when
@@ -134,7 +331,26 @@ func TestDefaultController(t *testing.T) {
 
 			productRepository.
 				EXPECT().
-				Create([]*model.Product{{Name: "test product", Retailer: "the company"}}).
+				Create([]*model.Product{{Name: "test product", Retailer: "the company", Price: money.New(0, money.EUR)}}).
+				Return(nil)
+
+			// when
+			controller.PostProducts(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
+		t.Run("should set authenticated user as owner", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v1/products",
+				strings.NewReader(`{"name":"test product","retailer":"the company"}`))
+			r = r.WithContext(jwtauth.NewContext(r.Context(), jwtauth.Claims{"sub": "42", "role": "retailer"}))
+
+			productRepository.
+				EXPECT().
+				Create([]*model.Product{{Name: "test product", Retailer: "the company", Price: money.New(0, money.EUR), OwnerId: "42"}}).
 				Return(nil)
 
 			// when
@@ -150,7 +366,7 @@ func TestDefaultController(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("GET", "/api/v1/products/aaa", nil)
-			r = r.WithContext(context.WithValue(r.Context(), "productid", "aaa"))
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "aaa"})
 
 			// when
 			controller.GetProduct(w, r)
@@ -159,11 +375,40 @@ func TestDefaultController(t *testing.T) {
 			assert.Equal(t, http.StatusBadRequest, w.Code)
 		})
 
+		t.Run("should return 404 NOT FOUND if product does not exist", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/v1/products/1", nil)
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
+
+			productRepository.
+				EXPECT().
+				FindById(int64(1)).
+				Return(nil, ErrNotFound)
+
+			// when
+			controller.GetProduct(w, r)
+
+			// then
+			var response problem.Problem
+			err := json.NewDecoder(w.Body).Decode(&response)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, problem.Problem{
+				Title:    "Not Found",
+				Status:   http.StatusNotFound,
+				Detail:   "product not found",
+				Instance: "/api/v1/products/1",
+			}, response)
+		})
+
 		t.Run("should return 500 INTERNAL SERVER ERROR query failed", func(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("GET", "/api/v1/products/1", nil)
-			r = r.WithContext(context.WithValue(r.Context(), "productid", "1"))
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
 
 			productRepository.
 				EXPECT().
@@ -181,7 +426,7 @@ func TestDefaultController(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("GET", "/api/v1/products/1", nil)
-			r = r.WithContext(context.WithValue(r.Context(), "productid", "1"))
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
 
 			productRepository.
 				EXPECT().
@@ -209,7 +454,7 @@ func TestDefaultController(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("PUT", "/api/v1/products/aaa", nil)
-			r = r.WithContext(context.WithValue(r.Context(), "productid", "aaa"))
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "aaa"})
 
 			// when
 			controller.PutProduct(w, r)
@@ -228,7 +473,7 @@ func TestDefaultController(t *testing.T) {
 				// given
 				w := httptest.NewRecorder()
 				r := httptest.NewRequest("PUT", "/api/v1/products/1", test)
-				r = r.WithContext(context.WithValue(r.Context(), "productid", "1"))
+				r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
 
 				// when
 				controller.PutProduct(w, r)
@@ -238,16 +483,58 @@ func TestDefaultController(t *testing.T) {
 			}
 		})
 
+		t.Run("should return 422 UNPROCESSABLE ENTITY if payload is invalid", func(t *testing.T) {
+			tests := []io.Reader{
+				strings.NewReader(`{"id": 999}`),
+				strings.NewReader(`{"name":"test product"}`),
+				strings.NewReader(`{"name":"test product","retailer":"the company","price":-1}`),
+				strings.NewReader(`{"name":"test product","retailer":"the company","price":{"amount":"9.999","currency":"EUR"}}`),
+				strings.NewReader(`{"name":"test product","retailer":"the company","price":{"amount":"9.99","currency":"XYZ"}}`),
+			}
+
+			for _, test := range tests {
+				// given
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("PUT", "/api/v1/products/1", test)
+				r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
+
+				// when
+				controller.PutProduct(w, r)
+
+				// then
+				assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			}
+		})
+
+		t.Run("should return 404 NOT FOUND if product does not exist", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PUT", "/api/v1/products/1",
+				strings.NewReader(`{"name":"test product","retailer":"the company"}`))
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
+
+			productRepository.
+				EXPECT().
+				Update(&model.Product{ID: 1, Name: "test product", Retailer: "the company", Price: money.New(0, money.EUR)}).
+				Return(ErrNotFound)
+
+			// when
+			controller.PutProduct(w, r)
+
+			// then
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		})
+
 		t.Run("should return 500 INTERNAL SERVER ERROR if query failed", func(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("PUT", "/api/v1/products/1",
-				strings.NewReader(`{"id": 999}`))
-			r = r.WithContext(context.WithValue(r.Context(), "productid", "1"))
+				strings.NewReader(`{"name":"test product","retailer":"the company"}`))
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
 
 			productRepository.
 				EXPECT().
-				Create([]*model.Product{{ID: 1}}).
+				Update(&model.Product{ID: 1, Name: "test product", Retailer: "the company", Price: money.New(0, money.EUR)}).
 				Return(errors.New("database error"))
 
 			// when
@@ -257,21 +544,223 @@ func TestDefaultController(t *testing.T) {
 			assert.Equal(t, http.StatusInternalServerError, w.Code)
 		})
 
-		t.Run("should update one product", func(t *testing.T) {
+		t.Run("should replace product and return it", func(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("PUT", "/api/v1/products/1",
-				strings.NewReader(`{"id": 999}`))
-			r = r.WithContext(context.WithValue(r.Context(), "productid", "1"))
+				strings.NewReader(`{"id":999,"name":"test product","retailer":"the company","price":9.99}`))
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
 
 			productRepository.
 				EXPECT().
-				Create([]*model.Product{{ID: 1}}).
-				Return(nil)
+				Update(&model.Product{ID: 1, Name: "test product", Retailer: "the company", Price: money.New(999, money.EUR)}).
+				DoAndReturn(func(product *model.Product) error {
+					product.OwnerId = "42"
+					return nil
+				})
 
 			// when
 			controller.PutProduct(w, r)
 
+			// then
+			var response model.Product
+			err := json.NewDecoder(w.Body).Decode(&response)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Equal(t, model.Product{ID: 1, Name: "test product", Retailer: "the company", Price: money.New(999, money.EUR), OwnerId: "42"}, response)
+		})
+	})
+
+	t.Run("PatchProduct", func(t *testing.T) {
+		existing := func() *model.Product {
+			return &model.Product{ID: 1, Name: "test product", Retailer: "the company", Price: money.New(999, money.EUR), Description: "old", OwnerId: "42"}
+		}
+
+		newRequest := func(body string) *http.Request {
+			r := httptest.NewRequest("PATCH", "/api/v1/products/1", strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/merge-patch+json")
+			return router.WithParams(r, router.Param{Key: "productid", Value: "1"})
+		}
+
+		t.Run("should return 400 BAD REQUEST if product id is not numerical", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PATCH", "/api/v1/products/aaa", nil)
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "aaa"})
+
+			// when
+			controller.PatchProduct(w, r)
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should return 415 UNSUPPORTED MEDIA TYPE for other content types", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := newRequest(`{"name":"new name"}`)
+			r.Header.Set("Content-Type", "application/json-patch+json")
+
+			// when
+			controller.PatchProduct(w, r)
+
+			// then
+			assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+			assert.Equal(t, "application/merge-patch+json", w.Header().Get("Accept-Patch"))
+		})
+
+		t.Run("should return 400 BAD REQUEST if patch is no json object", func(t *testing.T) {
+			tests := []string{``, `{"invalid`, `null`, `[]`, `"name"`}
+
+			for _, test := range tests {
+				// given
+				w := httptest.NewRecorder()
+				r := newRequest(test)
+
+				// when
+				controller.PatchProduct(w, r)
+
+				// then
+				assert.Equal(t, http.StatusBadRequest, w.Code, test)
+			}
+		})
+
+		t.Run("should return 404 NOT FOUND if product does not exist", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := newRequest(`{"name":"new name"}`)
+
+			productRepository.
+				EXPECT().
+				FindById(int64(1)).
+				Return(nil, ErrNotFound)
+
+			// when
+			controller.PatchProduct(w, r)
+
+			// then
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		})
+
+		t.Run("should return 422 UNPROCESSABLE ENTITY if patched product is invalid", func(t *testing.T) {
+			tests := []struct {
+				patch  string
+				fields []string
+			}{
+				{`{"name":null}`, []string{"name"}},
+				{`{"retailer":""}`, []string{"retailer"}},
+				{`{"price":-1}`, []string{"price"}},
+				{`{"price":"cheap"}`, []string{"price"}},
+				{`{"price":"9.999"}`, []string{"price"}},
+				{`{"price":{"currency":"XYZ"}}`, []string{"price"}},
+				{`{"name":false}`, []string{"name"}},
+				{`{"ownerId":"43"}`, []string{"ownerId"}},
+				{`{"id":2}`, []string{"id"}},
+			}
+
+			for _, test := range tests {
+				// given
+				w := httptest.NewRecorder()
+				r := newRequest(test.patch)
+
+				productRepository.
+					EXPECT().
+					FindById(int64(1)).
+					Return(existing(), nil)
+
+				// when
+				controller.PatchProduct(w, r)
+
+				// then
+				assert.Equal(t, http.StatusUnprocessableEntity, w.Code, test.patch)
+				assert.Equal(t, test.fields, problemFields(t, w), test.patch)
+			}
+		})
+
+		t.Run("should merge patch into product and return it", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := newRequest(`{"price":19.99,"description":null}`)
+
+			productRepository.
+				EXPECT().
+				FindById(int64(1)).
+				Return(existing(), nil)
+
+			productRepository.
+				EXPECT().
+				Update(&model.Product{ID: 1, Name: "test product", Retailer: "the company", Price: money.New(1999, money.EUR)}).
+				DoAndReturn(func(product *model.Product) error {
+					product.OwnerId = "42"
+					return nil
+				})
+
+			// when
+			controller.PatchProduct(w, r)
+
+			// then
+			var response model.Product
+			err := json.NewDecoder(w.Body).Decode(&response)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, model.Product{ID: 1, Name: "test product", Retailer: "the company", Price: money.New(1999, money.EUR), OwnerId: "42"}, response)
+		})
+
+		t.Run("should keep currency unless it is patched", func(t *testing.T) {
+			tests := []struct {
+				patch    string
+				expected money.Money
+			}{
+				{`{"price":{"amount":"19.99"}}`, money.New(1999, money.EUR)},
+				{`{"price":null}`, money.New(0, money.EUR)},
+				{`{"price":{"amount":"1500","currency":"JPY"}}`, money.New(1500, money.JPY)},
+			}
+
+			for _, test := range tests {
+				// given
+				w := httptest.NewRecorder()
+				r := newRequest(test.patch)
+
+				productRepository.
+					EXPECT().
+					FindById(int64(1)).
+					Return(existing(), nil)
+
+				productRepository.
+					EXPECT().
+					Update(&model.Product{ID: 1, Name: "test product", Retailer: "the company", Price: test.expected, Description: "old"}).
+					Return(nil)
+
+				// when
+				controller.PatchProduct(w, r)
+
+				// then
+				assert.Equal(t, http.StatusOK, w.Code, test.patch)
+			}
+		})
+
+		t.Run("should accept application/json", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := newRequest(`{"name":"new name"}`)
+			r.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+			productRepository.
+				EXPECT().
+				FindById(int64(1)).
+				Return(existing(), nil)
+
+			productRepository.
+				EXPECT().
+				Update(gomock.Any()).
+				Return(nil)
+
+			// when
+			controller.PatchProduct(w, r)
+
 			// then
 			assert.Equal(t, http.StatusOK, w.Code)
 		})
@@ -282,7 +771,7 @@ func TestDefaultController(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("DELETE", "/api/v1/products/aaa", nil)
-			r = r.WithContext(context.WithValue(r.Context(), "productid", "aaa"))
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "aaa"})
 
 			// when
 			controller.DeleteProduct(w, r)
@@ -295,7 +784,7 @@ func TestDefaultController(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("DELETE", "/api/v1/products/1", nil)
-			r = r.WithContext(context.WithValue(r.Context(), "productid", "1"))
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
 
 			productRepository.
 				EXPECT().
@@ -309,11 +798,30 @@ func TestDefaultController(t *testing.T) {
 			assert.Equal(t, http.StatusInternalServerError, w.Code)
 		})
 
+		t.Run("should return 404 NOT FOUND if product does not exist", func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("DELETE", "/api/v1/products/1", nil)
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
+
+			productRepository.
+				EXPECT().
+				Delete([]*model.Product{{ID: 1}}).
+				Return(ErrNotFound)
+
+			// when
+			controller.DeleteProduct(w, r)
+
+			// then
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		})
+
 		t.Run("should return 200 OK", func(t *testing.T) {
 			// given
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("DELETE", "/api/v1/products/1", nil)
-			r = r.WithContext(context.WithValue(r.Context(), "productid", "1"))
+			r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
 
 			productRepository.
 				EXPECT().
@@ -328,3 +836,19 @@ func TestDefaultController(t *testing.T) {
 		})
 	})
 }
+
+// problemFields returns the names of the invalid fields of a problem response.
+func problemFields(t *testing.T, w *httptest.ResponseRecorder) []string {
+	var response problem.Problem
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("could not decode problem: %s", err.Error())
+	}
+
+	fields := make([]string, len(response.Errors))
+	for i, field := range response.Errors {
+		fields[i] = field.Field
+	}
+
+	sort.Strings(fields)
+	return fields
+}
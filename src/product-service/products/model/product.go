@@ -1,9 +1,39 @@
 package model
 
+import (
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/money"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+)
+
 type Product struct {
-	ID          int64   `json:"id"`
-	Name        string  `json:"name"`
-	Retailer    string  `json:"retailer"`
-	Price       float32 `json:"price"`
-	Description string  `json:"description"`
+	ID          int64       `json:"id"`
+	Name        string      `json:"name"`
+	Retailer    string      `json:"retailer"`
+	Price       money.Money `json:"price"`
+	Description string      `json:"description"`
+	OwnerId     string      `json:"ownerId"`
+	CreatedAt   time.Time   `json:"createdAt"`
+}
+
+// Validate returns a problem.ValidationError listing the invalid fields.
+func (product *Product) Validate() error {
+	var validation problem.ValidationError
+
+	if product.Name == "" {
+		validation.Add("name", "is required")
+	}
+
+	if product.Retailer == "" {
+		validation.Add("retailer", "is required")
+	}
+
+	if !product.Price.Currency().IsValid() {
+		validation.Add("price", "needs a currency")
+	} else if product.Price.IsNegative() {
+		validation.Add("price", "must not be negative")
+	}
+
+	return validation.Err()
 }
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/money"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type SortKey string
+
+const (
+	SortByName      SortKey = "name"
+	SortByPrice     SortKey = "price"
+	SortByCreatedAt SortKey = "createdAt"
+)
+
+func (key SortKey) IsValid() bool {
+	switch key {
+	case SortByName, SortByPrice, SortByCreatedAt:
+		return true
+	}
+
+	return false
+}
+
+// Query selects one page of products. Without a cursor the first page is
+// returned. Counting all matching products needs a scan of all of them, so
+// the total is only counted if asked for.
+type Query struct {
+	Sort       SortKey
+	Descending bool
+	Retailer   string
+	Name       string
+	Currency   money.Currency
+	MinPrice   *money.Money
+	MaxPrice   *money.Money
+	Limit      int
+	After      *Cursor
+	CountTotal bool
+}
+
+type Page struct {
+	Products   []*Product `json:"products"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+	Total      *int       `json:"total,omitempty"`
+}
+
+// Cursor points behind the last product of a page. It contains the sort key
+// value and id of that product, so the next page starts right after it even
+// if products were added or removed in the meantime.
+type Cursor struct {
+	Sort       SortKey `json:"s"`
+	Descending bool    `json:"d,omitempty"`
+	Value      string  `json:"v"`
+	Id         int64   `json:"id"`
+}
+
+func (cursor Cursor) Encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || !cursor.Sort.IsValid() || !cursor.hasValidValue() {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+var decimalPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// hasValidValue checks the value against the sort key, since it is cast to
+// the type of the sort column in the database.
+func (cursor Cursor) hasValidValue() bool {
+	switch cursor.Sort {
+	case SortByPrice:
+		return decimalPattern.MatchString(cursor.Value)
+	case SortByCreatedAt:
+		_, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		return err == nil
+	}
+
+	return utf8.ValidString(cursor.Value) && !strings.ContainsRune(cursor.Value, 0)
+}
@@ -0,0 +1,14 @@
+package model
+
+// SearchResult is a product found by a full-text search. Highlights contain
+// HTML escaped text with matching words wrapped in <mark> elements.
+type SearchResult struct {
+	Product
+	Rank       float32    `json:"rank"`
+	Highlights Highlights `json:"highlights"`
+}
+
+type Highlights struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,33 @@
+package products
+
+import (
+	"net/http"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/authz"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+)
+
+// OwnerPolicy allows the request if the caller owns the product of the
+// productid route parameter. A missing product is reported as ErrNotFound,
+// which authz.Require answers with 404 Not Found.
+func OwnerPolicy(productRepository Repository) authz.Policy {
+	return func(r *http.Request, claims jwtauth.Claims) (bool, error) {
+		subject := claims.String("sub")
+		if subject == "" {
+			return false, nil
+		}
+
+		id, err := router.ParamInt64(r, "productid")
+		if err != nil {
+			return false, nil
+		}
+
+		product, err := productRepository.FindById(id)
+		if err != nil {
+			return false, err
+		}
+
+		return product.OwnerId == subject, nil
+	}
+}
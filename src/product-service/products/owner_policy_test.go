@@ -0,0 +1,90 @@
+package products
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/_mocks"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/products/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOwnerPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	productRepository := mocks.NewMockRepository(ctrl)
+	policy := OwnerPolicy(productRepository)
+
+	r := httptest.NewRequest("PUT", "/api/v1/products/1", nil)
+	r = router.WithParams(r, router.Param{Key: "productid", Value: "1"})
+
+	t.Run("should deny request without subject", func(t *testing.T) {
+		// given
+		claims := jwtauth.Claims{"role": "retailer"}
+
+		// when
+		allowed, err := policy(r, claims)
+
+		// then
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("should return ErrNotFound if product does not exist", func(t *testing.T) {
+		// given
+		productRepository.
+			EXPECT().
+			FindById(int64(1)).
+			Return(nil, ErrNotFound)
+
+		// when
+		allowed, err := policy(r, jwtauth.Claims{"sub": "42"})
+
+		// then
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.False(t, allowed)
+	})
+
+	t.Run("should return error if query failed", func(t *testing.T) {
+		// given
+		productRepository.
+			EXPECT().
+			FindById(int64(1)).
+			Return(nil, errors.New("database error"))
+
+		// when
+		_, err := policy(r, jwtauth.Claims{"sub": "42"})
+
+		// then
+		assert.Error(t, err)
+	})
+
+	t.Run("should only allow owner of the product", func(t *testing.T) {
+		tests := []struct {
+			subject string
+			allowed bool
+		}{
+			{"42", true},
+			{"43", false},
+		}
+
+		for _, test := range tests {
+			// given
+			productRepository.
+				EXPECT().
+				FindById(int64(1)).
+				Return(&model.Product{ID: 1, OwnerId: "42"}, nil)
+
+			// when
+			allowed, err := policy(r, jwtauth.Claims{"sub": test.subject})
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, test.allowed, allowed)
+		}
+	})
+}
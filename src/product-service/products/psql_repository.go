@@ -1,14 +1,20 @@
 package products
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"html"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/database"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/money"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/products/model"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type PsqlRepository struct {
@@ -24,44 +30,219 @@ func NewPsqlRepository(config database.Config) (*PsqlRepository, error) {
 	return &PsqlRepository{db}, nil
 }
 
+func (repo *PsqlRepository) Ping(ctx context.Context) error {
+	return repo.db.PingContext(ctx)
+}
+
+func (repo *PsqlRepository) Stats() sql.DBStats {
+	return repo.db.Stats()
+}
+
+func (repo *PsqlRepository) Close() error {
+	return repo.db.Close()
+}
+
 const createProductsTable = `
 create table if not exists products (
 	id          serial  primary key,
 	name        text    not null,
 	retailer    text    not null,
 	price       decimal not null default 0,
-	description text             default ''
-)
+	currency    char(3) not null default 'EUR',
+	description text             default '',
+	owner_id    text    not null default ''
+);
+alter table products add column if not exists owner_id text not null default '';
+alter table products add column if not exists created_at timestamptz not null default now();
+alter table products add column if not exists currency char(3) not null default 'EUR';
+create index if not exists products_name_id_idx on products (name, id);
+create index if not exists products_price_id_idx on products (price, id);
+create index if not exists products_created_at_id_idx on products (created_at, id);
+alter table products add column if not exists search tsvector generated always as (
+	setweight(to_tsvector('simple', name), 'A') ||
+	setweight(to_tsvector('simple', coalesce(description, '')), 'B') ||
+	setweight(to_tsvector('simple', retailer), 'C')
+) stored;
+create index if not exists products_search_idx on products using gin (search);
+create index if not exists products_name_trgm_idx on products using gin (name gin_trgm_ops);
+create table if not exists product_migrations (
+	name       text        primary key,
+	applied_at timestamptz not null default now()
+);
+`
+
+const hasTrgmExtensionQuery = `
+select exists (select 1 from pg_extension where extname = 'pg_trgm')
 `
 
+// Migrate creates the products table and updates older ones. Prices used to
+// be written from float32 and could hold values like 9.989999771118164, which
+// are rounded to the minor unit of their currency once.
+//
+// The search needs the pg_trgm extension, which has to be created before by
+// a superuser with sql/extensions.sql.
 func (repo *PsqlRepository) Migrate() error {
-	_, err := repo.db.Exec(createProductsTable)
-	return err
+	var hasTrgm bool
+	if err := repo.db.QueryRow(hasTrgmExtensionQuery).Scan(&hasTrgm); err != nil {
+		return err
+	}
+	if !hasTrgm {
+		return errors.New("the pg_trgm extension is missing, create it as a superuser with sql/extensions.sql")
+	}
+
+	if _, err := repo.db.Exec(createProductsTable); err != nil {
+		return err
+	}
+
+	return repo.migrateOnce("round-float-prices", roundPricesQuery)
+}
+
+const recordMigrationQuery = `
+insert into product_migrations (name) values ($1) on conflict (name) do nothing
+`
+
+// migrateOnce runs a data migration unless it is recorded in
+// product_migrations. The record is written in the same transaction, so
+// instances starting at the same time wait for each other and the migration
+// runs only once.
+func (repo *PsqlRepository) migrateOnce(name string, query string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(recordMigrationQuery, name)
+	if err != nil {
+		return err
+	}
+
+	recorded, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if recorded == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(query); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+var roundPricesQuery = fmt.Sprintf(`
+update products set price = round(price, %[1]s) where price <> round(price, %[1]s)
+`, currencyDigits())
+
+// currencyDigits returns an SQL expression for the decimal places of the
+// currency column.
+func currencyDigits() string {
+	var digits strings.Builder
+	digits.WriteString("case currency")
+	for _, currency := range money.Currencies() {
+		if currency.Digits() != 2 {
+			fmt.Fprintf(&digits, " when '%s' then %d", currency, currency.Digits())
+		}
+	}
+	digits.WriteString(" else 2 end")
+
+	return digits.String()
 }
 
 const createProductsBatchQuery = `
-insert into products (name, retailer, price, description) values %s
+insert into products (name, retailer, price, currency, description, owner_id) values %s
 `
 
+// mapError translates constraint violations into the errors of the
+// repository.
+func mapError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+		return ErrConflict
+	}
+
+	return err
+}
+
 func (repo *PsqlRepository) Create(products []*model.Product) error {
+	for _, product := range products {
+		if err := product.Validate(); err != nil {
+			return err
+		}
+	}
+
 	placeholders := make([]string, len(products))
-	values := make([]interface{}, len(products)*4)
+	values := make([]interface{}, len(products)*6)
 
 	for i := 0; i < len(products); i++ {
-		placeholders[i] = fmt.Sprintf("($%d,$%d,$%d,$%d)", i*4+1, i*4+2, i*4+3, i*4+4)
-		values[i*4+0] = products[i].Name
-		values[i*4+1] = products[i].Retailer
-		values[i*4+2] = products[i].Price
-		values[i*4+3] = products[i].Description
+		placeholders[i] = fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d)", i*6+1, i*6+2, i*6+3, i*6+4, i*6+5, i*6+6)
+		values[i*6+0] = products[i].Name
+		values[i*6+1] = products[i].Retailer
+		values[i*6+2] = products[i].Price.Decimal()
+		values[i*6+3] = products[i].Price.Currency()
+		values[i*6+4] = products[i].Description
+		values[i*6+5] = products[i].OwnerId
 	}
 
 	query := fmt.Sprintf(createProductsBatchQuery, strings.Join(placeholders, ","))
 	_, err := repo.db.Exec(query, values...)
-	return err
+	return mapError(err)
+}
+
+const productColumns = `id, name, retailer, price, currency, description, owner_id, created_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanProduct scans the product columns and then the extra columns into
+// extra.
+func scanProduct(row scanner, extra ...any) (*model.Product, error) {
+	var product model.Product
+	var price, currency string
+
+	dest := []any{
+		&product.ID,
+		&product.Name,
+		&product.Retailer,
+		&price,
+		&currency,
+		&product.Description,
+		&product.OwnerId,
+		&product.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if product.Price, err = money.Parse(price, money.Currency(currency)); err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func scanProducts(rows *sql.Rows) ([]*model.Product, error) {
+	defer rows.Close()
+
+	products := []*model.Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
 }
 
 const findAllProductsQuery = `
-select id, name, retailer, price, description from products
+select ` + productColumns + ` from products
 `
 
 func (repo *PsqlRepository) FindAll() ([]*model.Product, error) {
@@ -70,38 +251,274 @@ func (repo *PsqlRepository) FindAll() ([]*model.Product, error) {
 		return nil, err
 	}
 
-	var products []*model.Product
+	return scanProducts(rows)
+}
+
+type sortColumn struct {
+	name string
+	cast string
+}
+
+var sortColumns = map[model.SortKey]sortColumn{
+	model.SortByName:      {"name", "text"},
+	model.SortByPrice:     {"price", "decimal"},
+	model.SortByCreatedAt: {"created_at", "timestamptz"},
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// queryFilter returns the where clause for the filters of the query, without the
+// cursor, together with its arguments.
+func queryFilter(query model.Query) (string, []any) {
+	var conditions []string
+	var args []any
+
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.Retailer != "" {
+		add("retailer = $%d", query.Retailer)
+	}
+	if query.Name != "" {
+		add("name ilike '%%' || $%d || '%%'", likeEscaper.Replace(query.Name))
+	}
+	if query.Currency != "" {
+		add("currency = $%d", query.Currency)
+	}
+	if query.MinPrice != nil {
+		add("price >= $%d::decimal", query.MinPrice.Decimal())
+	}
+	if query.MaxPrice != nil {
+		add("price <= $%d::decimal", query.MaxPrice.Decimal())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return " where " + strings.Join(conditions, " and "), args
+}
+
+func cursorValue(product *model.Product, sort model.SortKey) string {
+	switch sort {
+	case model.SortByName:
+		return product.Name
+	case model.SortByPrice:
+		return product.Price.Decimal()
+	default:
+		return product.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// Find returns one page of products. Pages are keyset paginated on the sort
+// column and id, which stays stable while products are added or removed.
+func (repo *PsqlRepository) Find(query model.Query) (*model.Page, error) {
+	if query.After != nil {
+		query.Sort, query.Descending = query.After.Sort, query.After.Descending
+	}
+	if !query.Sort.IsValid() {
+		query.Sort = model.SortByCreatedAt
+	}
+	if query.Limit <= 0 {
+		query.Limit = model.DefaultLimit
+	}
+	if query.Limit > model.MaxLimit {
+		query.Limit = model.MaxLimit
+	}
+
+	column := sortColumns[query.Sort]
+	filter, args := queryFilter(query)
+
+	var total *int
+	if query.CountTotal {
+		total = new(int)
+		if err := repo.db.QueryRow(`select count(*) from products`+filter, args...).Scan(total); err != nil {
+			return nil, err
+		}
+	}
+
+	direction, comparison := "asc", ">"
+	if query.Descending {
+		direction, comparison = "desc", "<"
+	}
+
+	where := filter
+	if query.After != nil {
+		keyset := fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)", column.name, comparison, len(args)+1, column.cast, len(args)+2)
+		args = append(args, query.After.Value, query.After.Id)
+
+		if where == "" {
+			where = " where " + keyset
+		} else {
+			where += " and " + keyset
+		}
+	}
+
+	args = append(args, query.Limit+1)
+	rows, err := repo.db.Query(fmt.Sprintf(
+		"select %s from products%s order by %s %s, id %s limit $%d",
+		productColumns, where, column.name, direction, direction, len(args),
+	), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := scanProducts(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.Page{Products: products, Total: total}
+	if len(products) > query.Limit {
+		page.Products = products[:query.Limit]
+
+		last := page.Products[query.Limit-1]
+		page.NextCursor = model.Cursor{
+			Sort:       query.Sort,
+			Descending: query.Descending,
+			Value:      cursorValue(last, query.Sort),
+			Id:         last.ID,
+		}.Encode()
+	}
+
+	return page, nil
+}
+
+// Matches are marked with control characters by ts_headline, so the text can
+// be HTML escaped before they are replaced by <mark> elements.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+var highlighter = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+func highlight(text string) string {
+	return highlighter.Replace(html.EscapeString(text))
+}
+
+const (
+	nameHighlightOptions        = "StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", HighlightAll=true"
+	descriptionHighlightOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", MaxFragments=2, MinWords=5, MaxWords=20, FragmentDelimiter=\" … \""
+)
+
+// prefixQuery turns the words of text into a tsquery which matches all of
+// them as prefixes, e.g. "red shi" becomes 'red':* & 'shi':*.
+func prefixQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = "'" + word + "':*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+// searchProductsQuery finds products whose words start with all words of the
+// search text, or whose name is similar to it to catch typos.
+const searchProductsQuery = `
+select ` + productColumns + `,
+	ts_rank_cd(search, tsq) + word_similarity($2, name) as rank,
+	ts_headline('simple', name, tsq, $4),
+	ts_headline('simple', coalesce(description, ''), tsq, $5)
+from products, to_tsquery('simple', $1) tsq
+where search @@ tsq or $2 <% name
+order by rank desc, id
+limit $3
+`
+
+func (repo *PsqlRepository) Search(text string, limit int) ([]*model.SearchResult, error) {
+	results := []*model.SearchResult{}
+
+	tsquery := prefixQuery(text)
+	if tsquery == "" {
+		return results, nil
+	}
+
+	if limit <= 0 {
+		limit = model.DefaultLimit
+	}
+	if limit > model.MaxLimit {
+		limit = model.MaxLimit
+	}
+
+	rows, err := repo.db.Query(searchProductsQuery, tsquery, strings.TrimSpace(text), limit, nameHighlightOptions, descriptionHighlightOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
 	for rows.Next() {
-		var product model.Product
-		if err := rows.Scan(&product.ID, &product.Name, &product.Retailer, &product.Price, &product.Description); err != nil {
+		var result model.SearchResult
+		product, err := scanProduct(rows, &result.Rank, &result.Highlights.Name, &result.Highlights.Description)
+		if err != nil {
 			return nil, err
 		}
 
-		products = append(products, &product)
+		result.Product = *product
+		result.Highlights.Name = highlight(result.Highlights.Name)
+		result.Highlights.Description = highlight(result.Highlights.Description)
+		results = append(results, &result)
 	}
 
-	return products, nil
+	return results, rows.Err()
 }
 
 const findProductByIdQuery = `
-select id, name, retailer, price, description from products where id = $1 limit 1
+select ` + productColumns + ` from products where id = $1 limit 1
 `
 
 func (repo *PsqlRepository) FindById(id int64) (*model.Product, error) {
-	row := repo.db.QueryRow(findProductByIdQuery, id)
+	product, err := scanProduct(repo.db.QueryRow(findProductByIdQuery, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
 
-	var product model.Product
-	if err := row.Scan(&product.ID, &product.Name, &product.Retailer, &product.Price, &product.Description); err != nil {
-		return nil, err
+	return product, err
+}
+
+const updateProductQuery = `
+update products set name = $2, retailer = $3, price = $4, currency = $5, description = $6
+where id = $1
+returning owner_id, created_at
+`
+
+// Update replaces the product with the same id. The owner is never changed,
+// it is read back into the product instead.
+func (repo *PsqlRepository) Update(product *model.Product) error {
+	if err := product.Validate(); err != nil {
+		return err
 	}
 
-	return &product, nil
+	row := repo.db.QueryRow(updateProductQuery,
+		product.ID,
+		product.Name,
+		product.Retailer,
+		product.Price.Decimal(),
+		product.Price.Currency(),
+		product.Description,
+	)
+
+	if err := row.Scan(&product.OwnerId, &product.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+
+		return mapError(err)
+	}
+
+	return nil
 }
 
 const deleteProductsByIdQuery = `
 delete from products where id in (%s)
 `
 
+// Delete returns ErrNotFound if none of the products existed.
 func (repo *PsqlRepository) Delete(products []*model.Product) error {
 	placeholders := make([]string, len(products))
 	ids := make([]interface{}, len(products))
@@ -112,6 +529,19 @@ func (repo *PsqlRepository) Delete(products []*model.Product) error {
 	}
 
 	query := fmt.Sprintf(deleteProductsByIdQuery, strings.Join(placeholders, ","))
-	_, err := repo.db.Exec(query, ids...)
-	return err
+	result, err := repo.db.Exec(query, ids...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
 }
@@ -3,10 +3,12 @@ package products
 import (
 	"context"
 	"database/sql"
+	"os"
 	"testing"
 
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/containerhelpers"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/database"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/money"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/products/model"
 	"github.com/stretchr/testify/assert"
 
@@ -40,6 +42,14 @@ func TestIntegrationPsqlRepository(t *testing.T) {
 	}
 	t.Cleanup(clearTables(t, repository.db))
 
+	extensions, err := os.ReadFile("../sql/extensions.sql")
+	if err != nil {
+		t.Fatalf("could not read extensions: %s", err.Error())
+	}
+	if _, err := repository.db.Exec(string(extensions)); err != nil {
+		t.Fatalf("could not create extensions: %s", err.Error())
+	}
+
 	t.Run("Migrate", func(t *testing.T) {
 		t.Run("should create products table", func(t *testing.T) {
 			t.Cleanup(clearTables(t, repository.db))
@@ -50,7 +60,53 @@ func TestIntegrationPsqlRepository(t *testing.T) {
 
 			// then
 			assert.NoError(t, err)
-			assertTableExists(t, repository.db, "products", []string{"id", "name", "retailer", "price", "description"})
+			assertTableExists(t, repository.db, "products", []string{"id", "name", "retailer", "price", "currency", "description", "owner_id", "created_at"})
+		})
+
+		t.Run("should round prices written from floats", func(t *testing.T) {
+			t.Cleanup(clearTables(t, repository.db))
+
+			// given
+			_, err := repository.db.Exec(`delete from product_migrations`)
+			assert.NoError(t, err)
+			_, err = repository.db.Exec(`insert into products (name, retailer, price, currency) values
+				('legacy product', 'the company', 9.989999771118164, 'EUR'),
+				('legacy yen product', 'the company', 1200.0000001, 'JPY')`)
+			assert.NoError(t, err)
+
+			// when
+			err = repository.Migrate()
+
+			// then
+			assert.NoError(t, err)
+
+			product, err := repository.FindById(getProductFromDatabase(t, repository.db, "legacy product").ID)
+			assert.NoError(t, err)
+			assert.Equal(t, money.New(999, money.EUR), product.Price)
+
+			product, err = repository.FindById(getProductFromDatabase(t, repository.db, "legacy yen product").ID)
+			assert.NoError(t, err)
+			assert.Equal(t, money.New(1200, money.JPY), product.Price)
+		})
+
+		t.Run("should round prices only once", func(t *testing.T) {
+			t.Cleanup(clearTables(t, repository.db))
+
+			// given
+			assert.NoError(t, repository.Migrate())
+			_, err := repository.db.Exec(`insert into products (name, retailer, price, currency) values
+				('product', 'the company', 9.989, 'EUR')`)
+			assert.NoError(t, err)
+
+			// when
+			err = repository.Migrate()
+
+			// then
+			assert.NoError(t, err)
+
+			var price string
+			assert.NoError(t, repository.db.QueryRow(`select price from products where name = 'product'`).Scan(&price))
+			assert.Equal(t, "9.989", price)
 		})
 	})
 
@@ -63,10 +119,12 @@ func TestIntegrationPsqlRepository(t *testing.T) {
 				{
 					Name:     "test product 1",
 					Retailer: "the company",
+					Price:    money.New(999, money.EUR),
 				},
 				{
 					Name:     "test product 2",
 					Retailer: "the company",
+					Price:    money.New(0, money.JPY),
 				},
 			}
 
@@ -109,6 +167,96 @@ func TestIntegrationPsqlRepository(t *testing.T) {
 		})
 	})
 
+	t.Run("Find", func(t *testing.T) {
+		t.Run("should page through filtered products", func(t *testing.T) {
+			t.Cleanup(clearTables(t, repository.db))
+
+			// given
+			err := repository.Create([]*model.Product{
+				{Name: "blue shirt", Retailer: "the company", Price: money.New(999, money.EUR)},
+				{Name: "red shirt", Retailer: "the company", Price: money.New(1999, money.EUR)},
+				{Name: "green shirt", Retailer: "the company", Price: money.New(999, money.EUR)},
+				{Name: "shoes", Retailer: "the company", Price: money.New(4999, money.EUR)},
+				{Name: "yellow shirt", Retailer: "another company", Price: money.New(999, money.EUR)},
+			})
+			assert.NoError(t, err)
+
+			maxPrice := money.New(1999, money.EUR)
+			query := model.Query{
+				Sort:       model.SortByPrice,
+				Currency:   money.EUR,
+				Retailer:   "the company",
+				Name:       "SHIRT",
+				MaxPrice:   &maxPrice,
+				Limit:      2,
+				CountTotal: true,
+			}
+
+			// when
+			first, err := repository.Find(query)
+			assert.NoError(t, err)
+
+			query.After, err = model.DecodeCursor(first.NextCursor)
+			assert.NoError(t, err)
+			second, err := repository.Find(query)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, 3, *first.Total)
+			assert.Len(t, first.Products, 2)
+			assert.Equal(t, "blue shirt", first.Products[0].Name)
+			assert.Equal(t, "green shirt", first.Products[1].Name)
+			assert.Equal(t, 3, *second.Total)
+			assert.Len(t, second.Products, 1)
+			assert.Equal(t, "red shirt", second.Products[0].Name)
+			assert.Empty(t, second.NextCursor)
+		})
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		t.Cleanup(clearTables(t, repository.db))
+
+		err := repository.Create([]*model.Product{
+			{Name: "red shirt", Retailer: "the company", Price: money.New(1999, money.EUR), Description: "a <b>bold</b> red shirt"},
+			{Name: "blue jeans", Retailer: "the company", Price: money.New(4999, money.EUR), Description: "goes well with a red shirt"},
+			{Name: "shoes", Retailer: "red company", Price: money.New(5999, money.EUR)},
+		})
+		assert.NoError(t, err)
+
+		t.Run("should rank name matches first", func(t *testing.T) {
+			// when
+			results, err := repository.Search("red shirt", 10)
+
+			// then
+			assert.NoError(t, err)
+			assert.Len(t, results, 2)
+			assert.Equal(t, "red shirt", results[0].Name)
+			assert.Equal(t, "blue jeans", results[1].Name)
+			assert.Equal(t, "<mark>red</mark> <mark>shirt</mark>", results[0].Highlights.Name)
+			assert.Contains(t, results[0].Highlights.Description, "&lt;b&gt;bold&lt;/b&gt; <mark>red</mark>")
+		})
+
+		t.Run("should match prefixes", func(t *testing.T) {
+			// when
+			results, err := repository.Search("sho", 10)
+
+			// then
+			assert.NoError(t, err)
+			assert.Len(t, results, 1)
+			assert.Equal(t, "shoes", results[0].Name)
+		})
+
+		t.Run("should match names with typos", func(t *testing.T) {
+			// when
+			results, err := repository.Search("blue jaens", 10)
+
+			// then
+			assert.NoError(t, err)
+			assert.Len(t, results, 1)
+			assert.Equal(t, "blue jeans", results[0].Name)
+		})
+	})
+
 	t.Run("FindById", func(t *testing.T) {
 		t.Run("should return one product", func(t *testing.T) {
 			t.Cleanup(clearTables(t, repository.db))
@@ -139,6 +287,51 @@ func TestIntegrationPsqlRepository(t *testing.T) {
 			assert.Equal(t, "test product 1", product.Name)
 			assert.Equal(t, "the company", product.Retailer)
 		})
+
+		t.Run("should return ErrNotFound if product does not exist", func(t *testing.T) {
+			t.Cleanup(clearTables(t, repository.db))
+
+			// when
+			product, err := repository.FindById(999)
+
+			// then
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.Nil(t, product)
+		})
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		t.Run("should replace product and keep its owner", func(t *testing.T) {
+			t.Cleanup(clearTables(t, repository.db))
+
+			// given
+			insertProduct(t, repository.db, &model.Product{Name: "test product", Retailer: "the company"})
+			id := getProductFromDatabase(t, repository.db, "test product").ID
+			repository.db.Exec(`update products set owner_id = '42' where id = $1`, id)
+
+			product := &model.Product{ID: id, Name: "new name", Retailer: "new company", Price: money.New(999, money.EUR), Description: "new"}
+
+			// when
+			err := repository.Update(product)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, "42", product.OwnerId)
+
+			updated, err := repository.FindById(id)
+			assert.NoError(t, err)
+			assert.Equal(t, product, updated)
+		})
+
+		t.Run("should return ErrNotFound if product does not exist", func(t *testing.T) {
+			t.Cleanup(clearTables(t, repository.db))
+
+			// when
+			err := repository.Update(&model.Product{ID: 999, Name: "test product", Retailer: "the company"})
+
+			// then
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
 	})
 
 	t.Run("Delete", func(t *testing.T) {
@@ -170,6 +363,16 @@ func TestIntegrationPsqlRepository(t *testing.T) {
 			assert.NotNil(t, getProductFromDatabase(t, repository.db, "test product 1"))
 			assert.Nil(t, getProductFromDatabase(t, repository.db, "test product 2"))
 		})
+
+		t.Run("should return ErrNotFound if product does not exist", func(t *testing.T) {
+			t.Cleanup(clearTables(t, repository.db))
+
+			// when
+			err := repository.Delete([]*model.Product{{ID: 999}})
+
+			// then
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
 	})
 }
 
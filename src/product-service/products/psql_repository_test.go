@@ -1,13 +1,21 @@
 package products
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/money"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/products/model"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
+var productColumnNames = []string{"id", "name", "retailer", "price", "currency", "description", "owner_id", "created_at"}
+
 func TestPsqlRepository(t *testing.T) {
 	db, dbmock, err := sqlmock.New()
 	if err != nil {
@@ -16,6 +24,63 @@ func TestPsqlRepository(t *testing.T) {
 
 	repository := PsqlRepository{db}
 
+	t.Run("Migrate", func(t *testing.T) {
+		t.Run("should round prices to the minor unit of their currency", func(t *testing.T) {
+			// given
+			dbmock.ExpectQuery(`select exists \(select 1 from pg_extension where extname = 'pg_trgm'\)`).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			dbmock.ExpectExec(`create table if not exists products`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			dbmock.ExpectBegin()
+			dbmock.ExpectExec(`insert into product_migrations \(name\) values \(\$1\) on conflict \(name\) do nothing`).
+				WithArgs("round-float-prices").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			dbmock.ExpectExec(`update products set price = round\(price, case currency (.+) when 'JPY' then 0 (.+) else 2 end\) where price <> round\(price, (.+)\)`).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			dbmock.ExpectCommit()
+
+			// when
+			err := repository.Migrate()
+
+			// then
+			assert.NoError(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+
+		t.Run("should not round prices again", func(t *testing.T) {
+			// given
+			dbmock.ExpectQuery(`select exists`).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			dbmock.ExpectExec(`create table if not exists products`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			dbmock.ExpectBegin()
+			dbmock.ExpectExec(`insert into product_migrations`).
+				WithArgs("round-float-prices").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			dbmock.ExpectRollback()
+
+			// when
+			err := repository.Migrate()
+
+			// then
+			assert.NoError(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+
+		t.Run("should return error if the pg_trgm extension is missing", func(t *testing.T) {
+			// given
+			dbmock.ExpectQuery(`select exists`).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+			// when
+			err := repository.Migrate()
+
+			// then
+			assert.ErrorContains(t, err, "pg_trgm")
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+	})
+
 	t.Run("Create", func(t *testing.T) {
 		t.Run("should insert products in batches", func(t *testing.T) {
 			// given
@@ -23,15 +88,17 @@ func TestPsqlRepository(t *testing.T) {
 				{
 					Name:     "test product 1",
 					Retailer: "test company",
+					Price:    money.New(999, money.EUR),
 				},
 				{
 					Name:     "test product 2",
 					Retailer: "test company",
+					Price:    money.New(0, money.JPY),
 				},
 			}
 
-			dbmock.ExpectExec(`insert into products \(name, retailer, price, description\) values \(\$1,\$2,\$3,\$4\),\(\$5,\$6,\$7,\$8\)`).
-				WithArgs("test product 1", "test company", sqlmock.AnyArg(), sqlmock.AnyArg(), "test product 2", "test company", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			dbmock.ExpectExec(`insert into products \(name, retailer, price, currency, description, owner_id\) values \(\$1,\$2,\$3,\$4,\$5,\$6\),\(\$7,\$8,\$9,\$10,\$11,\$12\)`).
+				WithArgs("test product 1", "test company", "9.99", money.EUR, sqlmock.AnyArg(), sqlmock.AnyArg(), "test product 2", "test company", "0", money.JPY, sqlmock.AnyArg(), sqlmock.AnyArg()).
 				WillReturnResult(sqlmock.NewResult(0, 2))
 
 			// when
@@ -43,13 +110,39 @@ func TestPsqlRepository(t *testing.T) {
 		})
 	})
 
+	t.Run("Create", func(t *testing.T) {
+		t.Run("should return ErrConflict on unique violations", func(t *testing.T) {
+			// given
+			dbmock.ExpectExec(`insert into products`).
+				WillReturnError(&pq.Error{Code: "23505"})
+
+			// when
+			err := repository.Create([]*model.Product{{Name: "test product", Retailer: "the company", Price: money.New(999, money.EUR)}})
+
+			// then
+			assert.ErrorIs(t, err, ErrConflict)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+
+		t.Run("should return validation error without querying", func(t *testing.T) {
+			// when
+			err := repository.Create([]*model.Product{{Name: "test product"}})
+
+			// then
+			var validation *problem.ValidationError
+			assert.ErrorAs(t, err, &validation)
+			assert.Equal(t, []problem.FieldError{{Field: "retailer", Message: "is required"}, {Field: "price", Message: "needs a currency"}}, validation.Fields)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+	})
+
 	t.Run("FindAll", func(t *testing.T) {
 		t.Run("should return all products", func(t *testing.T) {
 			// given
 			dbmock.ExpectQuery(`select (.*) from products`).
-				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "retailer", "price", "description"}).
-					AddRow(1, "test product 1", "the company", 99.99, "description").
-					AddRow(2, "test product 2", "the company", 9.99, "description"))
+				WillReturnRows(sqlmock.NewRows(productColumnNames).
+					AddRow(1, "test product 1", "the company", "99.99", "EUR", "description", "1", time.Now()).
+					AddRow(2, "test product 2", "the company", "9.99", "EUR", "description", "1", time.Now()))
 
 			// when
 			products, err := repository.FindAll()
@@ -60,18 +153,139 @@ func TestPsqlRepository(t *testing.T) {
 			assert.Len(t, products, 2)
 			assert.Equal(t, "test product 1", products[0].Name)
 			assert.Equal(t, "test product 2", products[1].Name)
+			assert.Equal(t, money.New(9999, money.EUR), products[0].Price)
+		})
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		t.Run("should return first page sorted by creation date", func(t *testing.T) {
+			// given
+			createdAt := time.Date(2023, 11, 1, 12, 0, 0, 500, time.UTC)
+
+			dbmock.ExpectQuery(`select count\(\*\) from products$`).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+			dbmock.ExpectQuery(`select (.*) from products order by created_at asc, id asc limit \$1`).
+				WithArgs(3).
+				WillReturnRows(sqlmock.NewRows(productColumnNames).
+					AddRow(1, "test product 1", "the company", "9.99", "EUR", "", "", createdAt).
+					AddRow(2, "test product 2", "the company", "9.99", "EUR", "", "", createdAt).
+					AddRow(3, "test product 3", "the company", "9.99", "EUR", "", "", createdAt))
+
+			// when
+			page, err := repository.Find(model.Query{Limit: 2, CountTotal: true})
+
+			// then
+			assert.NoError(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+			assert.Len(t, page.Products, 2)
+			assert.Equal(t, 3, *page.Total)
+
+			cursor, err := model.DecodeCursor(page.NextCursor)
+			assert.NoError(t, err)
+			assert.Equal(t, &model.Cursor{Sort: model.SortByCreatedAt, Value: "2023-11-01T12:00:00.0000005Z", Id: 2}, cursor)
+		})
+
+		t.Run("should filter and continue after cursor", func(t *testing.T) {
+			// given
+			minPrice, maxPrice := money.New(500, money.EUR), money.New(999, money.EUR)
+			query := model.Query{
+				Retailer: "the company",
+				Currency: money.EUR,
+				Name:     "50%_off",
+				MinPrice: &minPrice,
+				MaxPrice: &maxPrice,
+				Limit:    2,
+				After:    &model.Cursor{Sort: model.SortByPrice, Descending: true, Value: "9.99", Id: 7},
+			}
+
+			filter := `where retailer = \$1 and name ilike '%' \|\| \$2 \|\| '%' and currency = \$3 and price >= \$4::decimal and price <= \$5::decimal`
+			dbmock.ExpectQuery(`select (.*) from products `+filter+` and \(price, id\) < \(\$6::decimal, \$7\) order by price desc, id desc limit \$8`).
+				WithArgs("the company", `50\%\_off`, money.EUR, "5.00", "9.99", "9.99", 7, 3).
+				WillReturnRows(sqlmock.NewRows(productColumnNames).
+					AddRow(3, "50%_off shirt", "the company", "9.99", "EUR", "", "", time.Now()))
+
+			// when
+			page, err := repository.Find(query)
+
+			// then
+			assert.NoError(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+			assert.Len(t, page.Products, 1)
+			assert.Empty(t, page.NextCursor)
+			assert.Nil(t, page.Total)
+		})
+
+		t.Run("should return error if count failed", func(t *testing.T) {
+			// given
+			dbmock.ExpectQuery(`select count`).
+				WillReturnError(errors.New("database error"))
+
+			// when
+			page, err := repository.Find(model.Query{CountTotal: true})
+
+			// then
+			assert.Error(t, err)
+			assert.Nil(t, page)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		t.Run("should search prefixes and escape highlights", func(t *testing.T) {
+			// given
+			dbmock.ExpectQuery(`select (.*) from products, to_tsquery\('simple', \$1\) tsq\s+where search @@ tsq or \$2 <% name\s+order by rank desc, id\s+limit \$3`).
+				WithArgs("'red':* & 'shi':*", "Red shi-", 5, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows(append(productColumnNames, "rank", "name_highlight", "description_highlight")).
+					AddRow(1, "red shirt", "the company", "9.99", "EUR", "<b>red</b>", "", time.Now(), 0.5, "\x02red\x03 \x02shirt\x03", "<b>\x02red\x03</b>"))
+
+			// when
+			results, err := repository.Search("Red shi-", 5)
+
+			// then
+			assert.NoError(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+			assert.Len(t, results, 1)
+			assert.Equal(t, "red shirt", results[0].Name)
+			assert.Equal(t, float32(0.5), results[0].Rank)
+			assert.Equal(t, "<mark>red</mark> <mark>shirt</mark>", results[0].Highlights.Name)
+			assert.Equal(t, "&lt;b&gt;<mark>red</mark>&lt;/b&gt;", results[0].Highlights.Description)
+		})
+
+		t.Run("should return no results without words", func(t *testing.T) {
+			// when
+			results, err := repository.Search("?!", 5)
+
+			// then
+			assert.NoError(t, err)
+			assert.Empty(t, results)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
 		})
 	})
 
 	t.Run("FindById", func(t *testing.T) {
+		t.Run("should return ErrNotFound if product does not exist", func(t *testing.T) {
+			// given
+			dbmock.ExpectQuery(`select (.*) from products where id = \$1 limit 1`).
+				WithArgs(999).
+				WillReturnRows(sqlmock.NewRows(productColumnNames))
+
+			// when
+			product, err := repository.FindById(999)
+
+			// then
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.Nil(t, product)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+
 		t.Run("should return product by id", func(t *testing.T) {
 			// given
 			var id int64 = 999
 
 			dbmock.ExpectQuery(`select (.*) from products where id = \$1 limit 1`).
 				WithArgs(999).
-				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "retailer", "price", "description"}).
-					AddRow(1, "test product 1", "the company", 99.99, "description"))
+				WillReturnRows(sqlmock.NewRows(productColumnNames).
+					AddRow(1, "test product 1", "the company", "99.99", "EUR", "description", "1", time.Now()))
 
 			// when
 			product, err := repository.FindById(id)
@@ -83,6 +297,49 @@ func TestPsqlRepository(t *testing.T) {
 		})
 	})
 
+	t.Run("Update", func(t *testing.T) {
+		t.Run("should update product and read its owner", func(t *testing.T) {
+			// given
+			product := &model.Product{ID: 1, Name: "test product", Retailer: "the company", Price: money.New(999, money.EUR), Description: "description"}
+
+			dbmock.ExpectQuery(`update products set name = \$2, retailer = \$3, price = \$4, currency = \$5, description = \$6\s+where id = \$1\s+returning owner_id, created_at`).
+				WithArgs(1, "test product", "the company", "9.99", money.EUR, "description").
+				WillReturnRows(sqlmock.NewRows([]string{"owner_id", "created_at"}).AddRow("42", time.Now()))
+
+			// when
+			err := repository.Update(product)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, "42", product.OwnerId)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+
+		t.Run("should return ErrNotFound if product does not exist", func(t *testing.T) {
+			// given
+			dbmock.ExpectQuery(`update products`).
+				WillReturnRows(sqlmock.NewRows([]string{"owner_id", "created_at"}))
+
+			// when
+			err := repository.Update(&model.Product{ID: 999, Name: "test product", Retailer: "the company", Price: money.New(999, money.EUR)})
+
+			// then
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+
+		t.Run("should return validation error without querying", func(t *testing.T) {
+			// when
+			err := repository.Update(&model.Product{ID: 1, Price: money.New(-1, money.EUR)})
+
+			// then
+			var validation *problem.ValidationError
+			assert.ErrorAs(t, err, &validation)
+			assert.Len(t, validation.Fields, 3)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+	})
+
 	t.Run("Delete", func(t *testing.T) {
 		t.Run("should delete products in batch", func(t *testing.T) {
 			// given
@@ -110,5 +367,51 @@ func TestPsqlRepository(t *testing.T) {
 			assert.NoError(t, err)
 			assert.NoError(t, dbmock.ExpectationsWereMet())
 		})
+
+		t.Run("should return ErrNotFound if no product was deleted", func(t *testing.T) {
+			// given
+			dbmock.ExpectExec(`delete from products where id in \(\$1\)`).
+				WithArgs(1).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			// when
+			err := repository.Delete([]*model.Product{{ID: 1}})
+
+			// then
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		db, dbmock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		repository := PsqlRepository{db}
+
+		t.Run("should return error if database is not reachable", func(t *testing.T) {
+			// given
+			dbmock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+			// when
+			err := repository.Ping(context.Background())
+
+			// then
+			assert.Error(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+
+		t.Run("should ping database", func(t *testing.T) {
+			// given
+			dbmock.ExpectPing()
+
+			// when
+			err := repository.Ping(context.Background())
+
+			// then
+			assert.NoError(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
 	})
 }
@@ -1,11 +1,26 @@
 package products
 
-import "github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/products/model"
+import (
+	"fmt"
 
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/product-service/products/model"
+)
+
+var (
+	ErrNotFound = fmt.Errorf("product %w", problem.ErrNotFound)
+	ErrConflict = fmt.Errorf("%w: product already exists", problem.ErrConflict)
+)
+
+// Repository returns ErrNotFound, ErrConflict if a write violates a unique
+// constraint, and a problem.ValidationError if a product to save is invalid.
 type Repository interface {
 	Migrate() error
 	Create([]*model.Product) error
 	FindAll() ([]*model.Product, error)
+	Find(query model.Query) (*model.Page, error)
+	Search(text string, limit int) ([]*model.SearchResult, error)
 	FindById(id int64) (*model.Product, error)
+	Update(*model.Product) error
 	Delete([]*model.Product) error
 }
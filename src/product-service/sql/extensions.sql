@@ -0,0 +1,4 @@
+-- Extensions of the product-service. Creating them needs more rights than the
+-- service user has, so run this once per database as a superuser before the
+-- service starts, e.g. psql -U postgres -d products -f sql/extensions.sql
+create extension if not exists pg_trgm;
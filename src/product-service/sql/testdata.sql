@@ -1,9 +1,12 @@
 CREATE TABLE products (
-	id          serial  primary key,
-	name        text    not null,
-	retailer    text    not null,
-	price       decimal not null default 0,
-	description text             default ''
+	id          serial      primary key,
+	name        text        not null,
+	retailer    text        not null,
+	price       decimal     not null default 0,
+	currency    char(3)     not null default 'EUR',
+	description text                 default '',
+	owner_id    text        not null default '',
+	created_at  timestamptz not null default now()
 );
 
 INSERT INTO products (name, retailer, price, description) VALUES
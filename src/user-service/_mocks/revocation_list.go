@@ -0,0 +1,135 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: auth/revocation_list.go
+//
+// Generated by this command:
+//
+//	mockgen -package=mocks -destination=_mocks/revocation_list.go -source=auth/revocation_list.go
+//
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	jwtauth "github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRevocationList is a mock of RevocationList interface.
+type MockRevocationList struct {
+	ctrl     *gomock.Controller
+	recorder *MockRevocationListMockRecorder
+}
+
+// MockRevocationListMockRecorder is the mock recorder for MockRevocationList.
+type MockRevocationListMockRecorder struct {
+	mock *MockRevocationList
+}
+
+// NewMockRevocationList creates a new mock instance.
+func NewMockRevocationList(ctrl *gomock.Controller) *MockRevocationList {
+	mock := &MockRevocationList{ctrl: ctrl}
+	mock.recorder = &MockRevocationListMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRevocationList) EXPECT() *MockRevocationListMockRecorder {
+	return m.recorder
+}
+
+// IsRevoked mocks base method.
+func (m *MockRevocationList) IsRevoked(jti string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRevoked", jti)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRevoked indicates an expected call of IsRevoked.
+func (mr *MockRevocationListMockRecorder) IsRevoked(jti any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRevoked", reflect.TypeOf((*MockRevocationList)(nil).IsRevoked), jti)
+}
+
+// List mocks base method.
+func (m *MockRevocationList) List() ([]jwtauth.RevokedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]jwtauth.RevokedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRevocationListMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRevocationList)(nil).List))
+}
+
+// Revoke mocks base method.
+func (m *MockRevocationList) Revoke(jti string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", jti, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockRevocationListMockRecorder) Revoke(jti, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockRevocationList)(nil).Revoke), jti, expiresAt)
+}
+
+// RevokeSubject mocks base method.
+func (m *MockRevocationList) RevokeSubject(subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSubject", subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSubject indicates an expected call of RevokeSubject.
+func (mr *MockRevocationListMockRecorder) RevokeSubject(subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSubject", reflect.TypeOf((*MockRevocationList)(nil).RevokeSubject), subject)
+}
+
+// MockTokenTracker is a mock of TokenTracker interface.
+type MockTokenTracker struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenTrackerMockRecorder
+}
+
+// MockTokenTrackerMockRecorder is the mock recorder for MockTokenTracker.
+type MockTokenTrackerMockRecorder struct {
+	mock *MockTokenTracker
+}
+
+// NewMockTokenTracker creates a new mock instance.
+func NewMockTokenTracker(ctrl *gomock.Controller) *MockTokenTracker {
+	mock := &MockTokenTracker{ctrl: ctrl}
+	mock.recorder = &MockTokenTrackerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenTracker) EXPECT() *MockTokenTrackerMockRecorder {
+	return m.recorder
+}
+
+// Track mocks base method.
+func (m *MockTokenTracker) Track(jti, subject string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Track", jti, subject, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Track indicates an expected call of Track.
+func (mr *MockTokenTrackerMockRecorder) Track(jti, subject, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Track", reflect.TypeOf((*MockTokenTracker)(nil).Track), jti, subject, expiresAt)
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/auth"
+)
+
+type JwksHandler struct {
+	keySet *auth.KeySet
+}
+
+func NewJwksHandler(keySet *auth.KeySet) *JwksHandler {
+	return &JwksHandler{keySet}
+}
+
+func (handler *JwksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("Cache-Control", "public, max-age=300")
+		json.NewEncoder(w).Encode(handler.keySet.JWKS())
+	default:
+		problem.WriteStatus(w, r, http.StatusMethodNotAllowed, "")
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJwksHandler(t *testing.T) {
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keySet, _ := auth.NewKeySet(inMemoryJwtConfig{privateKey})
+	handler := NewJwksHandler(keySet)
+
+	t.Run("should return 405 METHOD NOT ALLOWED if method is not GET", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/.well-known/jwks.json", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("should return public keys", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		var response jwtauth.JSONWebKeySet
+		err := json.NewDecoder(w.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Len(t, response.Keys, 1)
+
+		publicKey, err := response.Keys[0].PublicKey()
+		assert.NoError(t, err)
+		assert.True(t, privateKey.PublicKey.Equal(publicKey))
+	})
+}
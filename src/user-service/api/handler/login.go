@@ -2,12 +2,15 @@ package handler
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/auth"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/crypto"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user"
 )
 
@@ -17,9 +20,10 @@ type loginRequest struct {
 }
 
 type loginResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 func (r *loginRequest) isValid() bool {
@@ -30,14 +34,20 @@ type LoginHandler struct {
 	userRepository user.Repository
 	hasher         crypto.Hasher
 	tokenGenerator auth.TokenGenerator
+	sessionManager session.Manager
+	accessTokenTtl time.Duration
+	logger         *slog.Logger
 }
 
 func NewLoginHandler(
 	userRepository user.Repository,
 	hasher crypto.Hasher,
 	tokenGenerator auth.TokenGenerator,
+	sessionManager session.Manager,
+	accessTokenTtl time.Duration,
+	logger *slog.Logger,
 ) *LoginHandler {
-	return &LoginHandler{userRepository, hasher, tokenGenerator}
+	return &LoginHandler{userRepository, hasher, tokenGenerator, sessionManager, accessTokenTtl, logger}
 }
 
 func (handler *LoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -45,46 +55,64 @@ func (handler *LoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost:
 		var request loginRequest
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			problem.WriteStatus(w, r, http.StatusBadRequest, "the request body is not valid JSON")
 			return
 		}
 
 		if !request.isValid() {
-			w.WriteHeader(http.StatusBadRequest)
+			problem.WriteStatus(w, r, http.StatusBadRequest, "email and password are required")
 			return
 		}
 
 		users, err := handler.userRepository.FindByEmail(request.Email)
 		if err != nil {
-			log.Printf("could not find user by email: %s", err.Error())
-			w.WriteHeader(http.StatusInternalServerError)
+			handler.logger.ErrorContext(r.Context(), "could not find user by email", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
 			return
 		}
 
 		if len(users) < 1 {
+			logins.Inc(loginFailed)
 			w.Header().Add("WWW-Authenticate", "Basic realm=Restricted")
-			w.WriteHeader(http.StatusUnauthorized)
+			problem.WriteStatus(w, r, http.StatusUnauthorized, "email or password is wrong")
 			return
 		}
 
 		if ok := handler.hasher.Validate([]byte(request.Password), users[0].Password); !ok {
+			logins.Inc(loginFailed)
 			w.Header().Add("WWW-Authenticate", "Basic realm=Restricted")
-			w.WriteHeader(http.StatusUnauthorized)
+			problem.WriteStatus(w, r, http.StatusUnauthorized, "email or password is wrong")
 			return
 		}
 
-		expiration := 1 * time.Hour
+		subject := strconv.FormatInt(users[0].Id, 10)
 		accessToken, err := handler.tokenGenerator.CreateToken(map[string]interface{}{
-			"email": request.Email,
-			"exp":   time.Now().Add(expiration).Unix(),
+			"sub":   subject,
+			"email": users[0].Email,
+			"role":  string(users[0].Role),
+			"exp":   time.Now().Add(handler.accessTokenTtl).Unix(),
 		})
+		if err != nil {
+			handler.logger.ErrorContext(r.Context(), "could not create access token", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		refreshToken, err := handler.sessionManager.Create(subject)
+		if err != nil {
+			handler.logger.ErrorContext(r.Context(), "could not create refresh token", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
 
+		logins.Inc(loginSucceeded)
 		json.NewEncoder(w).Encode(loginResponse{
-			AccessToken: accessToken,
-			TokenType:   "Bearer",
-			ExpiresIn:   int(expiration.Seconds()),
+			AccessToken:  accessToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int(handler.accessTokenTtl.Seconds()),
+			RefreshToken: refreshToken,
 		})
 	default:
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		problem.WriteStatus(w, r, http.StatusMethodNotAllowed, "")
 	}
 }
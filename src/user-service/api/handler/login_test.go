@@ -1,28 +1,52 @@
 package handler
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/gomockhelpers"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
 	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/_mocks"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/auth"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
+type inMemoryJwtConfig struct {
+	privateKey *ecdsa.PrivateKey
+}
+
+func (config inMemoryJwtConfig) ReadPrivateKeys() ([]any, error) {
+	return []any{config.privateKey}, nil
+}
+
+func (config inMemoryJwtConfig) Issuer() string {
+	return "user-service"
+}
+
+func (config inMemoryJwtConfig) Audience() string {
+	return "shop"
+}
+
 func TestLoginHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
 	userRepository := mocks.NewMockRepository(ctrl)
 	hasher := mocks.NewMockHasher(ctrl)
 	tokenGenerator := mocks.NewMockTokenGenerator(ctrl)
-	handler := NewLoginHandler(userRepository, hasher, tokenGenerator)
+	sessionManager := mocks.NewMockManager(ctrl)
+	handler := NewLoginHandler(userRepository, hasher, tokenGenerator, sessionManager, time.Hour, slog.Default())
 
 	t.Run("should return 405 METHOD NOT ALLOWED if method is not POST", func(t *testing.T) {
 		// given
@@ -118,8 +142,10 @@ func TestLoginHandler(t *testing.T) {
 			EXPECT().
 			FindByEmail("test@test.com").
 			Return([]*model.DbUser{{
+				Id:       1,
 				Email:    "test@test.com",
 				Password: []byte("hashed password"),
+				Role:     model.RoleCustomer,
 			}}, nil)
 
 		hasher.
@@ -135,6 +161,43 @@ func TestLoginHandler(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 
+	t.Run("should return 500 INTERNAL SERVER ERROR if refresh token could not be created", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/login", strings.NewReader(`{"email":"test@test.com","password":"test"}`))
+
+		userRepository.
+			EXPECT().
+			FindByEmail("test@test.com").
+			Return([]*model.DbUser{{
+				Id:       1,
+				Email:    "test@test.com",
+				Password: []byte("hashed password"),
+				Role:     model.RoleCustomer,
+			}}, nil)
+
+		hasher.
+			EXPECT().
+			Validate([]byte("test"), []byte("hashed password")).
+			Return(true)
+
+		tokenGenerator.
+			EXPECT().
+			CreateToken(gomock.Any()).
+			Return("token", nil)
+
+		sessionManager.
+			EXPECT().
+			Create("1").
+			Return("", errors.New("database error"))
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
 	t.Run("should return 200 OK", func(t *testing.T) {
 		// given
 		w := httptest.NewRecorder()
@@ -144,8 +207,10 @@ func TestLoginHandler(t *testing.T) {
 			EXPECT().
 			FindByEmail("test@test.com").
 			Return([]*model.DbUser{{
+				Id:       1,
 				Email:    "test@test.com",
 				Password: []byte("hashed password"),
+				Role:     model.RoleCustomer,
 			}}, nil)
 
 		hasher.
@@ -156,11 +221,18 @@ func TestLoginHandler(t *testing.T) {
 		tokenGenerator.
 			EXPECT().
 			CreateToken(gomockhelpers.Map(map[string]interface{}{
+				"sub":   "1",
 				"email": "test@test.com",
+				"role":  "customer",
 				"exp":   gomock.Any(),
 			})).
 			Return("token", nil)
 
+		sessionManager.
+			EXPECT().
+			Create("1").
+			Return("refresh token", nil)
+
 		// when
 		handler.ServeHTTP(w, r)
 
@@ -173,6 +245,67 @@ func TestLoginHandler(t *testing.T) {
 		assert.Equal(t, "token", response["access_token"])
 		assert.Equal(t, "Bearer", response["token_type"])
 		assert.Equal(t, float64(3600), response["expires_in"])
+		assert.Equal(t, "refresh token", response["refresh_token"])
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
+
+	t.Run("should issue token which can be verified", func(t *testing.T) {
+		// given
+		privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		config := inMemoryJwtConfig{privateKey}
+		keySet, _ := auth.NewKeySet(config)
+		revocationList := mocks.NewMockRevocationList(ctrl)
+		tokenTracker := mocks.NewMockTokenTracker(ctrl)
+		jwtTokenGenerator := auth.NewJwtTokenGenerator(config, keySet, tokenTracker)
+		jwtTokenVerifier := jwtauth.NewKeySourceVerifier(keySet.KeySource(), jwtauth.WithRevocationList(revocationList))
+		handler := NewLoginHandler(userRepository, hasher, jwtTokenGenerator, sessionManager, time.Hour, slog.Default())
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/login", strings.NewReader(`{"email":"test@test.com","password":"test"}`))
+
+		userRepository.
+			EXPECT().
+			FindByEmail("test@test.com").
+			Return([]*model.DbUser{{
+				Id:       1,
+				Email:    "test@test.com",
+				Password: []byte("hashed password"),
+				Role:     model.RoleCustomer,
+			}}, nil)
+
+		hasher.
+			EXPECT().
+			Validate([]byte("test"), []byte("hashed password")).
+			Return(true)
+
+		sessionManager.
+			EXPECT().
+			Create("1").
+			Return("refresh token", nil)
+
+		tokenTracker.
+			EXPECT().
+			Track(gomock.Any(), "1", gomock.Any()).
+			Return(nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		var response loginResponse
+		err := json.NewDecoder(w.Body).Decode(&response)
+		assert.NoError(t, err)
+
+		revocationList.
+			EXPECT().
+			IsRevoked(gomock.Any()).
+			Return(false, nil)
+
+		claims, err := jwtTokenVerifier.Verify(response.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", claims.String("sub"))
+		assert.Equal(t, "test@test.com", claims.String("email"))
+		assert.Equal(t, "customer", claims.String("role"))
+		assert.WithinDuration(t, claims.Time("iat").Add(time.Hour), claims.Time("exp"), time.Second)
+	})
 }
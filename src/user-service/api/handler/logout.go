@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/auth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session"
+)
+
+type logoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (r *logoutRequest) isValid() bool {
+	return r.RefreshToken != ""
+}
+
+type LogoutHandler struct {
+	tokenVerifier  jwtauth.TokenVerifier
+	revocationList auth.RevocationList
+	sessionManager session.Manager
+	logger         *slog.Logger
+}
+
+func NewLogoutHandler(
+	tokenVerifier jwtauth.TokenVerifier,
+	revocationList auth.RevocationList,
+	sessionManager session.Manager,
+	logger *slog.Logger,
+) *LogoutHandler {
+	return &LogoutHandler{tokenVerifier, revocationList, sessionManager, logger}
+}
+
+func (handler *LogoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var request logoutRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			problem.WriteStatus(w, r, http.StatusBadRequest, "the request body is not valid JSON")
+			return
+		}
+
+		if !request.isValid() {
+			problem.WriteStatus(w, r, http.StatusBadRequest, "refresh_token is required")
+			return
+		}
+
+		if err := handler.sessionManager.Revoke(request.RefreshToken); err != nil && !errors.Is(err, session.ErrInvalidRefreshToken) {
+			handler.logger.ErrorContext(r.Context(), "could not revoke refresh token", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		// The access token is optional, but if it's sent along it must not
+		// outlive the session.
+		if accessToken, ok := jwtauth.BearerToken(r); ok {
+			claims, err := handler.tokenVerifier.Verify(accessToken)
+			if jti := claims.String("jti"); err == nil && jti != "" {
+				if err := handler.revocationList.Revoke(jti, claims.Time("exp")); err != nil {
+					handler.logger.ErrorContext(r.Context(), "could not revoke access token", slog.String("error", err.Error()))
+					problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+					return
+				}
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		problem.WriteStatus(w, r, http.StatusMethodNotAllowed, "")
+	}
+}
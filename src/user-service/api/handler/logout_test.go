@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/_mocks"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLogoutHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	tokenVerifier := mocks.NewMockTokenVerifier(ctrl)
+	revocationList := mocks.NewMockRevocationList(ctrl)
+	sessionManager := mocks.NewMockManager(ctrl)
+	handler := NewLogoutHandler(tokenVerifier, revocationList, sessionManager, slog.Default())
+
+	t.Run("should return 405 METHOD NOT ALLOWED if method is not POST", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/auth/logout", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("should return 400 BAD REQUEST if payload is not valid", func(t *testing.T) {
+		tests := []io.Reader{
+			nil,
+			strings.NewReader(`{"invalid json`),
+			strings.NewReader(`{}`),
+		}
+
+		for _, test := range tests {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v1/auth/logout", test)
+
+			// when
+			handler.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("should return 500 INTERNAL SERVER ERROR if refresh token could not be revoked", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/logout", strings.NewReader(`{"refresh_token":"refresh token"}`))
+
+		sessionManager.
+			EXPECT().
+			Revoke("refresh token").
+			Return(errors.New("database error"))
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("should return 204 NO CONTENT if refresh token is unknown", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/logout", strings.NewReader(`{"refresh_token":"refresh token"}`))
+
+		sessionManager.
+			EXPECT().
+			Revoke("refresh token").
+			Return(session.ErrInvalidRefreshToken)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should ignore invalid access token", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/logout", strings.NewReader(`{"refresh_token":"refresh token"}`))
+		r.Header.Set("Authorization", "Bearer access token")
+
+		sessionManager.
+			EXPECT().
+			Revoke("refresh token").
+			Return(nil)
+
+		tokenVerifier.
+			EXPECT().
+			Verify("access token").
+			Return(nil, jwtauth.ErrInvalidToken)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should revoke refresh and access token", func(t *testing.T) {
+		// given
+		expiresAt := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/logout", strings.NewReader(`{"refresh_token":"refresh token"}`))
+		r.Header.Set("Authorization", "Bearer access token")
+
+		sessionManager.
+			EXPECT().
+			Revoke("refresh token").
+			Return(nil)
+
+		tokenVerifier.
+			EXPECT().
+			Verify("access token").
+			Return(jwtauth.Claims{"jti": "jti", "exp": float64(expiresAt.Unix())}, nil)
+
+		revocationList.
+			EXPECT().
+			Revoke("jti", expiresAt).
+			Return(nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
@@ -0,0 +1,13 @@
+package handler
+
+import "github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/metrics"
+
+const (
+	loginSucceeded = "succeeded"
+	loginFailed    = "failed"
+)
+
+var (
+	logins        = metrics.NewCounter("user_logins_total", "Total number of login attempts by result.", "result")
+	registrations = metrics.NewCounter("user_registrations_total", "Total number of registered users.")
+)
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
+)
+
+type address struct {
+	Street     string `json:"street"`
+	PostalCode string `json:"postalCode"`
+	City       string `json:"city"`
+	Country    string `json:"country"`
+}
+
+type profileResponse struct {
+	Id              int64     `json:"id"`
+	Email           string    `json:"email"`
+	DisplayName     string    `json:"displayName"`
+	ShippingAddress address   `json:"shippingAddress"`
+	Role            string    `json:"role"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+func newProfileResponse(user *model.DbUser) profileResponse {
+	return profileResponse{
+		Id:          user.Id,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		ShippingAddress: address{
+			Street:     user.ShippingAddress.Street,
+			PostalCode: user.ShippingAddress.PostalCode,
+			City:       user.ShippingAddress.City,
+			Country:    user.ShippingAddress.Country,
+		},
+		Role:      string(user.Role),
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// updateProfileRequest only changes the fields which are present.
+type updateProfileRequest struct {
+	DisplayName     *string  `json:"displayName"`
+	ShippingAddress *address `json:"shippingAddress"`
+}
+
+// validate returns a problem.ValidationError listing the invalid fields.
+func (r *updateProfileRequest) validate() error {
+	var validation problem.ValidationError
+
+	if r.DisplayName != nil && len(*r.DisplayName) > 100 {
+		validation.Add("displayName", "must not be longer than 100 characters")
+	}
+
+	if a := r.ShippingAddress; a != nil {
+		if len(a.Street) > 200 {
+			validation.Add("shippingAddress.street", "must not be longer than 200 characters")
+		}
+
+		if len(a.PostalCode) > 20 {
+			validation.Add("shippingAddress.postalCode", "must not be longer than 20 characters")
+		}
+
+		if len(a.City) > 100 {
+			validation.Add("shippingAddress.city", "must not be longer than 100 characters")
+		}
+
+		if len(a.Country) > 100 {
+			validation.Add("shippingAddress.country", "must not be longer than 100 characters")
+		}
+	}
+
+	return validation.Err()
+}
+
+type ProfileHandler struct {
+	tokenVerifier  jwtauth.TokenVerifier
+	userRepository user.Repository
+	logger         *slog.Logger
+}
+
+func NewProfileHandler(
+	tokenVerifier jwtauth.TokenVerifier,
+	userRepository user.Repository,
+	logger *slog.Logger,
+) *ProfileHandler {
+	return &ProfileHandler{tokenVerifier, userRepository, logger}
+}
+
+func (handler *ProfileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPatch {
+		problem.WriteStatus(w, r, http.StatusMethodNotAllowed, "")
+		return
+	}
+
+	id, ok := handler.authenticate(r)
+	if !ok {
+		w.Header().Add("WWW-Authenticate", "Bearer")
+		problem.WriteStatus(w, r, http.StatusUnauthorized, "a valid bearer token is required")
+		return
+	}
+
+	profile, err := handler.userRepository.FindById(id)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			problem.WriteError(w, r, err)
+			return
+		}
+
+		handler.logger.ErrorContext(r.Context(), "could not find user by id", slog.String("error", err.Error()))
+		problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+		return
+	}
+
+	if r.Method == http.MethodPatch {
+		var request updateProfileRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			problem.WriteStatus(w, r, http.StatusBadRequest, "the request body is not valid JSON")
+			return
+		}
+
+		if err := request.validate(); err != nil {
+			problem.WriteError(w, r, err)
+			return
+		}
+
+		if request.DisplayName != nil {
+			profile.DisplayName = *request.DisplayName
+		}
+
+		if a := request.ShippingAddress; a != nil {
+			profile.ShippingAddress = model.Address{
+				Street:     a.Street,
+				PostalCode: a.PostalCode,
+				City:       a.City,
+				Country:    a.Country,
+			}
+		}
+
+		if err := handler.userRepository.Update(profile); err != nil {
+			handler.logger.ErrorContext(r.Context(), "could not update user", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newProfileResponse(profile))
+}
+
+// authenticate returns the user id of the bearer token.
+func (handler *ProfileHandler) authenticate(r *http.Request) (int64, bool) {
+	token, ok := jwtauth.BearerToken(r)
+	if !ok {
+		return 0, false
+	}
+
+	claims, err := handler.tokenVerifier.Verify(token)
+	if err != nil {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(claims.String("sub"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/_mocks"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProfileHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	tokenVerifier := mocks.NewMockTokenVerifier(ctrl)
+	userRepository := mocks.NewMockRepository(ctrl)
+	handler := NewProfileHandler(tokenVerifier, userRepository, slog.Default())
+
+	testUser := func() *model.DbUser {
+		return &model.DbUser{
+			Id:          1,
+			Email:       "test@test.com",
+			Password:    []byte("hashed password"),
+			DisplayName: "Test",
+			ShippingAddress: model.Address{
+				Street:     "Street 1",
+				PostalCode: "24937",
+				City:       "Flensburg",
+				Country:    "Germany",
+			},
+			Role: model.RoleCustomer,
+		}
+	}
+
+	t.Run("should return 405 METHOD NOT ALLOWED if method is not GET or PATCH", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", "/api/v1/users/me", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("should return 401 UNAUTHORIZED if token is missing", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/users/me", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("should return 401 UNAUTHORIZED if token is invalid", func(t *testing.T) {
+		tests := []struct {
+			claims jwtauth.Claims
+			err    error
+		}{
+			{nil, jwtauth.ErrInvalidToken},
+			{jwtauth.Claims{"sub": "test@test.com"}, nil},
+		}
+
+		for _, test := range tests {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/v1/users/me", nil)
+			r.Header.Set("Authorization", "Bearer token")
+
+			tokenVerifier.
+				EXPECT().
+				Verify("token").
+				Return(test.claims, test.err)
+
+			// when
+			handler.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("should return 404 NOT FOUND if user does not exist", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/users/me", nil)
+		r.Header.Set("Authorization", "Bearer token")
+
+		tokenVerifier.
+			EXPECT().
+			Verify("token").
+			Return(jwtauth.Claims{"sub": "1"}, nil)
+
+		userRepository.
+			EXPECT().
+			FindById(int64(1)).
+			Return(nil, user.ErrNotFound)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should accept bearer scheme in any case", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/users/me", nil)
+		r.Header.Set("Authorization", "bearer token")
+
+		tokenVerifier.
+			EXPECT().
+			Verify("token").
+			Return(jwtauth.Claims{"sub": "1"}, nil)
+
+		userRepository.
+			EXPECT().
+			FindById(int64(1)).
+			Return(testUser(), nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should return profile of the user", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/users/me", nil)
+		r.Header.Set("Authorization", "Bearer token")
+
+		tokenVerifier.
+			EXPECT().
+			Verify("token").
+			Return(jwtauth.Claims{"sub": "1"}, nil)
+
+		userRepository.
+			EXPECT().
+			FindById(int64(1)).
+			Return(testUser(), nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		var response map[string]interface{}
+		err := json.NewDecoder(w.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, float64(1), response["id"])
+		assert.Equal(t, "test@test.com", response["email"])
+		assert.Equal(t, "Test", response["displayName"])
+		assert.Equal(t, "Flensburg", response["shippingAddress"].(map[string]interface{})["city"])
+		assert.Equal(t, "customer", response["role"])
+		assert.NotContains(t, response, "password")
+	})
+
+	t.Run("should return 400 BAD REQUEST if patch is not json", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PATCH", "/api/v1/users/me", strings.NewReader(`{"invalid json`))
+		r.Header.Set("Authorization", "Bearer token")
+
+		tokenVerifier.
+			EXPECT().
+			Verify("token").
+			Return(jwtauth.Claims{"sub": "1"}, nil)
+
+		userRepository.
+			EXPECT().
+			FindById(int64(1)).
+			Return(testUser(), nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 422 UNPROCESSABLE ENTITY if patch has invalid fields", func(t *testing.T) {
+		tests := []struct {
+			payload string
+			fields  []string
+		}{
+			{`{"displayName":"` + strings.Repeat("a", 101) + `"}`, []string{"displayName"}},
+			{`{"shippingAddress":{"postalCode":"` + strings.Repeat("1", 21) + `","city":"` + strings.Repeat("a", 101) + `"}}`, []string{"shippingAddress.postalCode", "shippingAddress.city"}},
+		}
+
+		for _, test := range tests {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PATCH", "/api/v1/users/me", strings.NewReader(test.payload))
+			r.Header.Set("Authorization", "Bearer token")
+
+			tokenVerifier.
+				EXPECT().
+				Verify("token").
+				Return(jwtauth.Claims{"sub": "1"}, nil)
+
+			userRepository.
+				EXPECT().
+				FindById(int64(1)).
+				Return(testUser(), nil)
+
+			// when
+			handler.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			assert.Equal(t, test.fields, problemFields(t, w))
+		}
+	})
+
+	t.Run("should return 500 INTERNAL SERVER ERROR if profile could not be updated", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PATCH", "/api/v1/users/me", strings.NewReader(`{"displayName":"New Name"}`))
+		r.Header.Set("Authorization", "Bearer token")
+
+		tokenVerifier.
+			EXPECT().
+			Verify("token").
+			Return(jwtauth.Claims{"sub": "1"}, nil)
+
+		userRepository.
+			EXPECT().
+			FindById(int64(1)).
+			Return(testUser(), nil)
+
+		userRepository.
+			EXPECT().
+			Update(gomock.Any()).
+			Return(errors.New("database error"))
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("should only update provided fields", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PATCH", "/api/v1/users/me", strings.NewReader(`{"displayName":"New Name"}`))
+		r.Header.Set("Authorization", "Bearer token")
+
+		tokenVerifier.
+			EXPECT().
+			Verify("token").
+			Return(jwtauth.Claims{"sub": "1"}, nil)
+
+		userRepository.
+			EXPECT().
+			FindById(int64(1)).
+			Return(testUser(), nil)
+
+		expected := testUser()
+		expected.DisplayName = "New Name"
+		userRepository.
+			EXPECT().
+			Update(expected).
+			Return(nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		var response map[string]interface{}
+		err := json.NewDecoder(w.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "New Name", response["displayName"])
+		assert.Equal(t, "Flensburg", response["shippingAddress"].(map[string]interface{})["city"])
+	})
+}
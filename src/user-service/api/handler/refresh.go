@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/auth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user"
+)
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (r *refreshRequest) isValid() bool {
+	return r.RefreshToken != ""
+}
+
+type RefreshHandler struct {
+	userRepository user.Repository
+	tokenGenerator auth.TokenGenerator
+	sessionManager session.Manager
+	accessTokenTtl time.Duration
+	logger         *slog.Logger
+}
+
+func NewRefreshHandler(
+	userRepository user.Repository,
+	tokenGenerator auth.TokenGenerator,
+	sessionManager session.Manager,
+	accessTokenTtl time.Duration,
+	logger *slog.Logger,
+) *RefreshHandler {
+	return &RefreshHandler{userRepository, tokenGenerator, sessionManager, accessTokenTtl, logger}
+}
+
+func (handler *RefreshHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var request refreshRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			problem.WriteStatus(w, r, http.StatusBadRequest, "the request body is not valid JSON")
+			return
+		}
+
+		if !request.isValid() {
+			problem.WriteStatus(w, r, http.StatusBadRequest, "refresh_token is required")
+			return
+		}
+
+		subject, refreshToken, err := handler.sessionManager.Rotate(request.RefreshToken)
+		if err != nil {
+			if errors.Is(err, session.ErrRefreshTokenReused) {
+				handler.logger.WarnContext(r.Context(), "refresh token reused, revoked token family")
+			}
+
+			if errors.Is(err, session.ErrInvalidRefreshToken) || errors.Is(err, session.ErrRefreshTokenReused) {
+				problem.WriteStatus(w, r, http.StatusUnauthorized, "the refresh token is invalid")
+				return
+			}
+
+			handler.logger.ErrorContext(r.Context(), "could not rotate refresh token", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(subject, 10, 64)
+		if err != nil {
+			problem.WriteStatus(w, r, http.StatusUnauthorized, "the refresh token is invalid")
+			return
+		}
+
+		account, err := handler.userRepository.FindById(id)
+		if err != nil {
+			if errors.Is(err, user.ErrNotFound) {
+				problem.WriteStatus(w, r, http.StatusUnauthorized, "the refresh token is invalid")
+				return
+			}
+
+			handler.logger.ErrorContext(r.Context(), "could not find user by id", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		accessToken, err := handler.tokenGenerator.CreateToken(map[string]interface{}{
+			"sub":   subject,
+			"email": account.Email,
+			"role":  string(account.Role),
+			"exp":   time.Now().Add(handler.accessTokenTtl).Unix(),
+		})
+		if err != nil {
+			handler.logger.ErrorContext(r.Context(), "could not create access token", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		json.NewEncoder(w).Encode(loginResponse{
+			AccessToken:  accessToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int(handler.accessTokenTtl.Seconds()),
+			RefreshToken: refreshToken,
+		})
+	default:
+		problem.WriteStatus(w, r, http.StatusMethodNotAllowed, "")
+	}
+}
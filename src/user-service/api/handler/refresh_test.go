@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/gomockhelpers"
+	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/_mocks"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRefreshHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	userRepository := mocks.NewMockRepository(ctrl)
+	tokenGenerator := mocks.NewMockTokenGenerator(ctrl)
+	sessionManager := mocks.NewMockManager(ctrl)
+	handler := NewRefreshHandler(userRepository, tokenGenerator, sessionManager, time.Hour, slog.Default())
+
+	t.Run("should return 405 METHOD NOT ALLOWED if method is not POST", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/auth/refresh", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("should return 400 BAD REQUEST if payload is not valid", func(t *testing.T) {
+		tests := []io.Reader{
+			nil,
+			strings.NewReader(`{"invalid json`),
+			strings.NewReader(`{}`),
+		}
+
+		for _, test := range tests {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v1/auth/refresh", test)
+
+			// when
+			handler.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("should return 401 UNAUTHORIZED if refresh token is invalid or reused", func(t *testing.T) {
+		tests := []error{
+			session.ErrInvalidRefreshToken,
+			session.ErrRefreshTokenReused,
+		}
+
+		for _, test := range tests {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v1/auth/refresh", strings.NewReader(`{"refresh_token":"old token"}`))
+
+			sessionManager.
+				EXPECT().
+				Rotate("old token").
+				Return("", "", test)
+
+			// when
+			handler.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("should return 500 INTERNAL SERVER ERROR if refresh token could not be rotated", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/refresh", strings.NewReader(`{"refresh_token":"old token"}`))
+
+		sessionManager.
+			EXPECT().
+			Rotate("old token").
+			Return("", "", errors.New("database error"))
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("should return 401 UNAUTHORIZED if user does not exist anymore", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/refresh", strings.NewReader(`{"refresh_token":"old token"}`))
+
+		sessionManager.
+			EXPECT().
+			Rotate("old token").
+			Return("1", "new token", nil)
+
+		userRepository.
+			EXPECT().
+			FindById(int64(1)).
+			Return(nil, user.ErrNotFound)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should return 200 OK", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/refresh", strings.NewReader(`{"refresh_token":"old token"}`))
+
+		sessionManager.
+			EXPECT().
+			Rotate("old token").
+			Return("1", "new token", nil)
+
+		userRepository.
+			EXPECT().
+			FindById(int64(1)).
+			Return(&model.DbUser{Id: 1, Email: "test@test.com", Role: model.RoleRetailer}, nil)
+
+		tokenGenerator.
+			EXPECT().
+			CreateToken(gomockhelpers.Map(map[string]interface{}{
+				"sub":   "1",
+				"email": "test@test.com",
+				"role":  "retailer",
+				"exp":   gomock.Any(),
+			})).
+			Return("token", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		var response map[string]interface{}
+		err := json.NewDecoder(w.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "token", response["access_token"])
+		assert.Equal(t, "Bearer", response["token_type"])
+		assert.Equal(t, float64(3600), response["expires_in"])
+		assert.Equal(t, "new token", response["refresh_token"])
+	})
+}
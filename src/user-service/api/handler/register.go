@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/crypto"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
@@ -14,8 +15,19 @@ type registerRequest struct {
 	Password string `json:"password"`
 }
 
-func (r *registerRequest) isValid() bool {
-	return r.Email != "" && r.Password != ""
+// validate returns a problem.ValidationError listing the invalid fields.
+func (r *registerRequest) validate() error {
+	var validation problem.ValidationError
+
+	if r.Email == "" {
+		validation.Add("email", "is required")
+	}
+
+	if r.Password == "" {
+		validation.Add("password", "is required")
+	}
+
+	return validation.Err()
 }
 
 type RegisterHandler struct {
@@ -35,29 +47,29 @@ func (handler *RegisterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	case http.MethodPost:
 		var request registerRequest
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			problem.WriteStatus(w, r, http.StatusBadRequest, "the request body is not valid JSON")
 			return
 		}
 
-		if !request.isValid() {
-			w.WriteHeader(http.StatusBadRequest)
+		if err := request.validate(); err != nil {
+			problem.WriteError(w, r, err)
 			return
 		}
 
 		products, err := handler.userRepository.FindByEmail(request.Email)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
 			return
 		}
 
 		if len(products) > 0 {
-			w.WriteHeader(http.StatusConflict)
+			problem.WriteError(w, r, user.ErrConflict)
 			return
 		}
 
 		hashedPassword, err := handler.hasher.Hash([]byte(request.Password))
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
 			return
 		}
 
@@ -65,10 +77,12 @@ func (handler *RegisterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 			Email:    request.Email,
 			Password: hashedPassword,
 		}}); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			problem.WriteError(w, r, err)
 			return
 		}
+
+		registrations.Inc()
 	default:
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		problem.WriteStatus(w, r, http.StatusMethodNotAllowed, "")
 	}
 }
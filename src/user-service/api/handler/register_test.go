@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
@@ -8,7 +9,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
 	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/_mocks"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -52,23 +55,27 @@ func TestRegisterHandler(t *testing.T) {
 		}
 	})
 
-	t.Run("should return 400 BAD REQUEST if payload is incomplete", func(t *testing.T) {
-		tests := []io.Reader{
-			strings.NewReader(`{}`),
-			strings.NewReader(`{"email":"test@test.com"}`),
-			strings.NewReader(`{"password":"test"}`),
+	t.Run("should return 422 UNPROCESSABLE ENTITY if payload is incomplete", func(t *testing.T) {
+		tests := []struct {
+			payload string
+			fields  []string
+		}{
+			{`{}`, []string{"email", "password"}},
+			{`{"email":"test@test.com"}`, []string{"password"}},
+			{`{"password":"test"}`, []string{"email"}},
 		}
 
 		for _, test := range tests {
 			// given
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest("POST", "/api/v1/auth/login", test)
+			r := httptest.NewRequest("POST", "/api/v1/auth/login", strings.NewReader(test.payload))
 
 			// when
 			handler.ServeHTTP(w, r)
 
 			// then
-			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, test.payload)
+			assert.Equal(t, test.fields, problemFields(t, w), test.payload)
 		}
 	})
 
@@ -102,6 +109,42 @@ func TestRegisterHandler(t *testing.T) {
 		// when
 		handler.ServeHTTP(w, r)
 
+		// then
+		var body problem.Problem
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		assert.Equal(t, problem.Problem{
+			Title:    "Conflict",
+			Status:   http.StatusConflict,
+			Detail:   "conflict: email is already registered",
+			Instance: "/api/v1/auth/login",
+		}, body)
+	})
+
+	t.Run("should return 409 CONFLICT if user has been registered concurrently", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/login", strings.NewReader(`{"email":"test@test.com","password":"test"}`))
+
+		userRepository.
+			EXPECT().
+			FindByEmail("test@test.com").
+			Return([]*model.DbUser{}, nil)
+
+		hasher.
+			EXPECT().
+			Hash([]byte("test")).
+			Return([]byte("hashed password"), nil)
+
+		userRepository.
+			EXPECT().
+			Create(gomock.Any()).
+			Return(user.ErrConflict)
+
+		// when
+		handler.ServeHTTP(w, r)
+
 		// then
 		assert.Equal(t, http.StatusConflict, w.Code)
 	})
@@ -188,3 +231,18 @@ func TestRegisterHandler(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 }
+
+// problemFields returns the names of the invalid fields of a problem response.
+func problemFields(t *testing.T, w *httptest.ResponseRecorder) []string {
+	var response problem.Problem
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("could not decode problem: %s", err.Error())
+	}
+
+	fields := make([]string, len(response.Errors))
+	for i, field := range response.Errors {
+		fields[i] = field.Field
+	}
+
+	return fields
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/auth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session"
+)
+
+type RevokeSessionsHandler struct {
+	sessionManager session.Manager
+	revocationList auth.RevocationList
+	logger         *slog.Logger
+}
+
+func NewRevokeSessionsHandler(sessionManager session.Manager, revocationList auth.RevocationList, logger *slog.Logger) *RevokeSessionsHandler {
+	return &RevokeSessionsHandler{sessionManager, revocationList, logger}
+}
+
+func (handler *RevokeSessionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodDelete:
+		userId := r.URL.Query().Get("userId")
+		if _, err := strconv.ParseInt(userId, 10, 64); err != nil {
+			problem.WriteStatus(w, r, http.StatusBadRequest, "userId must be a number")
+			return
+		}
+
+		if err := handler.sessionManager.RevokeAll(userId); err != nil {
+			handler.logger.ErrorContext(r.Context(), "could not revoke sessions", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		if err := handler.revocationList.RevokeSubject(userId); err != nil {
+			handler.logger.ErrorContext(r.Context(), "could not revoke access tokens", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		problem.WriteStatus(w, r, http.StatusMethodNotAllowed, "")
+	}
+}
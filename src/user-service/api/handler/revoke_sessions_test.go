@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/_mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRevokeSessionsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	sessionManager := mocks.NewMockManager(ctrl)
+	revocationList := mocks.NewMockRevocationList(ctrl)
+	handler := NewRevokeSessionsHandler(sessionManager, revocationList, slog.Default())
+
+	t.Run("should return 405 METHOD NOT ALLOWED if method is not DELETE", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/admin/sessions?userId=1", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("should return 400 BAD REQUEST if user id is missing or invalid", func(t *testing.T) {
+		tests := []string{
+			"/api/v1/admin/sessions",
+			"/api/v1/admin/sessions?userId=test@test.com",
+		}
+
+		for _, test := range tests {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("DELETE", test, nil)
+
+			// when
+			handler.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("should return 500 INTERNAL SERVER ERROR if sessions could not be revoked", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", "/api/v1/admin/sessions?userId=1", nil)
+
+		sessionManager.
+			EXPECT().
+			RevokeAll("1").
+			Return(errors.New("database error"))
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("should return 500 INTERNAL SERVER ERROR if access tokens could not be revoked", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", "/api/v1/admin/sessions?userId=1", nil)
+
+		sessionManager.
+			EXPECT().
+			RevokeAll("1").
+			Return(nil)
+
+		revocationList.
+			EXPECT().
+			RevokeSubject("1").
+			Return(errors.New("database error"))
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("should return 204 NO CONTENT", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", "/api/v1/admin/sessions?userId=1", nil)
+
+		sessionManager.
+			EXPECT().
+			RevokeAll("1").
+			Return(nil)
+
+		revocationList.
+			EXPECT().
+			RevokeSubject("1").
+			Return(nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
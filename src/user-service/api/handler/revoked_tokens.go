@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/auth"
+)
+
+type RevokedTokensHandler struct {
+	revocationList auth.RevocationList
+	logger         *slog.Logger
+}
+
+func NewRevokedTokensHandler(revocationList auth.RevocationList, logger *slog.Logger) *RevokedTokensHandler {
+	return &RevokedTokensHandler{revocationList, logger}
+}
+
+func (handler *RevokedTokensHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		tokens, err := handler.revocationList.List()
+		if err != nil {
+			handler.logger.ErrorContext(r.Context(), "could not list revoked tokens", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("Cache-Control", "no-cache")
+		json.NewEncoder(w).Encode(jwtauth.RevokedTokens{Tokens: tokens})
+	default:
+		problem.WriteStatus(w, r, http.StatusMethodNotAllowed, "")
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/_mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRevokedTokensHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	revocationList := mocks.NewMockRevocationList(ctrl)
+	handler := NewRevokedTokensHandler(revocationList, slog.Default())
+
+	t.Run("should return 405 METHOD NOT ALLOWED if method is not GET", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/.well-known/revoked-tokens.json", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("should return 500 INTERNAL SERVER ERROR if tokens could not be listed", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/.well-known/revoked-tokens.json", nil)
+
+		revocationList.
+			EXPECT().
+			List().
+			Return(nil, errors.New("database error"))
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("should return revoked tokens", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/.well-known/revoked-tokens.json", nil)
+
+		revocationList.
+			EXPECT().
+			List().
+			Return([]jwtauth.RevokedToken{{Id: "jti", ExpiresAt: 1700000000}}, nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		var response jwtauth.RevokedTokens
+		err := json.NewDecoder(w.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Equal(t, []jwtauth.RevokedToken{{Id: "jti", ExpiresAt: 1700000000}}, response.Tokens)
+	})
+}
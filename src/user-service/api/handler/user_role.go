@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/auth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
+)
+
+type userRoleRequest struct {
+	Role model.Role `json:"role"`
+}
+
+type UserRoleHandler struct {
+	userRepository user.Repository
+	sessionManager session.Manager
+	revocationList auth.RevocationList
+	logger         *slog.Logger
+}
+
+func NewUserRoleHandler(userRepository user.Repository, sessionManager session.Manager, revocationList auth.RevocationList, logger *slog.Logger) *UserRoleHandler {
+	return &UserRoleHandler{userRepository, sessionManager, revocationList, logger}
+}
+
+// ServeHTTP changes the role of a user. The sessions and access tokens of the
+// user are revoked, so the old role can't be used any longer and the new role
+// is embedded into the access tokens issued on the next login.
+func (handler *UserRoleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut:
+		id, err := strconv.ParseInt(r.URL.Query().Get("userId"), 10, 64)
+		if err != nil {
+			problem.WriteStatus(w, r, http.StatusBadRequest, "userId must be a number")
+			return
+		}
+
+		var request userRoleRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			problem.WriteStatus(w, r, http.StatusBadRequest, "the request body is not valid JSON")
+			return
+		}
+
+		if !request.Role.IsValid() {
+			problem.WriteStatus(w, r, http.StatusBadRequest, "role must be customer, retailer or admin")
+			return
+		}
+
+		if err := handler.userRepository.UpdateRole(id, request.Role); err != nil {
+			if errors.Is(err, user.ErrNotFound) {
+				problem.WriteError(w, r, err)
+				return
+			}
+
+			handler.logger.ErrorContext(r.Context(), "could not update role", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		userId := strconv.FormatInt(id, 10)
+		if err := handler.sessionManager.RevokeAll(userId); err != nil {
+			handler.logger.ErrorContext(r.Context(), "could not revoke sessions", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		if err := handler.revocationList.RevokeSubject(userId); err != nil {
+			handler.logger.ErrorContext(r.Context(), "could not revoke access tokens", slog.String("error", err.Error()))
+			problem.WriteStatus(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		problem.WriteStatus(w, r, http.StatusMethodNotAllowed, "")
+	}
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/_mocks"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUserRoleHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	userRepository := mocks.NewMockRepository(ctrl)
+	sessionManager := mocks.NewMockManager(ctrl)
+	revocationList := mocks.NewMockRevocationList(ctrl)
+	handler := NewUserRoleHandler(userRepository, sessionManager, revocationList, slog.Default())
+
+	t.Run("should return 405 METHOD NOT ALLOWED if method is not PUT", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/admin/users/role?userId=1", nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("should return 400 BAD REQUEST if request is not valid", func(t *testing.T) {
+		tests := []struct {
+			target string
+			body   io.Reader
+		}{
+			{"/api/v1/admin/users/role", strings.NewReader(`{"role":"admin"}`)},
+			{"/api/v1/admin/users/role?userId=1", strings.NewReader(`{"invalid json`)},
+			{"/api/v1/admin/users/role?userId=1", strings.NewReader(`{"role":"superuser"}`)},
+		}
+
+		for _, test := range tests {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PUT", test.target, test.body)
+
+			// when
+			handler.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("should return 404 NOT FOUND if user does not exist", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PUT", "/api/v1/admin/users/role?userId=1", strings.NewReader(`{"role":"retailer"}`))
+
+		userRepository.
+			EXPECT().
+			UpdateRole(int64(1), model.RoleRetailer).
+			Return(user.ErrNotFound)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should return 500 INTERNAL SERVER ERROR if role could not be updated", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PUT", "/api/v1/admin/users/role?userId=1", strings.NewReader(`{"role":"retailer"}`))
+
+		userRepository.
+			EXPECT().
+			UpdateRole(int64(1), model.RoleRetailer).
+			Return(errors.New("database error"))
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("should return 500 INTERNAL SERVER ERROR if sessions could not be revoked", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PUT", "/api/v1/admin/users/role?userId=1", strings.NewReader(`{"role":"retailer"}`))
+
+		userRepository.
+			EXPECT().
+			UpdateRole(int64(1), model.RoleRetailer).
+			Return(nil)
+
+		sessionManager.
+			EXPECT().
+			RevokeAll("1").
+			Return(errors.New("database error"))
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("should return 500 INTERNAL SERVER ERROR if access tokens could not be revoked", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PUT", "/api/v1/admin/users/role?userId=1", strings.NewReader(`{"role":"retailer"}`))
+
+		userRepository.
+			EXPECT().
+			UpdateRole(int64(1), model.RoleRetailer).
+			Return(nil)
+
+		sessionManager.
+			EXPECT().
+			RevokeAll("1").
+			Return(nil)
+
+		revocationList.
+			EXPECT().
+			RevokeSubject("1").
+			Return(errors.New("database error"))
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("should return 204 NO CONTENT and revoke sessions and access tokens", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PUT", "/api/v1/admin/users/role?userId=1", strings.NewReader(`{"role":"retailer"}`))
+
+		userRepository.
+			EXPECT().
+			UpdateRole(int64(1), model.RoleRetailer).
+			Return(nil)
+
+		sessionManager.
+			EXPECT().
+			RevokeAll("1").
+			Return(nil)
+
+		revocationList.
+			EXPECT().
+			RevokeSubject("1").
+			Return(nil)
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
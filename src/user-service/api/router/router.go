@@ -1,22 +1,75 @@
 package router
 
-import "net/http"
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/authz"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/health"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
+)
 
 type Router struct {
-	mux *http.ServeMux
+	router http.Handler
 }
 
 func New(
 	registerHandler http.Handler,
 	loginHandler http.Handler,
+	refreshHandler http.Handler,
+	logoutHandler http.Handler,
+	profileHandler http.Handler,
+	jwksHandler http.Handler,
+	revokedTokensHandler http.Handler,
+	healthHandler *health.Handler,
+	authLimit router.Middleware,
+	middlewares ...router.Middleware,
+) *Router {
+	router := router.New()
+	router.Use(middlewares...)
+	router.GET("/healthz", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
+
+	auth := router.Group("/api/v1/auth")
+	auth.Handle(http.MethodPost, "/register", registerHandler, authLimit)
+	auth.Handle(http.MethodPost, "/login", loginHandler, authLimit)
+	auth.Handle(http.MethodPost, "/refresh", refreshHandler)
+	auth.Handle(http.MethodPost, "/logout", logoutHandler)
+
+	users := router.Group("/api/v1/users")
+	users.Handle(http.MethodGet, "/me", profileHandler)
+	users.Handle(http.MethodPatch, "/me", profileHandler)
+
+	router.Handle(http.MethodGet, "/.well-known/jwks.json", jwksHandler)
+	router.Handle(http.MethodGet, "/.well-known/revoked-tokens.json", revokedTokensHandler)
+
+	return &Router{router}
+}
+
+// NewAdmin creates the router of the admin listener, which must not be
+// reachable from outside the cluster. The admin endpoints additionally require
+// an access token with the admin role, so the jwtauth middleware has to be
+// one of the middlewares.
+func NewAdmin(
+	revokeSessionsHandler http.Handler,
+	userRoleHandler http.Handler,
+	metricsHandler http.Handler,
+	logger *slog.Logger,
+	middlewares ...router.Middleware,
 ) *Router {
-	mux := http.NewServeMux()
-	mux.Handle("/api/v1/auth/register", registerHandler)
-	mux.Handle("/api/v1/auth/login", loginHandler)
+	router := router.New()
+	router.Use(middlewares...)
+
+	admin := router.Group("/api/v1/admin", authz.Require(authz.HasRole(string(model.RoleAdmin)), authz.WithLogger(logger)))
+	admin.Handle(http.MethodDelete, "/sessions", revokeSessionsHandler)
+	admin.Handle(http.MethodPut, "/users/role", userRoleHandler)
+
+	router.Handle(http.MethodGet, "/metrics", metricsHandler)
 
-	return &Router{mux}
+	return &Router{router}
 }
 
-func (handler *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	handler.mux.ServeHTTP(w, r)
+func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	router.router.ServeHTTP(w, r)
 }
@@ -1,10 +1,14 @@
 package router
 
 import (
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/gomockhelpers"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/health"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
 	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/_mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -15,7 +19,21 @@ func TestRouter(t *testing.T) {
 
 	registerHandler := mocks.NewMockHandler(ctrl)
 	loginHandler := mocks.NewMockHandler(ctrl)
-	router := New(registerHandler, loginHandler)
+	refreshHandler := mocks.NewMockHandler(ctrl)
+	logoutHandler := mocks.NewMockHandler(ctrl)
+	profileHandler := mocks.NewMockHandler(ctrl)
+	jwksHandler := mocks.NewMockHandler(ctrl)
+	revokedTokensHandler := mocks.NewMockHandler(ctrl)
+	authLimit := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Limited") != "" {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	router := New(registerHandler, loginHandler, refreshHandler, logoutHandler, profileHandler, jwksHandler, revokedTokensHandler, health.NewHandler(), authLimit)
 
 	t.Run("should run register handler", func(t *testing.T) {
 		// given
@@ -24,7 +42,61 @@ func TestRouter(t *testing.T) {
 
 		registerHandler.
 			EXPECT().
-			ServeHTTP(w, r).
+			ServeHTTP(w, gomockhelpers.Request(r)).
+			Times(1)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, ctrl.Satisfied())
+	})
+
+	t.Run("should run refresh handler", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/refresh", nil)
+
+		refreshHandler.
+			EXPECT().
+			ServeHTTP(w, gomockhelpers.Request(r)).
+			Times(1)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, ctrl.Satisfied())
+	})
+
+	t.Run("should run logout handler", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/logout", nil)
+
+		logoutHandler.
+			EXPECT().
+			ServeHTTP(w, gomockhelpers.Request(r)).
+			Times(1)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, ctrl.Satisfied())
+	})
+
+	t.Run("should run profile handler", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/users/me", nil)
+
+		profileHandler.
+			EXPECT().
+			ServeHTTP(w, gomockhelpers.Request(r)).
 			Times(1)
 
 		// when
@@ -42,7 +114,78 @@ func TestRouter(t *testing.T) {
 
 		loginHandler.
 			EXPECT().
-			ServeHTTP(w, r).
+			ServeHTTP(w, gomockhelpers.Request(r)).
+			Times(1)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, ctrl.Satisfied())
+	})
+
+	t.Run("should rate limit register and login", func(t *testing.T) {
+		tests := []string{"/api/v1/auth/register", "/api/v1/auth/login"}
+
+		for _, test := range tests {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", test, nil)
+			r.Header.Set("X-Limited", "true")
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusTooManyRequests, w.Code, test)
+		}
+	})
+
+	t.Run("should not rate limit refresh", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/refresh", nil)
+		r.Header.Set("X-Limited", "true")
+
+		refreshHandler.
+			EXPECT().
+			ServeHTTP(w, gomockhelpers.Request(r)).
+			Times(1)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should run jwks handler", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+
+		jwksHandler.
+			EXPECT().
+			ServeHTTP(w, gomockhelpers.Request(r)).
+			Times(1)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, ctrl.Satisfied())
+	})
+
+	t.Run("should run revoked tokens handler", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/.well-known/revoked-tokens.json", nil)
+
+		revokedTokensHandler.
+			EXPECT().
+			ServeHTTP(w, gomockhelpers.Request(r)).
 			Times(1)
 
 		// when
@@ -53,6 +196,22 @@ func TestRouter(t *testing.T) {
 		assert.True(t, ctrl.Satisfied())
 	})
 
+	t.Run("should return 200 OK on health endpoints", func(t *testing.T) {
+		tests := []string{"/healthz", "/readyz"}
+
+		for _, test := range tests {
+			// given
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", test, nil)
+
+			// when
+			router.ServeHTTP(w, r)
+
+			// then
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+	})
+
 	t.Run("should return 404 NOT FOUND if target is unknown", func(t *testing.T) {
 		// given
 		w := httptest.NewRecorder()
@@ -64,4 +223,117 @@ func TestRouter(t *testing.T) {
 		// then
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
+
+	t.Run("should return 405 METHOD NOT ALLOWED if method is not allowed", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", "/api/v1/users/me", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET, HEAD, OPTIONS, PATCH", w.Header().Get("Allow"))
+	})
+}
+
+func TestAdminRouter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	revokeSessionsHandler := mocks.NewMockHandler(ctrl)
+	userRoleHandler := mocks.NewMockHandler(ctrl)
+	metricsHandler := mocks.NewMockHandler(ctrl)
+	router := NewAdmin(revokeSessionsHandler, userRoleHandler, metricsHandler, slog.Default())
+
+	withClaims := func(r *http.Request, claims jwtauth.Claims) *http.Request {
+		return r.WithContext(jwtauth.NewContext(r.Context(), claims))
+	}
+
+	t.Run("should return 401 UNAUTHORIZED if admin request is not authenticated", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", "/api/v1/admin/sessions?userId=1", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should return 403 FORBIDDEN if caller is not an admin", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := withClaims(httptest.NewRequest("PUT", "/api/v1/admin/users/role?userId=1", nil), jwtauth.Claims{"role": "retailer"})
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should run revoke sessions handler", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := withClaims(httptest.NewRequest("DELETE", "/api/v1/admin/sessions?userId=1", nil), jwtauth.Claims{"role": "admin"})
+
+		revokeSessionsHandler.
+			EXPECT().
+			ServeHTTP(w, gomockhelpers.Request(r)).
+			Times(1)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.True(t, ctrl.Satisfied())
+	})
+
+	t.Run("should run user role handler", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := withClaims(httptest.NewRequest("PUT", "/api/v1/admin/users/role?userId=1", nil), jwtauth.Claims{"role": "admin"})
+
+		userRoleHandler.
+			EXPECT().
+			ServeHTTP(w, gomockhelpers.Request(r)).
+			Times(1)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.True(t, ctrl.Satisfied())
+	})
+
+	t.Run("should run metrics handler", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/metrics", nil)
+
+		metricsHandler.
+			EXPECT().
+			ServeHTTP(w, gomockhelpers.Request(r)).
+			Times(1)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.True(t, ctrl.Satisfied())
+	})
+
+	t.Run("should not serve public routes", func(t *testing.T) {
+		// given
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+
+		// when
+		router.ServeHTTP(w, r)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
 }
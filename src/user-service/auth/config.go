@@ -1,5 +1,7 @@
 package auth
 
 type Config interface {
-	ReadPrivateKey() (any, error)
+	ReadPrivateKeys() ([]any, error)
+	Issuer() string
+	Audience() string
 }
@@ -3,19 +3,87 @@ package auth
 import (
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	defaultIssuer   = "user-service"
+	defaultAudience = "shop"
 )
 
 type JwtConfig struct {
-	SignKey string `yaml:"signKey"`
+	SignKey       string `yaml:"signKey"`
+	KeysDir       string `yaml:"keysDir"`
+	TokenIssuer   string `yaml:"issuer"`
+	TokenAudience string `yaml:"audience"`
 }
 
-func (config JwtConfig) ReadPrivateKey() (any, error) {
-	bytes, err := os.ReadFile(config.SignKey)
+// ReadPrivateKeys returns the keys in KeysDir ordered by file name, followed by
+// SignKey. The last key is the one used for signing new tokens.
+func (config JwtConfig) ReadPrivateKeys() ([]any, error) {
+	var paths []string
+
+	if config.KeysDir != "" {
+		matches, err := filepath.Glob(filepath.Join(config.KeysDir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+
+	if config.SignKey != "" {
+		paths = append(paths, config.SignKey)
+	}
+
+	if len(paths) == 0 {
+		return nil, errors.New("no signing keys configured")
+	}
+
+	keys := make([]any, len(paths))
+	for i, path := range paths {
+		key, err := readPrivateKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read key %s: %w", path, err)
+		}
+
+		keys[i] = key
+	}
+
+	return keys, nil
+}
+
+func (config JwtConfig) Issuer() string {
+	if config.TokenIssuer == "" {
+		return defaultIssuer
+	}
+
+	return config.TokenIssuer
+}
+
+func (config JwtConfig) Audience() string {
+	if config.TokenAudience == "" {
+		return defaultAudience
+	}
+
+	return config.TokenAudience
+}
+
+func readPrivateKey(path string) (any, error) {
+	bytes, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
 	block, _ := pem.Decode(bytes)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
 	return x509.ParseECPrivateKey(block.Bytes)
 }
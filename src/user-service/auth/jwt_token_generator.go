@@ -1,35 +1,89 @@
 package auth
 
 import (
-	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 )
 
 type JwtTokenGenerator struct {
-	privateKey *ecdsa.PrivateKey
+	keys     *KeySet
+	issuer   string
+	audience string
+	tracker  TokenTracker
 }
 
-func NewJwtTokenGenerator(config Config) (*JwtTokenGenerator, error) {
-	key, err := config.ReadPrivateKey()
-	if err != nil {
-		return nil, err
-	}
-
-	privateKey, ok := key.(*ecdsa.PrivateKey)
-	if !ok {
-		return nil, err
+func NewJwtTokenGenerator(config Config, keys *KeySet, tracker TokenTracker) *JwtTokenGenerator {
+	return &JwtTokenGenerator{
+		keys:     keys,
+		issuer:   config.Issuer(),
+		audience: config.Audience(),
+		tracker:  tracker,
 	}
-
-	return &JwtTokenGenerator{privateKey}, nil
 }
 
 func (gen *JwtTokenGenerator) CreateToken(claims map[string]interface{}) (string, error) {
-	jwtClaims := jwt.MapClaims{}
+	jti, err := newTokenId()
+	if err != nil {
+		return "", err
+	}
+
+	jwtClaims := jwt.MapClaims{
+		"jti": jti,
+		"iss": gen.issuer,
+		"aud": gen.audience,
+		"iat": time.Now().Unix(),
+	}
 	for k, v := range claims {
 		jwtClaims[k] = v
 	}
 
+	kid, privateKey := gen.keys.SigningKey()
+
 	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwtClaims)
-	return token.SignedString(gen.privateKey)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	if err := gen.track(jti, jwtClaims); err != nil {
+		return "", err
+	}
+
+	return signed, nil
+}
+
+// track records tokens with a subject and an expiry, so they can be revoked
+// together with the sessions of the subject.
+func (gen *JwtTokenGenerator) track(jti string, claims jwt.MapClaims) error {
+	subject, ok := claims["sub"].(string)
+	if !ok || gen.tracker == nil {
+		return nil
+	}
+
+	var expiresAt time.Time
+	switch exp := claims["exp"].(type) {
+	case int64:
+		expiresAt = time.Unix(exp, 0)
+	case int:
+		expiresAt = time.Unix(int64(exp), 0)
+	case float64:
+		expiresAt = time.Unix(int64(exp), 0)
+	default:
+		return nil
+	}
+
+	return gen.tracker.Track(jti, subject, expiresAt)
+}
+
+func newTokenId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
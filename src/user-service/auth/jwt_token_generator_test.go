@@ -6,15 +6,28 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/_mocks"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 )
 
 func TestJwtAuthorizer(t *testing.T) {
 	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	tokenGenerator := JwtTokenGenerator{privateKey}
+	keySet := &KeySet{}
+	keySet.set([]*ecdsa.PrivateKey{privateKey})
+	ctrl := gomock.NewController(t)
+	tracker := mocks.NewMockTokenTracker(ctrl)
+	tokenGenerator := JwtTokenGenerator{
+		keys:     keySet,
+		issuer:   "user-service",
+		audience: "shop",
+		tracker:  tracker,
+	}
 
 	t.Run("CreateToken", func(t *testing.T) {
 		t.Run("should generate valid JWT token", func(t *testing.T) {
@@ -30,6 +43,17 @@ func TestJwtAuthorizer(t *testing.T) {
 			tokenParts := strings.Split(token, ".")
 			assert.Len(t, tokenParts, 3)
 
+			h, _ := base64.
+				StdEncoding.
+				WithPadding(base64.NoPadding).
+				DecodeString(tokenParts[0])
+
+			var header map[string]interface{}
+			json.Unmarshal(h, &header)
+
+			assert.Equal(t, "ES256", header["alg"])
+			assert.Equal(t, keySet.signingKeyId, header["kid"])
+
 			b, _ := base64.
 				StdEncoding.
 				WithPadding(base64.NoPadding).
@@ -40,6 +64,45 @@ func TestJwtAuthorizer(t *testing.T) {
 
 			assert.Equal(t, float64(12345), claims["exp"])
 			assert.Equal(t, "test", claims["user"])
+			assert.Equal(t, "user-service", claims["iss"])
+			assert.Equal(t, "shop", claims["aud"])
+			assert.NotNil(t, claims["iat"])
+			assert.NotEmpty(t, claims["jti"])
+		})
+
+		t.Run("should track token of subject", func(t *testing.T) {
+			// given
+			tracker.
+				EXPECT().
+				Track(gomock.Any(), "1", time.Unix(12345, 0)).
+				Return(nil)
+
+			// when
+			token, err := tokenGenerator.CreateToken(map[string]interface{}{
+				"sub": "1",
+				"exp": int64(12345),
+			})
+
+			// then
+			assert.NoError(t, err)
+			assert.NotEmpty(t, token)
+		})
+
+		t.Run("should return error if token could not be tracked", func(t *testing.T) {
+			// given
+			tracker.
+				EXPECT().
+				Track(gomock.Any(), "1", time.Unix(12345, 0)).
+				Return(errors.New("database error"))
+
+			// when
+			_, err := tokenGenerator.CreateToken(map[string]interface{}{
+				"sub": "1",
+				"exp": int64(12345),
+			})
+
+			// then
+			assert.Error(t, err)
 		})
 	})
 }
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+)
+
+type KeySet struct {
+	config Config
+
+	mu           sync.RWMutex
+	signingKeyId string
+	signingKey   *ecdsa.PrivateKey
+	publicKeys   map[string]*ecdsa.PublicKey
+}
+
+func NewKeySet(config Config) (*KeySet, error) {
+	keySet := &KeySet{config: config}
+	if err := keySet.Reload(); err != nil {
+		return nil, err
+	}
+
+	return keySet, nil
+}
+
+// Reload reads the keys from the configuration again. Tokens signed by keys
+// which are not part of the configuration anymore can no longer be verified.
+func (keySet *KeySet) Reload() error {
+	keys, err := keySet.config.ReadPrivateKeys()
+	if err != nil {
+		return err
+	}
+
+	privateKeys := make([]*ecdsa.PrivateKey, len(keys))
+	for i, key := range keys {
+		privateKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return errors.New("private key is not an ECDSA key")
+		}
+
+		privateKeys[i] = privateKey
+	}
+
+	return keySet.set(privateKeys)
+}
+
+func (keySet *KeySet) set(privateKeys []*ecdsa.PrivateKey) error {
+	if len(privateKeys) == 0 {
+		return errors.New("key set must contain at least one key")
+	}
+
+	publicKeys := make(map[string]*ecdsa.PublicKey, len(privateKeys))
+	for _, privateKey := range privateKeys {
+		publicKeys[jwtauth.Thumbprint(&privateKey.PublicKey)] = &privateKey.PublicKey
+	}
+
+	signingKey := privateKeys[len(privateKeys)-1]
+
+	keySet.mu.Lock()
+	defer keySet.mu.Unlock()
+
+	keySet.signingKeyId = jwtauth.Thumbprint(&signingKey.PublicKey)
+	keySet.signingKey = signingKey
+	keySet.publicKeys = publicKeys
+	return nil
+}
+
+func (keySet *KeySet) SigningKey() (string, *ecdsa.PrivateKey) {
+	keySet.mu.RLock()
+	defer keySet.mu.RUnlock()
+
+	return keySet.signingKeyId, keySet.signingKey
+}
+
+func (keySet *KeySet) PublicKey(kid string) (*ecdsa.PublicKey, bool) {
+	keySet.mu.RLock()
+	defer keySet.mu.RUnlock()
+
+	publicKey, ok := keySet.publicKeys[kid]
+	return publicKey, ok
+}
+
+// KeySource returns the public keys as jwtauth.KeySource, so the user-service
+// verifies its own tokens with jwtauth.Verifier like every other service.
+// Tokens without a kid were issued before key rotation and are checked against
+// the signing key.
+func (keySet *KeySet) KeySource() jwtauth.KeySource {
+	return keySetSource{keySet}
+}
+
+type keySetSource struct {
+	keySet *KeySet
+}
+
+func (source keySetSource) PublicKey(kid string) (*ecdsa.PublicKey, error) {
+	if kid == "" {
+		_, signingKey := source.keySet.SigningKey()
+		return &signingKey.PublicKey, nil
+	}
+
+	publicKey, ok := source.keySet.PublicKey(kid)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", jwtauth.ErrUnknownKey, kid)
+	}
+
+	return publicKey, nil
+}
+
+// JWKS returns all public keys sorted by kid, so the published key set does
+// not change between requests.
+func (keySet *KeySet) JWKS() jwtauth.JSONWebKeySet {
+	keySet.mu.RLock()
+	defer keySet.mu.RUnlock()
+
+	jwks := jwtauth.JSONWebKeySet{Keys: []jwtauth.JSONWebKey{}}
+	for _, publicKey := range keySet.publicKeys {
+		jwks.Keys = append(jwks.Keys, jwtauth.NewJSONWebKey(publicKey))
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KeyId < jwks.Keys[j].KeyId })
+
+	return jwks
+}
@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKey(t *testing.T, path string) *ecdsa.PrivateKey {
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalECPrivateKey(privateKey)
+
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("could not write key: %s", err.Error())
+	}
+
+	return privateKey
+}
+
+func TestKeySet(t *testing.T) {
+	t.Run("NewKeySet", func(t *testing.T) {
+		t.Run("should return error if no keys are configured", func(t *testing.T) {
+			// given
+			config := JwtConfig{KeysDir: t.TempDir()}
+
+			// when
+			keySet, err := NewKeySet(config)
+
+			// then
+			assert.Error(t, err)
+			assert.Nil(t, keySet)
+		})
+
+		t.Run("should return error if key is not PEM encoded", func(t *testing.T) {
+			// given
+			path := filepath.Join(t.TempDir(), "key.pem")
+			os.WriteFile(path, []byte("not a key"), 0600)
+
+			// when
+			_, err := NewKeySet(JwtConfig{SignKey: path})
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should use sign key as signing key", func(t *testing.T) {
+			// given
+			dir := t.TempDir()
+			retiredKey := writeKey(t, filepath.Join(dir, "2023-01-01.pem"))
+
+			signKeyPath := filepath.Join(t.TempDir(), "key")
+			signKey := writeKey(t, signKeyPath)
+
+			// when
+			keySet, err := NewKeySet(JwtConfig{KeysDir: dir, SignKey: signKeyPath})
+
+			// then
+			assert.NoError(t, err)
+
+			kid, privateKey := keySet.SigningKey()
+			assert.Equal(t, signKey, privateKey)
+			assert.Equal(t, jwtauth.Thumbprint(&signKey.PublicKey), kid)
+
+			_, ok := keySet.PublicKey(jwtauth.Thumbprint(&retiredKey.PublicKey))
+			assert.True(t, ok)
+		})
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		t.Run("should rotate to the last key in the keys directory", func(t *testing.T) {
+			// given
+			dir := t.TempDir()
+			firstKey := writeKey(t, filepath.Join(dir, "2023-01-01.pem"))
+			keySet, _ := NewKeySet(JwtConfig{KeysDir: dir})
+
+			// when
+			secondKey := writeKey(t, filepath.Join(dir, "2023-02-01.pem"))
+			err := keySet.Reload()
+
+			// then
+			assert.NoError(t, err)
+
+			_, signingKey := keySet.SigningKey()
+			assert.Equal(t, secondKey, signingKey)
+
+			_, ok := keySet.PublicKey(jwtauth.Thumbprint(&firstKey.PublicKey))
+			assert.True(t, ok)
+		})
+
+		t.Run("should keep current keys if reload fails", func(t *testing.T) {
+			// given
+			dir := t.TempDir()
+			firstKey := writeKey(t, filepath.Join(dir, "2023-01-01.pem"))
+			keySet, _ := NewKeySet(JwtConfig{KeysDir: dir})
+
+			// when
+			os.WriteFile(filepath.Join(dir, "2023-02-01.pem"), []byte("broken"), 0600)
+			err := keySet.Reload()
+
+			// then
+			assert.Error(t, err)
+
+			_, signingKey := keySet.SigningKey()
+			assert.Equal(t, firstKey, signingKey)
+		})
+	})
+
+	t.Run("KeySource", func(t *testing.T) {
+		dir := t.TempDir()
+		firstKey := writeKey(t, filepath.Join(dir, "2023-01-01.pem"))
+		secondKey := writeKey(t, filepath.Join(dir, "2023-02-01.pem"))
+		keySet, _ := NewKeySet(JwtConfig{KeysDir: dir})
+		source := keySet.KeySource()
+
+		t.Run("should return public key by kid", func(t *testing.T) {
+			// when
+			publicKey, err := source.PublicKey(jwtauth.Thumbprint(&firstKey.PublicKey))
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, &firstKey.PublicKey, publicKey)
+		})
+
+		t.Run("should return signing key if token has no kid", func(t *testing.T) {
+			// when
+			publicKey, err := source.PublicKey("")
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, &secondKey.PublicKey, publicKey)
+		})
+
+		t.Run("should return ErrUnknownKey for unknown kid", func(t *testing.T) {
+			// when
+			publicKey, err := source.PublicKey("unknown")
+
+			// then
+			assert.ErrorIs(t, err, jwtauth.ErrUnknownKey)
+			assert.Nil(t, publicKey)
+		})
+	})
+
+	t.Run("JWKS", func(t *testing.T) {
+		t.Run("should publish all public keys", func(t *testing.T) {
+			// given
+			dir := t.TempDir()
+			firstKey := writeKey(t, filepath.Join(dir, "2023-01-01.pem"))
+			secondKey := writeKey(t, filepath.Join(dir, "2023-02-01.pem"))
+			keySet, _ := NewKeySet(JwtConfig{KeysDir: dir})
+
+			// when
+			jwks := keySet.JWKS()
+
+			// then
+			assert.Len(t, jwks.Keys, 2)
+			assert.ElementsMatch(t,
+				[]string{jwtauth.Thumbprint(&firstKey.PublicKey), jwtauth.Thumbprint(&secondKey.PublicKey)},
+				[]string{jwks.Keys[0].KeyId, jwks.Keys[1].KeyId})
+		})
+
+		t.Run("should sort keys by kid", func(t *testing.T) {
+			// given
+			dir := t.TempDir()
+			for _, name := range []string{"2023-01-01.pem", "2023-02-01.pem", "2023-03-01.pem", "2023-04-01.pem"} {
+				writeKey(t, filepath.Join(dir, name))
+			}
+			keySet, _ := NewKeySet(JwtConfig{KeysDir: dir})
+
+			// when
+			jwks := keySet.JWKS()
+
+			// then
+			assert.Len(t, jwks.Keys, 4)
+			assert.IsIncreasing(t, []string{jwks.Keys[0].KeyId, jwks.Keys[1].KeyId, jwks.Keys[2].KeyId, jwks.Keys[3].KeyId})
+		})
+	})
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+
+	_ "github.com/lib/pq"
+)
+
+type PsqlRevocationList struct {
+	db *sql.DB
+}
+
+func NewPsqlRevocationList(db *sql.DB) *PsqlRevocationList {
+	return &PsqlRevocationList{db}
+}
+
+const createRevokedTokensTable = `
+create table if not exists revoked_tokens (
+	jti        varchar(64) not null,
+	expires_at timestamptz not null,
+	primary key (jti)
+);
+create index if not exists revoked_tokens_expires_at_idx on revoked_tokens (expires_at);
+create table if not exists issued_tokens (
+	jti        varchar(64) not null,
+	subject    varchar(100) not null,
+	expires_at timestamptz not null,
+	primary key (jti)
+);
+create index if not exists issued_tokens_subject_idx on issued_tokens (subject);
+`
+
+func (list *PsqlRevocationList) Migrate() error {
+	_, err := list.db.Exec(createRevokedTokensTable)
+	return err
+}
+
+const deleteExpiredRevokedTokensQuery = `
+delete from revoked_tokens where expires_at <= now()
+`
+
+const revokeTokenQuery = `
+insert into revoked_tokens (jti, expires_at) values ($1, $2) on conflict (jti) do nothing
+`
+
+// Revoke adds the token id to the list until the token expires on its own.
+// Entries of already expired tokens are removed on the way.
+func (list *PsqlRevocationList) Revoke(jti string, expiresAt time.Time) error {
+	if _, err := list.db.Exec(deleteExpiredRevokedTokensQuery); err != nil {
+		return err
+	}
+
+	_, err := list.db.Exec(revokeTokenQuery, jti, expiresAt)
+	return err
+}
+
+const deleteExpiredIssuedTokensQuery = `
+delete from issued_tokens where subject = $1 and expires_at <= now()
+`
+
+const trackTokenQuery = `
+insert into issued_tokens (jti, subject, expires_at) values ($1, $2, $3)
+`
+
+// Track records a token issued to the subject until it expires on its own.
+// Expired entries of the subject are removed on the way.
+func (list *PsqlRevocationList) Track(jti string, subject string, expiresAt time.Time) error {
+	if _, err := list.db.Exec(deleteExpiredIssuedTokensQuery, subject); err != nil {
+		return err
+	}
+
+	_, err := list.db.Exec(trackTokenQuery, jti, subject, expiresAt)
+	return err
+}
+
+const revokeSubjectQuery = `
+insert into revoked_tokens (jti, expires_at)
+select jti, expires_at from issued_tokens where subject = $1 and expires_at > now()
+on conflict (jti) do nothing
+`
+
+// RevokeSubject revokes every tracked token of the subject which has not
+// expired yet.
+func (list *PsqlRevocationList) RevokeSubject(subject string) error {
+	_, err := list.db.Exec(revokeSubjectQuery, subject)
+	return err
+}
+
+const isTokenRevokedQuery = `
+select exists (select 1 from revoked_tokens where jti = $1 and expires_at > now())
+`
+
+func (list *PsqlRevocationList) IsRevoked(jti string) (bool, error) {
+	var revoked bool
+	if err := list.db.QueryRow(isTokenRevokedQuery, jti).Scan(&revoked); err != nil {
+		return false, err
+	}
+
+	return revoked, nil
+}
+
+const listRevokedTokensQuery = `
+select jti, expires_at from revoked_tokens where expires_at > now() order by expires_at
+`
+
+// List returns the revoked tokens which have not expired yet.
+func (list *PsqlRevocationList) List() ([]jwtauth.RevokedToken, error) {
+	rows, err := list.db.Query(listRevokedTokensQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []jwtauth.RevokedToken{}
+	for rows.Next() {
+		var jti string
+		var expiresAt time.Time
+		if err := rows.Scan(&jti, &expiresAt); err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, jwtauth.RevokedToken{Id: jti, ExpiresAt: expiresAt.Unix()})
+	}
+
+	return tokens, rows.Err()
+}
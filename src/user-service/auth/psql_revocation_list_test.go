@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPsqlRevocationList(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revocationList := PsqlRevocationList{db}
+
+	t.Run("Revoke", func(t *testing.T) {
+		t.Run("should return error if expired entries could not be deleted", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectExec(`delete from revoked_tokens where expires_at <= now\(\)`).
+				WillReturnError(errors.New("database error"))
+
+			// when
+			err := revocationList.Revoke("jti", time.Now())
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should insert token id", func(t *testing.T) {
+			// given
+			expiresAt := time.Now().Add(time.Hour)
+			dbmock.
+				ExpectExec(`delete from revoked_tokens where expires_at <= now\(\)`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			dbmock.
+				ExpectExec(`insert into revoked_tokens \(jti, expires_at\) values \(\$1, \$2\) on conflict \(jti\) do nothing`).
+				WithArgs("jti", expiresAt).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			// when
+			err := revocationList.Revoke("jti", expiresAt)
+
+			// then
+			assert.NoError(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+	})
+
+	t.Run("IsRevoked", func(t *testing.T) {
+		t.Run("should return error if executing query failed", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectQuery(`select exists \(select 1 from revoked_tokens where jti = \$1 and expires_at > now\(\)\)`).
+				WithArgs("jti").
+				WillReturnError(errors.New("database error"))
+
+			// when
+			_, err := revocationList.IsRevoked("jti")
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should return whether token id is revoked", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectQuery(`select exists \(select 1 from revoked_tokens where jti = \$1 and expires_at > now\(\)\)`).
+				WithArgs("jti").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+			// when
+			revoked, err := revocationList.IsRevoked("jti")
+
+			// then
+			assert.NoError(t, err)
+			assert.True(t, revoked)
+		})
+	})
+	t.Run("Track", func(t *testing.T) {
+		t.Run("should return error if deleting expired tokens failed", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectExec(`delete from issued_tokens where subject = \$1 and expires_at <= now\(\)`).
+				WithArgs("1").
+				WillReturnError(errors.New("database error"))
+
+			// when
+			err := revocationList.Track("jti", "1", time.Now())
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should insert issued token", func(t *testing.T) {
+			// given
+			expiresAt := time.Now().Add(time.Hour)
+			dbmock.
+				ExpectExec(`delete from issued_tokens where subject = \$1 and expires_at <= now\(\)`).
+				WithArgs("1").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			dbmock.
+				ExpectExec(`insert into issued_tokens \(jti, subject, expires_at\) values \(\$1, \$2, \$3\)`).
+				WithArgs("jti", "1", expiresAt).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			// when
+			err := revocationList.Track("jti", "1", expiresAt)
+
+			// then
+			assert.NoError(t, err)
+		})
+	})
+	t.Run("RevokeSubject", func(t *testing.T) {
+		t.Run("should return error if executing query failed", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectExec(`insert into revoked_tokens \(jti, expires_at\) select jti, expires_at from issued_tokens where subject = \$1`).
+				WithArgs("1").
+				WillReturnError(errors.New("database error"))
+
+			// when
+			err := revocationList.RevokeSubject("1")
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should revoke tracked tokens of subject", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectExec(`insert into revoked_tokens \(jti, expires_at\) select jti, expires_at from issued_tokens where subject = \$1`).
+				WithArgs("1").
+				WillReturnResult(sqlmock.NewResult(0, 2))
+
+			// when
+			err := revocationList.RevokeSubject("1")
+
+			// then
+			assert.NoError(t, err)
+		})
+	})
+	t.Run("List", func(t *testing.T) {
+		t.Run("should return error if executing query failed", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectQuery(`select jti, expires_at from revoked_tokens where expires_at > now\(\)`).
+				WillReturnError(errors.New("database error"))
+
+			// when
+			_, err := revocationList.List()
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should return tokens which have not expired", func(t *testing.T) {
+			// given
+			expiresAt := time.Now().Add(time.Hour)
+			dbmock.
+				ExpectQuery(`select jti, expires_at from revoked_tokens where expires_at > now\(\)`).
+				WillReturnRows(sqlmock.NewRows([]string{"jti", "expires_at"}).AddRow("jti", expiresAt))
+
+			// when
+			tokens, err := revocationList.List()
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, []jwtauth.RevokedToken{{Id: "jti", ExpiresAt: expiresAt.Unix()}}, tokens)
+		})
+	})
+}
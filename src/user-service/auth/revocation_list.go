@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+)
+
+type RevocationList interface {
+	Revoke(jti string, expiresAt time.Time) error
+	RevokeSubject(subject string) error
+	IsRevoked(jti string) (bool, error)
+	List() ([]jwtauth.RevokedToken, error)
+}
+
+// TokenTracker records the issued tokens of a subject, so that they can be
+// revoked all at once by RevokeSubject.
+type TokenTracker interface {
+	Track(jti string, subject string, expiresAt time.Time) error
+}
@@ -1,24 +1,87 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/cors"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/database"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/health"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/jwtauth"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/metrics"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/middleware"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/ratelimit"
+	librouter "github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/router"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/server"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/tracing"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/api/handler"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/api/router"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/auth"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/crypto"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user"
 	"gopkg.in/yaml.v3"
 )
 
 type ApplicationConfig struct {
-	Database database.PsqlConfig `yaml:"database"`
-	Jwt      auth.JwtConfig      `yaml:"jwt"`
+	Database  database.PsqlConfig `yaml:"database"`
+	Jwt       auth.JwtConfig      `yaml:"jwt"`
+	Tokens    TokensConfig        `yaml:"tokens"`
+	Tracing   TracingConfig       `yaml:"tracing"`
+	RateLimit RateLimitConfig     `yaml:"rateLimit"`
+	Cors      cors.Config         `yaml:"cors"`
+	Server    server.Config       `yaml:"server"`
+}
+
+type TokensConfig struct {
+	AccessTokenTtl  time.Duration `yaml:"accessTokenTtl"`
+	RefreshTokenTtl time.Duration `yaml:"refreshTokenTtl"`
+}
+
+type TracingConfig struct {
+	Exporter string `yaml:"exporter"`
+}
+
+type RateLimitConfig struct {
+	Store             string        `yaml:"store"`
+	Requests          int           `yaml:"requests"`
+	Period            time.Duration `yaml:"period"`
+	Burst             int           `yaml:"burst"`
+	TrustForwardedFor bool          `yaml:"trustForwardedFor"`
+	FailOpen          bool          `yaml:"failOpen"`
+}
+
+// Middleware limits the requests of a single client. Behind the api-gateway
+// clients can only be told apart by the address it adds to X-Forwarded-For.
+func (config RateLimitConfig) Middleware(logger *slog.Logger) (*ratelimit.Middleware, error) {
+	store, err := ratelimit.NewStore(config.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	key := ratelimit.ClientIP
+	if config.TrustForwardedFor {
+		key = ratelimit.ForwardedClientIP
+	}
+
+	limit := ratelimit.Limit{
+		Requests: config.Requests,
+		Period:   config.Period,
+		Burst:    config.Burst,
+	}
+
+	return ratelimit.NewMiddleware(store, "auth", limit,
+		ratelimit.WithKey(key),
+		ratelimit.WithFailOpen(config.FailOpen),
+		ratelimit.WithLogger(logger))
 }
 
 func LoadConfigFromFile(path string) (*ApplicationConfig, error) {
@@ -27,7 +90,17 @@ func LoadConfigFromFile(path string) (*ApplicationConfig, error) {
 		return nil, err
 	}
 
-	var config ApplicationConfig
+	config := ApplicationConfig{
+		Tokens: TokensConfig{
+			AccessTokenTtl:  1 * time.Hour,
+			RefreshTokenTtl: 30 * 24 * time.Hour,
+		},
+		RateLimit: RateLimitConfig{
+			Requests: 10,
+			Period:   time.Minute,
+		},
+		Server: server.DefaultConfig(),
+	}
 	if err := yaml.NewDecoder(f).Decode(&config); err != nil {
 		return nil, err
 	}
@@ -35,8 +108,23 @@ func LoadConfigFromFile(path string) (*ApplicationConfig, error) {
 	return &config, nil
 }
 
+func reloadKeysOnHangup(keySet *auth.KeySet) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	for range hangup {
+		if err := keySet.Reload(); err != nil {
+			log.Printf("could not reload JWT signing keys: %s", err.Error())
+			continue
+		}
+
+		log.Printf("reloaded JWT signing keys")
+	}
+}
+
 func main() {
 	port := flag.String("port", "8080", "The listening port")
+	adminPort := flag.String("admin-port", "9000", "The listening port of the admin endpoints")
 	configPath := flag.String("config", "config.yml", "The path to the configuration file")
 	flag.Parse()
 
@@ -45,29 +133,109 @@ func main() {
 		log.Fatalf("could not load application configuration: %s", err.Error())
 	}
 
-	userRepository, err := user.NewPsqlRepository(config.Database)
+	db, err := sql.Open("postgres", config.Database.Dsn())
 	if err != nil {
-		log.Fatalf("could not create user repository: %s", err.Error())
+		log.Fatalf("could not open database: %s", err.Error())
 	}
 
+	userRepository := user.NewPsqlRepository(db)
 	if err := userRepository.Migrate(); err != nil {
 		log.Fatalf("could not migrate: %s", err.Error())
 	}
 
-	tokenGenerator, err := auth.NewJwtTokenGenerator(config.Jwt)
+	sessionRepository := session.NewPsqlRepository(db)
+	if err := sessionRepository.Migrate(); err != nil {
+		log.Fatalf("could not migrate: %s", err.Error())
+	}
+
+	revocationList := auth.NewPsqlRevocationList(db)
+	if err := revocationList.Migrate(); err != nil {
+		log.Fatalf("could not migrate: %s", err.Error())
+	}
+
+	keySet, err := auth.NewKeySet(config.Jwt)
 	if err != nil {
-		log.Fatalf("could not create JWT token generator: %s", err.Error())
+		log.Fatalf("could not load JWT signing keys: %s", err.Error())
 	}
 
+	go reloadKeysOnHangup(keySet)
+
+	tokenGenerator := auth.NewJwtTokenGenerator(config.Jwt, keySet, revocationList)
+	tokenVerifier := jwtauth.NewKeySourceVerifier(keySet.KeySource(),
+		jwtauth.WithIssuer(config.Jwt.Issuer()),
+		jwtauth.WithAudience(config.Jwt.Audience()),
+		jwtauth.WithRevocationList(revocationList),
+	)
+
 	hasher := crypto.NewBcryptHasher()
+	sessionManager := session.NewRefreshTokenManager(sessionRepository, config.Tokens.RefreshTokenTtl)
+
+	exporter, err := tracing.NewExporter(config.Tracing.Exporter)
+	if err != nil {
+		log.Fatalf("could not create trace exporter: %s", err.Error())
+	}
+
+	tracer := tracing.NewTracer("user-service", exporter)
+
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, nil)))
+	metrics.Default.RegisterDBStats(map[string]func() sql.DBStats{"users": db.Stats})
+
+	authLimit, err := config.RateLimit.Middleware(logger)
+	if err != nil {
+		log.Fatalf("could not create rate limit: %s", err.Error())
+	}
+
+	traces := tracing.Middleware(tracer)
+	httpMetrics := metrics.NewHTTPMetrics(metrics.Default).Handler
+	accessLog := middleware.AccessLog(logger)
+	recoverer := middleware.Recover(logger)
+
+	adminHandler := router.NewAdmin(
+		handler.NewRevokeSessionsHandler(sessionManager, revocationList, logger),
+		handler.NewUserRoleHandler(userRepository, sessionManager, revocationList, logger),
+		metrics.Handler(),
+		logger,
+		traces, httpMetrics, accessLog, jwtauth.NewMiddleware(tokenVerifier).Handler, recoverer,
+	)
+
+	middlewares := []librouter.Middleware{traces, httpMetrics, accessLog}
+	if config.Cors.Enabled() {
+		corsMiddleware, err := cors.New(config.Cors)
+		if err != nil {
+			log.Fatalf("invalid cors configuration: %s", err.Error())
+		}
+		middlewares = append(middlewares, corsMiddleware.Handler)
+	}
+	middlewares = append(middlewares, recoverer)
 
+	healthHandler := health.NewHandler(userRepository)
 	handler := router.New(
 		handler.NewRegisterHandler(userRepository, hasher),
-		handler.NewLoginHandler(userRepository, hasher, tokenGenerator),
+		handler.NewLoginHandler(userRepository, hasher, tokenGenerator, sessionManager, config.Tokens.AccessTokenTtl, logger),
+		handler.NewRefreshHandler(userRepository, tokenGenerator, sessionManager, config.Tokens.AccessTokenTtl, logger),
+		handler.NewLogoutHandler(tokenVerifier, revocationList, sessionManager, logger),
+		handler.NewProfileHandler(tokenVerifier, userRepository, logger),
+		handler.NewJwksHandler(keySet),
+		handler.NewRevokedTokensHandler(revocationList, logger),
+		healthHandler,
+		authLimit.Handler,
+		middlewares...,
 	)
 
-	addr := fmt.Sprintf("0.0.0.0:%s", *port)
-	if err := http.ListenAndServe(addr, handler); err != nil {
-		log.Fatalf("error while listen and serve: %s", err.Error())
+	runner := server.NewRunner(config.Server)
+	if err := runner.Listen(fmt.Sprintf("0.0.0.0:%s", *port), handler); err != nil {
+		log.Fatalf("error while listen: %s", err.Error())
+	}
+
+	if err := runner.Listen(fmt.Sprintf("0.0.0.0:%s", *adminPort), adminHandler); err != nil {
+		log.Fatalf("error while listen on admin port: %s", err.Error())
+	}
+
+	runner.AddReadiness(healthHandler)
+	runner.OnShutdown(server.Close(db))
+	runner.OnShutdown(tracer.Shutdown)
+
+	if err := runner.Run(context.Background()); err != nil {
+		log.Fatalf("error while shutting down: %s", err.Error())
 	}
 }
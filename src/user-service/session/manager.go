@@ -0,0 +1,8 @@
+package session
+
+type Manager interface {
+	Create(subject string) (string, error)
+	Rotate(refreshToken string) (subject string, newRefreshToken string, err error)
+	Revoke(refreshToken string) error
+	RevokeAll(subject string) error
+}
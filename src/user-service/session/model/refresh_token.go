@@ -0,0 +1,15 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+type DbRefreshToken struct {
+	TokenHash []byte
+	FamilyId  string
+	Subject   string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	RevokedAt sql.NullTime
+}
@@ -0,0 +1,103 @@
+package session
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session/model"
+
+	_ "github.com/lib/pq"
+)
+
+type PsqlRepository struct {
+	db *sql.DB
+}
+
+func NewPsqlRepository(db *sql.DB) *PsqlRepository {
+	return &PsqlRepository{db}
+}
+
+const createRefreshTokensTable = `
+create table if not exists refresh_tokens (
+	token_hash bytea        not null,
+	family_id  varchar(64)  not null,
+	subject    varchar(100) not null,
+	expires_at timestamptz  not null,
+	used_at    timestamptz,
+	revoked_at timestamptz,
+	primary key (token_hash)
+);
+create index if not exists refresh_tokens_family_id_idx on refresh_tokens (family_id);
+create index if not exists refresh_tokens_subject_idx on refresh_tokens (subject);
+`
+
+func (repo *PsqlRepository) Migrate() error {
+	_, err := repo.db.Exec(createRefreshTokensTable)
+	return err
+}
+
+const createRefreshTokenQuery = `
+insert into refresh_tokens (token_hash, family_id, subject, expires_at) values ($1, $2, $3, $4)
+`
+
+func (repo *PsqlRepository) Create(token *model.DbRefreshToken) error {
+	_, err := repo.db.Exec(createRefreshTokenQuery, token.TokenHash, token.FamilyId, token.Subject, token.ExpiresAt)
+	return err
+}
+
+const findRefreshTokenByHashQuery = `
+select token_hash, family_id, subject, expires_at, used_at, revoked_at from refresh_tokens where token_hash = $1
+`
+
+func (repo *PsqlRepository) FindByHash(hash []byte) (*model.DbRefreshToken, error) {
+	row := repo.db.QueryRow(findRefreshTokenByHashQuery, hash)
+
+	var token model.DbRefreshToken
+	if err := row.Scan(&token.TokenHash, &token.FamilyId, &token.Subject, &token.ExpiresAt, &token.UsedAt, &token.RevokedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+const markRefreshTokenUsedQuery = `
+update refresh_tokens set used_at = now() where token_hash = $1 and used_at is null and revoked_at is null
+`
+
+// MarkUsed marks the token as used and reports whether it was unused before.
+// Two concurrent calls for the same token never both return true.
+func (repo *PsqlRepository) MarkUsed(hash []byte) (bool, error) {
+	result, err := repo.db.Exec(markRefreshTokenUsedQuery, hash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+const revokeRefreshTokenFamilyQuery = `
+update refresh_tokens set revoked_at = now() where family_id = $1 and revoked_at is null
+`
+
+func (repo *PsqlRepository) RevokeFamily(familyId string) error {
+	_, err := repo.db.Exec(revokeRefreshTokenFamilyQuery, familyId)
+	return err
+}
+
+const revokeRefreshTokenSubjectQuery = `
+update refresh_tokens set revoked_at = now() where subject = $1 and revoked_at is null
+`
+
+func (repo *PsqlRepository) RevokeSubject(subject string) error {
+	_, err := repo.db.Exec(revokeRefreshTokenSubjectQuery, subject)
+	return err
+}
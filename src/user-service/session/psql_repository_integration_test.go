@@ -0,0 +1,97 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/containerhelpers"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/database"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationPsqlRepository(t *testing.T) {
+	postgres, err := containerhelpers.StartPostgres()
+	if err != nil {
+		t.Fatalf("could not start postgres container: %s", err.Error())
+	}
+
+	t.Cleanup(func() {
+		postgres.Terminate(context.Background())
+	})
+
+	port, err := postgres.MappedPort(context.Background(), "5432")
+	if err != nil {
+		t.Fatalf("could not get database container port: %s", err.Error())
+	}
+
+	db, err := sql.Open("postgres", database.PsqlConfig{
+		Host:     "0.0.0.0",
+		Port:     port.Int(),
+		Username: "postgres",
+		Password: "postgres",
+		Database: "postgres",
+	}.Dsn())
+	if err != nil {
+		t.Fatalf("could not open database: %s", err.Error())
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repository := NewPsqlRepository(db)
+
+	t.Run("Migrate", func(t *testing.T) {
+		t.Run("should create refresh tokens table", func(t *testing.T) {
+			// given
+			// when
+			err := repository.Migrate()
+
+			// then
+			assert.NoError(t, err)
+		})
+	})
+
+	t.Run("Rotation", func(t *testing.T) {
+		t.Run("should mark token as used only once and revoke its family", func(t *testing.T) {
+			t.Cleanup(clearTables(t, repository.db))
+
+			// given
+			expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+			for _, hash := range []string{"first", "second"} {
+				err := repository.Create(&model.DbRefreshToken{
+					TokenHash: []byte(hash),
+					FamilyId:  "family",
+					Subject:   "test@test.com",
+					ExpiresAt: expiresAt,
+				})
+				assert.NoError(t, err)
+			}
+
+			// when
+			firstUse, err1 := repository.MarkUsed([]byte("first"))
+			secondUse, err2 := repository.MarkUsed([]byte("first"))
+			err3 := repository.RevokeFamily("family")
+			token, err4 := repository.FindByHash([]byte("second"))
+
+			// then
+			assert.NoError(t, err1)
+			assert.NoError(t, err2)
+			assert.NoError(t, err3)
+			assert.NoError(t, err4)
+			assert.True(t, firstUse)
+			assert.False(t, secondUse)
+			assert.True(t, expiresAt.Equal(token.ExpiresAt))
+			assert.True(t, token.RevokedAt.Valid)
+		})
+	})
+}
+
+func clearTables(t *testing.T, db *sql.DB) func() {
+	return func() {
+		if _, err := db.Exec("delete from refresh_tokens"); err != nil {
+			t.Logf("could not delete rows from refresh_tokens: %s", err.Error())
+			t.FailNow()
+		}
+	}
+}
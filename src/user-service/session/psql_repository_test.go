@@ -0,0 +1,161 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPsqlRepository(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository := PsqlRepository{db}
+
+	t.Run("Create", func(t *testing.T) {
+		t.Run("should insert refresh token", func(t *testing.T) {
+			// given
+			expiresAt := time.Now()
+			dbmock.
+				ExpectExec(`insert into refresh_tokens \(token_hash, family_id, subject, expires_at\) values \(\$1, \$2, \$3, \$4\)`).
+				WithArgs([]byte("hash"), "family", "test@test.com", expiresAt).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			// when
+			err := repository.Create(&model.DbRefreshToken{
+				TokenHash: []byte("hash"),
+				FamilyId:  "family",
+				Subject:   "test@test.com",
+				ExpiresAt: expiresAt,
+			})
+
+			// then
+			assert.NoError(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+	})
+
+	t.Run("FindByHash", func(t *testing.T) {
+		t.Run("should return error if executing query failed", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectQuery(`select token_hash, family_id, subject, expires_at, used_at, revoked_at from refresh_tokens where token_hash = \$1`).
+				WithArgs([]byte("hash")).
+				WillReturnError(errors.New("database error"))
+
+			// when
+			token, err := repository.FindByHash([]byte("hash"))
+
+			// then
+			assert.Error(t, err)
+			assert.Nil(t, token)
+		})
+
+		t.Run("should return nil if token does not exist", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectQuery(`select token_hash, family_id, subject, expires_at, used_at, revoked_at from refresh_tokens where token_hash = \$1`).
+				WithArgs([]byte("hash")).
+				WillReturnRows(sqlmock.NewRows([]string{"token_hash", "family_id", "subject", "expires_at", "used_at", "revoked_at"}))
+
+			// when
+			token, err := repository.FindByHash([]byte("hash"))
+
+			// then
+			assert.NoError(t, err)
+			assert.Nil(t, token)
+		})
+
+		t.Run("should return token", func(t *testing.T) {
+			// given
+			expiresAt := time.Now()
+			dbmock.
+				ExpectQuery(`select token_hash, family_id, subject, expires_at, used_at, revoked_at from refresh_tokens where token_hash = \$1`).
+				WithArgs([]byte("hash")).
+				WillReturnRows(sqlmock.NewRows([]string{"token_hash", "family_id", "subject", "expires_at", "used_at", "revoked_at"}).
+					AddRow([]byte("hash"), "family", "test@test.com", expiresAt, nil, nil))
+
+			// when
+			token, err := repository.FindByHash([]byte("hash"))
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, "family", token.FamilyId)
+			assert.Equal(t, "test@test.com", token.Subject)
+			assert.Equal(t, expiresAt, token.ExpiresAt)
+			assert.False(t, token.UsedAt.Valid)
+			assert.False(t, token.RevokedAt.Valid)
+		})
+	})
+
+	t.Run("MarkUsed", func(t *testing.T) {
+		t.Run("should return true if token was unused", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectExec(`update refresh_tokens set used_at = now\(\) where token_hash = \$1 and used_at is null and revoked_at is null`).
+				WithArgs([]byte("hash")).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			// when
+			unused, err := repository.MarkUsed([]byte("hash"))
+
+			// then
+			assert.NoError(t, err)
+			assert.True(t, unused)
+		})
+
+		t.Run("should return false if token was already used", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectExec(`update refresh_tokens set used_at = now\(\) where token_hash = \$1 and used_at is null and revoked_at is null`).
+				WithArgs([]byte("hash")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			// when
+			unused, err := repository.MarkUsed([]byte("hash"))
+
+			// then
+			assert.NoError(t, err)
+			assert.False(t, unused)
+		})
+	})
+
+	t.Run("RevokeFamily", func(t *testing.T) {
+		t.Run("should revoke all tokens of the family", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectExec(`update refresh_tokens set revoked_at = now\(\) where family_id = \$1 and revoked_at is null`).
+				WithArgs("family").
+				WillReturnResult(sqlmock.NewResult(0, 3))
+
+			// when
+			err := repository.RevokeFamily("family")
+
+			// then
+			assert.NoError(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+	})
+	t.Run("RevokeSubject", func(t *testing.T) {
+		t.Run("should revoke all tokens of the subject", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectExec(`update refresh_tokens set revoked_at = now\(\) where subject = \$1 and revoked_at is null`).
+				WithArgs("test@test.com").
+				WillReturnResult(sqlmock.NewResult(0, 2))
+
+			// when
+			err := repository.RevokeSubject("test@test.com")
+
+			// then
+			assert.NoError(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+	})
+}
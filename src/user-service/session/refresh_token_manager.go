@@ -0,0 +1,125 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session/model"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+)
+
+type RefreshTokenManager struct {
+	repository Repository
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+func NewRefreshTokenManager(repository Repository, ttl time.Duration) *RefreshTokenManager {
+	return &RefreshTokenManager{
+		repository: repository,
+		ttl:        ttl,
+		now:        time.Now,
+	}
+}
+
+func (manager *RefreshTokenManager) Create(subject string) (string, error) {
+	familyId, err := randomString(16)
+	if err != nil {
+		return "", err
+	}
+
+	return manager.issue(subject, familyId)
+}
+
+// Rotate exchanges a refresh token for a new one of the same family. Every
+// refresh token can only be used once. If a used token is presented again, it
+// has probably been stolen, so the whole family is revoked.
+func (manager *RefreshTokenManager) Rotate(refreshToken string) (string, string, error) {
+	hash := hashToken(refreshToken)
+
+	token, err := manager.repository.FindByHash(hash)
+	if err != nil {
+		return "", "", err
+	}
+
+	if token == nil || token.RevokedAt.Valid || !manager.now().Before(token.ExpiresAt) {
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	unused, err := manager.repository.MarkUsed(hash)
+	if err != nil {
+		return "", "", err
+	}
+
+	if token.UsedAt.Valid || !unused {
+		if err := manager.repository.RevokeFamily(token.FamilyId); err != nil {
+			return "", "", err
+		}
+
+		return "", "", ErrRefreshTokenReused
+	}
+
+	newRefreshToken, err := manager.issue(token.Subject, token.FamilyId)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token.Subject, newRefreshToken, nil
+}
+
+// Revoke ends the session of the refresh token by revoking its whole family.
+func (manager *RefreshTokenManager) Revoke(refreshToken string) error {
+	token, err := manager.repository.FindByHash(hashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		return ErrInvalidRefreshToken
+	}
+
+	return manager.repository.RevokeFamily(token.FamilyId)
+}
+
+func (manager *RefreshTokenManager) RevokeAll(subject string) error {
+	return manager.repository.RevokeSubject(subject)
+}
+
+func (manager *RefreshTokenManager) issue(subject string, familyId string) (string, error) {
+	refreshToken, err := randomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	if err := manager.repository.Create(&model.DbRefreshToken{
+		TokenHash: hashToken(refreshToken),
+		FamilyId:  familyId,
+		Subject:   subject,
+		ExpiresAt: manager.now().Add(manager.ttl),
+	}); err != nil {
+		return "", err
+	}
+
+	return refreshToken, nil
+}
+
+func hashToken(refreshToken string) []byte {
+	hash := sha256.Sum256([]byte(refreshToken))
+	return hash[:]
+}
+
+func randomString(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
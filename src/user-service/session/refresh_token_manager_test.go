@@ -0,0 +1,242 @@
+package session
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	mocks "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/_mocks"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRefreshTokenManager(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	now := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	repository := mocks.NewMockSessionRepository(ctrl)
+	manager := NewRefreshTokenManager(repository, time.Hour)
+	manager.now = func() time.Time { return now }
+
+	t.Run("Create", func(t *testing.T) {
+		t.Run("should store hashed refresh token", func(t *testing.T) {
+			// given
+			var stored *model.DbRefreshToken
+			repository.
+				EXPECT().
+				Create(gomock.Any()).
+				DoAndReturn(func(token *model.DbRefreshToken) error {
+					stored = token
+					return nil
+				})
+
+			// when
+			refreshToken, err := manager.Create("test@test.com")
+
+			// then
+			assert.NoError(t, err)
+			assert.NotEmpty(t, refreshToken)
+			assert.Equal(t, hashToken(refreshToken), stored.TokenHash)
+			assert.NotEmpty(t, stored.FamilyId)
+			assert.Equal(t, "test@test.com", stored.Subject)
+			assert.Equal(t, now.Add(time.Hour), stored.ExpiresAt)
+		})
+
+		t.Run("should return error if refresh token could not be stored", func(t *testing.T) {
+			// given
+			repository.
+				EXPECT().
+				Create(gomock.Any()).
+				Return(errors.New("database error"))
+
+			// when
+			_, err := manager.Create("test@test.com")
+
+			// then
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		t.Run("should return error if refresh token is unknown", func(t *testing.T) {
+			// given
+			repository.
+				EXPECT().
+				FindByHash(hashToken("unknown")).
+				Return(nil, nil)
+
+			// when
+			_, _, err := manager.Rotate("unknown")
+
+			// then
+			assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+		})
+
+		t.Run("should return error if refresh token is expired or revoked", func(t *testing.T) {
+			tests := []*model.DbRefreshToken{
+				{FamilyId: "family", ExpiresAt: now},
+				{FamilyId: "family", ExpiresAt: now.Add(time.Hour), RevokedAt: sql.NullTime{Time: now, Valid: true}},
+			}
+
+			for _, test := range tests {
+				// given
+				repository.
+					EXPECT().
+					FindByHash(hashToken("token")).
+					Return(test, nil)
+
+				// when
+				_, _, err := manager.Rotate("token")
+
+				// then
+				assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+			}
+		})
+
+		t.Run("should revoke token family if refresh token is reused", func(t *testing.T) {
+			// given
+			repository.
+				EXPECT().
+				FindByHash(hashToken("token")).
+				Return(&model.DbRefreshToken{
+					FamilyId:  "family",
+					Subject:   "test@test.com",
+					ExpiresAt: now.Add(time.Hour),
+					UsedAt:    sql.NullTime{Time: now, Valid: true},
+				}, nil)
+
+			repository.
+				EXPECT().
+				MarkUsed(hashToken("token")).
+				Return(false, nil)
+
+			repository.
+				EXPECT().
+				RevokeFamily("family").
+				Return(nil)
+
+			// when
+			_, _, err := manager.Rotate("token")
+
+			// then
+			assert.ErrorIs(t, err, ErrRefreshTokenReused)
+		})
+
+		t.Run("should revoke token family if refresh token is used concurrently", func(t *testing.T) {
+			// given
+			repository.
+				EXPECT().
+				FindByHash(hashToken("token")).
+				Return(&model.DbRefreshToken{
+					FamilyId:  "family",
+					Subject:   "test@test.com",
+					ExpiresAt: now.Add(time.Hour),
+				}, nil)
+
+			repository.
+				EXPECT().
+				MarkUsed(hashToken("token")).
+				Return(false, nil)
+
+			repository.
+				EXPECT().
+				RevokeFamily("family").
+				Return(nil)
+
+			// when
+			_, _, err := manager.Rotate("token")
+
+			// then
+			assert.ErrorIs(t, err, ErrRefreshTokenReused)
+		})
+
+		t.Run("should issue new refresh token of the same family", func(t *testing.T) {
+			// given
+			repository.
+				EXPECT().
+				FindByHash(hashToken("token")).
+				Return(&model.DbRefreshToken{
+					FamilyId:  "family",
+					Subject:   "test@test.com",
+					ExpiresAt: now.Add(time.Hour),
+				}, nil)
+
+			repository.
+				EXPECT().
+				MarkUsed(hashToken("token")).
+				Return(true, nil)
+
+			var stored *model.DbRefreshToken
+			repository.
+				EXPECT().
+				Create(gomock.Any()).
+				DoAndReturn(func(token *model.DbRefreshToken) error {
+					stored = token
+					return nil
+				})
+
+			// when
+			subject, refreshToken, err := manager.Rotate("token")
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, "test@test.com", subject)
+			assert.NotEqual(t, "token", refreshToken)
+			assert.Equal(t, hashToken(refreshToken), stored.TokenHash)
+			assert.Equal(t, "family", stored.FamilyId)
+			assert.Equal(t, now.Add(time.Hour), stored.ExpiresAt)
+		})
+	})
+	t.Run("Revoke", func(t *testing.T) {
+		t.Run("should return error if refresh token is unknown", func(t *testing.T) {
+			// given
+			repository.
+				EXPECT().
+				FindByHash(hashToken("unknown")).
+				Return(nil, nil)
+
+			// when
+			err := manager.Revoke("unknown")
+
+			// then
+			assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+		})
+
+		t.Run("should revoke token family", func(t *testing.T) {
+			// given
+			repository.
+				EXPECT().
+				FindByHash(hashToken("token")).
+				Return(&model.DbRefreshToken{FamilyId: "family"}, nil)
+
+			repository.
+				EXPECT().
+				RevokeFamily("family").
+				Return(nil)
+
+			// when
+			err := manager.Revoke("token")
+
+			// then
+			assert.NoError(t, err)
+		})
+	})
+
+	t.Run("RevokeAll", func(t *testing.T) {
+		t.Run("should revoke all tokens of the subject", func(t *testing.T) {
+			// given
+			repository.
+				EXPECT().
+				RevokeSubject("test@test.com").
+				Return(nil)
+
+			// when
+			err := manager.RevokeAll("test@test.com")
+
+			// then
+			assert.NoError(t, err)
+		})
+	})
+}
@@ -0,0 +1,12 @@
+package session
+
+import "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/session/model"
+
+type Repository interface {
+	Migrate() error
+	Create(*model.DbRefreshToken) error
+	FindByHash(hash []byte) (*model.DbRefreshToken, error)
+	MarkUsed(hash []byte) (bool, error)
+	RevokeFamily(familyId string) error
+	RevokeSubject(subject string) error
+}
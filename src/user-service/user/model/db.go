@@ -1,6 +1,33 @@
 package model
 
+import "time"
+
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleRetailer Role = "retailer"
+	RoleAdmin    Role = "admin"
+)
+
+func (role Role) IsValid() bool {
+	return role == RoleCustomer || role == RoleRetailer || role == RoleAdmin
+}
+
+type Address struct {
+	Street     string
+	PostalCode string
+	City       string
+	Country    string
+}
+
 type DbUser struct {
-	Email    string
-	Password []byte
+	Id              int64
+	Email           string
+	Password        []byte
+	DisplayName     string
+	ShippingAddress Address
+	Role            Role
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
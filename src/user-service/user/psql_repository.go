@@ -1,35 +1,57 @@
 package user
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/database"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type PsqlRepository struct {
 	db *sql.DB
 }
 
-func NewPsqlRepository(config database.Config) (*PsqlRepository, error) {
-	db, err := sql.Open("postgres", config.Dsn())
-	if err != nil {
-		return nil, err
-	}
+func NewPsqlRepository(db *sql.DB) *PsqlRepository {
+	return &PsqlRepository{db}
+}
 
-	return &PsqlRepository{db}, nil
+func (repo *PsqlRepository) Ping(ctx context.Context) error {
+	return repo.db.PingContext(ctx)
 }
 
+// createUsersTable creates the users table or migrates the former table,
+// which had only email and password columns and was keyed on email.
 const createUsersTable = `
 create table if not exists users (
-	email    varchar(100) not null unique,
-	password bytea        not null,
-	primary key (email)
-)
+	id                   bigserial    not null,
+	email                varchar(100) not null unique,
+	password             bytea        not null,
+	primary key (id)
+);
+alter table users add column if not exists id                   bigserial;
+alter table users add column if not exists display_name         varchar(100) not null default '';
+alter table users add column if not exists shipping_street      varchar(200) not null default '';
+alter table users add column if not exists shipping_postal_code varchar(20)  not null default '';
+alter table users add column if not exists shipping_city        varchar(100) not null default '';
+alter table users add column if not exists shipping_country     varchar(100) not null default '';
+alter table users add column if not exists role                 varchar(20)  not null default 'customer';
+alter table users add column if not exists created_at           timestamptz  not null default now();
+alter table users add column if not exists updated_at           timestamptz  not null default now();
+do $$
+begin
+	if exists (
+		select 1 from information_schema.key_column_usage
+		where table_name = 'users' and constraint_name = 'users_pkey' and column_name = 'email'
+	) then
+		alter table users drop constraint users_pkey;
+		alter table users add primary key (id);
+	end if;
+end $$;
 `
 
 func (repo *PsqlRepository) Migrate() error {
@@ -37,27 +59,93 @@ func (repo *PsqlRepository) Migrate() error {
 	return err
 }
 
+const userColumns = `id, email, password, display_name, shipping_street, shipping_postal_code, shipping_city, shipping_country, role, created_at, updated_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row scanner) (*model.DbUser, error) {
+	var user model.DbUser
+	if err := row.Scan(
+		&user.Id,
+		&user.Email,
+		&user.Password,
+		&user.DisplayName,
+		&user.ShippingAddress.Street,
+		&user.ShippingAddress.PostalCode,
+		&user.ShippingAddress.City,
+		&user.ShippingAddress.Country,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 const createUsersBatchQuery = `
-insert into users (email, password) values %s
+insert into users (email, password, display_name, shipping_street, shipping_postal_code, shipping_city, shipping_country, role) values %s
 `
 
 func (repo *PsqlRepository) Create(users []*model.DbUser) error {
+	const columns = 8
+
 	placeholders := make([]string, len(users))
-	values := make([]interface{}, len(users)*2)
+	values := make([]interface{}, 0, len(users)*columns)
 
-	for i := 0; i < len(users); i++ {
-		placeholders[i] = fmt.Sprintf("($%d,$%d)", i*2+1, i*2+2)
-		values[i*2+0] = users[i].Email
-		values[i*2+1] = users[i].Password
+	for i, user := range users {
+		params := make([]string, columns)
+		for j := range params {
+			params[j] = fmt.Sprintf("$%d", i*columns+j+1)
+		}
+		placeholders[i] = fmt.Sprintf("(%s)", strings.Join(params, ","))
+
+		role := user.Role
+		if role == "" {
+			role = model.RoleCustomer
+		}
+
+		values = append(values,
+			user.Email,
+			user.Password,
+			user.DisplayName,
+			user.ShippingAddress.Street,
+			user.ShippingAddress.PostalCode,
+			user.ShippingAddress.City,
+			user.ShippingAddress.Country,
+			role,
+		)
 	}
 
 	query := fmt.Sprintf(createUsersBatchQuery, strings.Join(placeholders, ","))
 	_, err := repo.db.Exec(query, values...)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+		return ErrConflict
+	}
+
 	return err
 }
 
-const findUsersByEmailQuery = `
-select email, password from users where email = $1
+var findUserByIdQuery = `
+select ` + userColumns + ` from users where id = $1
+`
+
+func (repo *PsqlRepository) FindById(id int64) (*model.DbUser, error) {
+	user, err := scanUser(repo.db.QueryRow(findUserByIdQuery, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return user, err
+}
+
+var findUsersByEmailQuery = `
+select ` + userColumns + ` from users where email = $1
 `
 
 func (repo *PsqlRepository) FindByEmail(email string) ([]*model.DbUser, error) {
@@ -65,20 +153,73 @@ func (repo *PsqlRepository) FindByEmail(email string) ([]*model.DbUser, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var users []*model.DbUser
 	for rows.Next() {
-		user := model.DbUser{}
-		if err := rows.Scan(&user.Email, &user.Password); err != nil {
+		user, err := scanUser(rows)
+		if err != nil {
 			return nil, err
 		}
 
-		users = append(users, &user)
+		users = append(users, user)
 	}
 
 	return users, nil
 }
 
+const updateUserQuery = `
+update users
+set display_name = $2, shipping_street = $3, shipping_postal_code = $4, shipping_city = $5, shipping_country = $6, updated_at = now()
+where id = $1
+returning updated_at
+`
+
+// Update saves the profile of the user. Email, password and role are not
+// changed.
+func (repo *PsqlRepository) Update(user *model.DbUser) error {
+	row := repo.db.QueryRow(updateUserQuery,
+		user.Id,
+		user.DisplayName,
+		user.ShippingAddress.Street,
+		user.ShippingAddress.PostalCode,
+		user.ShippingAddress.City,
+		user.ShippingAddress.Country,
+	)
+
+	if err := row.Scan(&user.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+const updateUserRoleQuery = `
+update users set role = $2, updated_at = now() where id = $1
+`
+
+func (repo *PsqlRepository) UpdateRole(id int64, role model.Role) error {
+	result, err := repo.db.Exec(updateUserRoleQuery, id, role)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
 const deleteUsersBatchQuery = `
 delete from users where email in (%s)
 `
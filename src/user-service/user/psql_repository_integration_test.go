@@ -26,16 +26,19 @@ func TestIntegrationPsqlRepository(t *testing.T) {
 		t.Fatalf("could not get database container port: %s", err.Error())
 	}
 
-	repository, err := NewPsqlRepository(database.PsqlConfig{
+	db, err := sql.Open("postgres", database.PsqlConfig{
 		Host:     "0.0.0.0",
 		Port:     port.Int(),
 		Username: "postgres",
 		Password: "postgres",
 		Database: "postgres",
-	})
+	}.Dsn())
 	if err != nil {
-		t.Fatalf("could not create user repository: %s", err.Error())
+		t.Fatalf("could not open database: %s", err.Error())
 	}
+	t.Cleanup(func() { db.Close() })
+
+	repository := NewPsqlRepository(db)
 	t.Cleanup(clearTables(t, repository.db))
 
 	t.Run("Migrate", func(t *testing.T) {
@@ -48,7 +51,34 @@ func TestIntegrationPsqlRepository(t *testing.T) {
 
 			// then
 			assert.NoError(t, err)
-			assertTableExists(t, repository.db, "users", []string{"email", "password"})
+			assertTableExists(t, repository.db, "users", []string{"id", "email", "password", "display_name", "role", "created_at", "updated_at"})
+		})
+
+		t.Run("should migrate users table keyed on email", func(t *testing.T) {
+			t.Cleanup(clearTables(t, repository.db))
+
+			// given
+			_, err := repository.db.Exec(`
+				drop table users;
+				create table users (
+					email    varchar(100) not null unique,
+					password bytea        not null,
+					primary key (email)
+				);
+				insert into users (email, password) values ('test@test.com', 'hash');
+			`)
+			assert.NoError(t, err)
+
+			// when
+			err = repository.Migrate()
+
+			// then
+			assert.NoError(t, err)
+			users, err := repository.FindByEmail("test@test.com")
+			assert.NoError(t, err)
+			assert.Len(t, users, 1)
+			assert.NotZero(t, users[0].Id)
+			assert.Equal(t, model.RoleCustomer, users[0].Role)
 		})
 	})
 
@@ -97,6 +127,33 @@ func TestIntegrationPsqlRepository(t *testing.T) {
 		})
 	})
 
+	t.Run("FindById and Update", func(t *testing.T) {
+		t.Run("should update profile of the user", func(t *testing.T) {
+			t.Cleanup(clearTables(t, repository.db))
+
+			// given
+			insertUser(t, repository.db, &model.DbUser{
+				Email:    "test@test.com",
+				Password: []byte("some random hash"),
+			})
+			users, _ := repository.FindByEmail("test@test.com")
+			user := users[0]
+			user.DisplayName = "Test"
+			user.ShippingAddress.City = "Flensburg"
+
+			// when
+			err := repository.Update(user)
+
+			// then
+			assert.NoError(t, err)
+			updated, err := repository.FindById(user.Id)
+			assert.NoError(t, err)
+			assert.Equal(t, "Test", updated.DisplayName)
+			assert.Equal(t, "Flensburg", updated.ShippingAddress.City)
+			assert.False(t, updated.UpdatedAt.Before(updated.CreatedAt))
+		})
+	})
+
 	t.Run("Delete", func(t *testing.T) {
 		t.Run("should delete provided users", func(t *testing.T) {
 			t.Cleanup(clearTables(t, repository.db))
@@ -1,11 +1,14 @@
 package user
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,6 +19,8 @@ func TestPsqlRepository(t *testing.T) {
 	}
 
 	repository := PsqlRepository{db}
+	now := time.Now()
+	userColumnNames := []string{"id", "email", "password", "display_name", "shipping_street", "shipping_postal_code", "shipping_city", "shipping_country", "role", "created_at", "updated_at"}
 
 	t.Run("Create", func(t *testing.T) {
 		t.Run("should return error if executing query failed", func(t *testing.T) {
@@ -36,6 +41,24 @@ func TestPsqlRepository(t *testing.T) {
 			assert.Error(t, err)
 		})
 
+		t.Run("should return ErrConflict if email is already registered", func(t *testing.T) {
+			// given
+			users := []*model.DbUser{{
+				Email:    "test@test.com",
+				Password: []byte("test"),
+			}}
+
+			dbmock.
+				ExpectExec(`insert into users`).
+				WillReturnError(&pq.Error{Code: "23505"})
+
+			// when
+			err := repository.Create(users)
+
+			// then
+			assert.ErrorIs(t, err, ErrConflict)
+		})
+
 		t.Run("should insert users in batches", func(t *testing.T) {
 			// given
 			users := []*model.DbUser{
@@ -50,8 +73,11 @@ func TestPsqlRepository(t *testing.T) {
 			}
 
 			dbmock.
-				ExpectExec(`insert into users \(email, password\) values \(\$1,\$2\),\(\$3,\$4\)`).
-				WithArgs("test@test.com", []byte("test"), "abc@abc.com", []byte("abc")).
+				ExpectExec(`insert into users \(email, password, display_name, shipping_street, shipping_postal_code, shipping_city, shipping_country, role\) values \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8\),\(\$9,\$10,\$11,\$12,\$13,\$14,\$15,\$16\)`).
+				WithArgs(
+					"test@test.com", []byte("test"), "", "", "", "", "", model.RoleCustomer,
+					"abc@abc.com", []byte("abc"), "", "", "", "", "", model.RoleCustomer,
+				).
 				WillReturnResult(sqlmock.NewResult(0, 2))
 
 			// when
@@ -68,7 +94,7 @@ func TestPsqlRepository(t *testing.T) {
 			email := "test@test.com"
 
 			dbmock.
-				ExpectQuery(`select email, password from users where email = \$1`).
+				ExpectQuery(`select (.+) from users where email = \$1`).
 				WillReturnError(errors.New("database error"))
 
 			// when
@@ -84,8 +110,8 @@ func TestPsqlRepository(t *testing.T) {
 			email := "test@test.com"
 
 			dbmock.
-				ExpectQuery(`select email, password from users where email = \$1`).
-				WillReturnRows(sqlmock.NewRows([]string{"email", "password"}).AddRow("test@test.com", []byte("hash")))
+				ExpectQuery(`select (.+) from users where email = \$1`).
+				WillReturnRows(sqlmock.NewRows(userColumnNames).AddRow(1, "test@test.com", []byte("hash"), "Test", "Street 1", "24937", "Flensburg", "Germany", "customer", now, now))
 
 			// when
 			users, err := repository.FindByEmail(email)
@@ -93,6 +119,126 @@ func TestPsqlRepository(t *testing.T) {
 			// then
 			assert.NoError(t, err)
 			assert.Len(t, users, 1)
+			assert.Equal(t, int64(1), users[0].Id)
+			assert.Equal(t, "Flensburg", users[0].ShippingAddress.City)
+			assert.Equal(t, model.RoleCustomer, users[0].Role)
+		})
+	})
+
+	t.Run("FindById", func(t *testing.T) {
+		t.Run("should return ErrNotFound if user does not exist", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectQuery(`select (.+) from users where id = \$1`).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows(userColumnNames))
+
+			// when
+			user, err := repository.FindById(1)
+
+			// then
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.Nil(t, user)
+		})
+
+		t.Run("should return user by id", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectQuery(`select (.+) from users where id = \$1`).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows(userColumnNames).AddRow(1, "test@test.com", []byte("hash"), "Test", "Street 1", "24937", "Flensburg", "Germany", "customer", now, now))
+
+			// when
+			user, err := repository.FindById(1)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, &model.DbUser{
+				Id:          1,
+				Email:       "test@test.com",
+				Password:    []byte("hash"),
+				DisplayName: "Test",
+				ShippingAddress: model.Address{
+					Street:     "Street 1",
+					PostalCode: "24937",
+					City:       "Flensburg",
+					Country:    "Germany",
+				},
+				Role:      model.RoleCustomer,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}, user)
+		})
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		t.Run("should return ErrNotFound if user does not exist", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectQuery(`update users set (.+) where id = \$1 returning updated_at`).
+				WillReturnRows(sqlmock.NewRows([]string{"updated_at"}))
+
+			// when
+			err := repository.Update(&model.DbUser{Id: 1})
+
+			// then
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+
+		t.Run("should update profile of the user", func(t *testing.T) {
+			// given
+			user := &model.DbUser{
+				Id:          1,
+				DisplayName: "Test",
+				ShippingAddress: model.Address{
+					Street:     "Street 1",
+					PostalCode: "24937",
+					City:       "Flensburg",
+					Country:    "Germany",
+				},
+			}
+
+			dbmock.
+				ExpectQuery(`update users set (.+) where id = \$1 returning updated_at`).
+				WithArgs(1, "Test", "Street 1", "24937", "Flensburg", "Germany").
+				WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+
+			// when
+			err := repository.Update(user)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, now, user.UpdatedAt)
+		})
+	})
+
+	t.Run("UpdateRole", func(t *testing.T) {
+		t.Run("should return ErrNotFound if user does not exist", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectExec(`update users set role = \$2, updated_at = now\(\) where id = \$1`).
+				WithArgs(1, model.RoleRetailer).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			// when
+			err := repository.UpdateRole(1, model.RoleRetailer)
+
+			// then
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+
+		t.Run("should update role of the user", func(t *testing.T) {
+			// given
+			dbmock.
+				ExpectExec(`update users set role = \$2, updated_at = now\(\) where id = \$1`).
+				WithArgs(1, model.RoleRetailer).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			// when
+			err := repository.UpdateRole(1, model.RoleRetailer)
+
+			// then
+			assert.NoError(t, err)
 		})
 	})
 
@@ -146,4 +292,36 @@ func TestPsqlRepository(t *testing.T) {
 			assert.NoError(t, err)
 		})
 	})
+
+	t.Run("Ping", func(t *testing.T) {
+		db, dbmock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		repository := PsqlRepository{db}
+
+		t.Run("should return error if database is not reachable", func(t *testing.T) {
+			// given
+			dbmock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+			// when
+			err := repository.Ping(context.Background())
+
+			// then
+			assert.Error(t, err)
+		})
+
+		t.Run("should ping database", func(t *testing.T) {
+			// given
+			dbmock.ExpectPing()
+
+			// when
+			err := repository.Ping(context.Background())
+
+			// then
+			assert.NoError(t, err)
+			assert.NoError(t, dbmock.ExpectationsWereMet())
+		})
+	})
 }
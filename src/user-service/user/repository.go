@@ -1,10 +1,23 @@
 package user
 
-import "github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
+import (
+	"fmt"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/problem"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/user-service/user/model"
+)
+
+var (
+	ErrNotFound = fmt.Errorf("user %w", problem.ErrNotFound)
+	ErrConflict = fmt.Errorf("%w: email is already registered", problem.ErrConflict)
+)
 
 type Repository interface {
 	Migrate() error
 	Create([]*model.DbUser) error
+	FindById(id int64) (*model.DbUser, error)
 	FindByEmail(email string) ([]*model.DbUser, error)
+	Update(*model.DbUser) error
+	UpdateRole(id int64, role model.Role) error
 	Delete([]*model.DbUser) error
 }
@@ -1,52 +1,159 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
+
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/metrics"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/middleware"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/money"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/server"
+	"github.com/flohansen/hsfl-master-ai-cloud-engineering/lib/tracing"
 )
 
 type IndexPageViewModel struct {
-	Products []Product
+	Products   []Product
+	Total      *int
+	Cursor     string
+	NextCursor string
+	Search     string
+	Results    []SearchResult
 }
 
 type Product struct {
 	Name     string
 	Retailer string
-	Price    float32
+	Price    money.Money
+}
+
+func (product Product) DisplayPrice() string {
+	return product.Price.Format(money.German)
+}
+
+type ProductsPage struct {
+	Products   []Product `json:"products"`
+	NextCursor string    `json:"nextCursor"`
+	Total      *int      `json:"total"`
+}
+
+// SearchResult is a product found by the search of the product service. The
+// highlights are HTML escaped by the product service and only contain <mark>
+// elements.
+type SearchResult struct {
+	Product
+	Highlights struct {
+		Name        template.HTML `json:"name"`
+		Description template.HTML `json:"description"`
+	} `json:"highlights"`
 }
 
-func requestProducts() ([]Product, error) {
-	endpoint := fmt.Sprintf("http://%s/api/v1/products", os.Getenv("PRODUCTS_ENDPOINT"))
+var errInvalidCursor = errors.New("invalid cursor")
 
-	req, _ := http.NewRequest("GET", endpoint, nil)
-	res, err := http.DefaultClient.Do(req)
+func requestProducts(ctx context.Context, client *http.Client, cursor string) (*ProductsPage, error) {
+	// The total is only counted for the first page, counting on every page
+	// would scan all products again.
+	query := "total=true"
+	if cursor != "" {
+		query = "cursor=" + url.QueryEscape(cursor)
+	}
+	endpoint := fmt.Sprintf("http://%s/api/v1/products?%s", os.Getenv("PRODUCTS_ENDPOINT"), query)
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusBadRequest && cursor != "":
+		return nil, errInvalidCursor
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
 
-	var products []Product
-	if err := json.NewDecoder(res.Body).Decode(&products); err != nil {
+	var page ProductsPage
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
 		return nil, err
 	}
 
-	return products, nil
+	return &page, nil
 }
 
-func indexHandler(tmpl *template.Template) http.HandlerFunc {
+func searchProducts(ctx context.Context, client *http.Client, text string) ([]SearchResult, error) {
+	endpoint := fmt.Sprintf("http://%s/api/v1/products/search?q=%s", os.Getenv("PRODUCTS_ENDPOINT"), url.QueryEscape(text))
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	var response struct {
+		Results []SearchResult `json:"results"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Results, nil
+}
+
+func searchHandler(tmpl *template.Template, client *http.Client, logger *slog.Logger, text string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		products, err := requestProducts()
+		results, err := searchProducts(r.Context(), client, text)
 		if err != nil {
-			log.Println(err)
+			logger.ErrorContext(r.Context(), "could not search products", slog.String("error", err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		tmpl.ExecuteTemplate(w, "index", IndexPageViewModel{
+			Search:  text,
+			Results: results,
+		})
+	}
+}
+
+func indexHandler(tmpl *template.Template, client *http.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if text := strings.TrimSpace(r.URL.Query().Get("q")); text != "" {
+			searchHandler(tmpl, client, logger, text)(w, r)
+			return
+		}
+
+		cursor := r.URL.Query().Get("cursor")
+
+		page, err := requestProducts(r.Context(), client, cursor)
+		if errors.Is(err, errInvalidCursor) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			logger.ErrorContext(r.Context(), "could not request products", slog.String("error", err.Error()))
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
 		viewModel := IndexPageViewModel{
-			Products: products,
+			Products:   page.Products,
+			Total:      page.Total,
+			Cursor:     cursor,
+			NextCursor: page.NextCursor,
 		}
 
 		tmpl.ExecuteTemplate(w, "index", viewModel)
@@ -56,9 +163,49 @@ func indexHandler(tmpl *template.Template) http.HandlerFunc {
 func main() {
 	tmpl := template.Must(template.ParseGlob("templates/*.gohtml"))
 
+	exporter, err := tracing.NewExporter(os.Getenv("TRACES_EXPORTER"))
+	if err != nil {
+		log.Fatalf("could not create trace exporter: %s", err.Error())
+	}
+
+	tracer := tracing.NewTracer("web-service", exporter)
+
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, nil)))
+	client := &http.Client{Transport: tracing.NewTransport(tracer, nil)}
+
+	httpMetrics := metrics.NewHTTPMetrics(metrics.Default)
+
 	router := http.NewServeMux()
-	router.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("public"))))
-	router.HandleFunc("/", indexHandler(tmpl))
+	router.Handle("/static/", httpMetrics.Route("/static/")(http.StripPrefix("/static/", http.FileServer(http.Dir("public")))))
+	router.Handle("/", httpMetrics.Route("/")(indexHandler(tmpl, client, logger)))
+
+	handler := tracing.Middleware(tracer)(middleware.AccessLog(logger)(middleware.Recover(logger)(router)))
+
+	serverConfig, err := server.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("invalid server configuration: %s", err.Error())
+	}
+
+	runner := server.NewRunner(serverConfig)
+	if err := runner.Listen("0.0.0.0:3000", handler); err != nil {
+		log.Fatalf("error while listen: %s", err.Error())
+	}
+
+	// The admin port serves the metrics and must not be exposed to the public.
+	adminPort := os.Getenv("ADMIN_PORT")
+	if adminPort == "" {
+		adminPort = "9000"
+	}
 
-	log.Fatal(http.ListenAndServe("0.0.0.0:3000", router))
+	admin := http.NewServeMux()
+	admin.Handle("/metrics", metrics.Handler())
+	if err := runner.Listen("0.0.0.0:"+adminPort, admin); err != nil {
+		log.Fatalf("error while listen on admin port: %s", err.Error())
+	}
+
+	runner.OnShutdown(tracer.Shutdown)
+
+	if err := runner.Run(context.Background()); err != nil {
+		log.Fatalf("error while shutting down: %s", err.Error())
+	}
 }